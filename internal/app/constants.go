@@ -5,6 +5,7 @@ import "time"
 // Header keys
 const CONTENT_TYPE = "Content-Type"
 const AUTHORIZATION = "Authorization"
+const RETRY_AFTER = "Retry-After"
 
 const BEAERER = "Bearer "
 
@@ -15,6 +16,83 @@ const TEXT_PLAIN = "text/plain; charset=utf-8"
 // JWT expire time
 const JWT_EXPIRE_TIME = 1 * time.Hour
 
+// JWT audiences. Each kind of token is only accepted where its audience is expected.
+const JWT_AUDIENCE_ACCESS = "sanctuary-access"
+const JWT_AUDIENCE_EMAIL_VERIFICATION = "sanctuary-email-verification"
+const JWT_AUDIENCE_MFA = "sanctuary-mfa"
+
+// How long the user has to enter the 2fa code after entering the password
+const MFA_TOKEN_EXPIRE_TIME = 5 * time.Minute
+
+// Two factor authentication
+const TOTP_ISSUER = "Sanctuary"
+
+// Number of time steps before and after the current one that are still accepted, to tolerate clock drift
+const TOTP_SKEW = 1
+const RECOVERY_CODE_COUNT = 10
+
+// How long clients may cache the public keys from /.well-known/jwks.json
+const JWKS_CACHE_MAX_AGE = 5 * time.Minute
+
+// Refresh token expire time
+const REFRESH_TOKEN_EXPIRE_TIME = 30 * 24 * time.Hour
+
+// Password reset token expire time
+const PASSWORD_RESET_EXPIRE_TIME = 1 * time.Hour
+
+// Email verification token expire time
+const EMAIL_VERIFICATION_EXPIRE_TIME = 48 * time.Hour
+
+// Email change confirmation link expire time
+const EMAIL_CHANGE_EXPIRE_TIME = 24 * time.Hour
+
+// How long a user has to wait before asking for another verification email
+const VERIFICATION_EMAIL_COOLDOWN = 1 * time.Minute
+
+// How long token revocation lookups are cached
+const REVOCATION_CACHE_TTL = 30 * time.Second
+
+// Failed logins older than this are forgotten
+const LOGIN_ATTEMPT_WINDOW = 24 * time.Hour
+
+// Failed logins allowed before the lockout starts
+const LOGIN_FREE_ATTEMPTS_PER_ACCOUNT = 5
+const LOGIN_FREE_ATTEMPTS_PER_IP = 20
+
+// The lockout doubles with every failed login after the free attempts, up to the max
+const LOGIN_LOCKOUT_BASE = 30 * time.Second
+const LOGIN_LOCKOUT_MAX = 1 * time.Hour
+
+// Deleted accounts can be restored by logging in until the grace period is over
+const ACCOUNT_DELETION_GRACE_PERIOD = 30 * 24 * time.Hour
+
+// How often deleted accounts past the grace period are purged, and how many per run
+const ACCOUNT_PURGE_INTERVAL = 1 * time.Hour
+const ACCOUNT_PURGE_BATCH_SIZE = 100
+
+// The for you feed ranks the posts of this window, up to the candidate limit
+const FOR_YOU_CANDIDATE_WINDOW = 7 * 24 * time.Hour
+const FOR_YOU_CANDIDATE_LIMIT = 500
+
+// How long a user has to wait between user name changes, and how long the old user name stays reserved for them
+const USER_NAME_CHANGE_COOLDOWN = 30 * 24 * time.Hour
+const USER_NAME_RESERVATION_PERIOD = 14 * 24 * time.Hour
+
+// Most media files a post can have
+const MAX_POST_MEDIA = 10
+
+// Size limits of post media by kind
+const MAX_POST_IMAGE_SIZE = 20 << 20
+const MAX_POST_GIF_SIZE = 15 << 20
+const MAX_POST_VIDEO_SIZE = 200 << 20
+
+// Posts can be edited by their author for this long after posting
+const POST_EDIT_WINDOW = 1 * time.Hour
+
+// How often the media of deleted posts is removed from S3, and how many files per run
+const POST_MEDIA_PURGE_INTERVAL = 10 * time.Minute
+const POST_MEDIA_PURGE_BATCH_SIZE = 100
+
 // Time parse layout
 const TIME_PARSE_LAYOUT = "2006-01-02"
 
@@ -0,0 +1,365 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/app"
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/app/mailer"
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/app/validators"
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/database"
+)
+
+type changePasswordRequest struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
+
+type changeEmailRequest struct {
+	NewEmail string `json:"new_email"`
+	Password string `json:"password"`
+}
+
+// Change the password of the authenticated user.
+// Every other session is logged out, the caller gets a new access token and refresh token.
+func (cfg *ApiConfig) ChangePasswordHandler(writer http.ResponseWriter, request *http.Request) {
+	// Decode the request
+	decoder := json.NewDecoder(request.Body)
+	changeRequest := changePasswordRequest{}
+	if decodeErr := decoder.Decode(&changeRequest); decodeErr != nil {
+		RespondWithError(writer, http.StatusBadRequest, decodeErr.Error())
+		return
+	}
+
+	if changeRequest.CurrentPassword == "" {
+		RespondWithError(writer, http.StatusBadRequest, CLIENT_MSG_PASSWORD_CANNOT_BE_EMPTY)
+		return
+	}
+
+	if changeRequest.NewPassword == "" {
+		RespondWithError(writer, http.StatusBadRequest, CLIENT_MSG_NEW_PASSWORD_CANNOT_BE_EMPTY)
+		return
+	}
+
+	// Verify the current password
+	user := UserFromContext(request.Context())
+	if checkPassErr := CheckPasswordHash(user.HashedPassword, changeRequest.CurrentPassword); checkPassErr != nil {
+		RespondWithError(writer, http.StatusUnauthorized, CLIENT_MSG_INCORRECT_PASSWORD)
+		return
+	}
+
+	// Hash the new password
+	hashedPassword, hashErr := HashPassword(changeRequest.NewPassword)
+	if hashErr != nil {
+		cfg.LogError(SERVER_MSG_PASSWORD_HASH_FAILED, hashErr)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_CHANGE_PASSWORD)
+		return
+	}
+
+	params := database.UpdateUserPasswordParams{
+		ID:             user.ID,
+		HashedPassword: hashedPassword,
+	}
+	if updateErr := cfg.Db.UpdateUserPassword(request.Context(), params); updateErr != nil {
+		cfg.LogError(SERVER_MSG_UPDATE_PASSWORD_FAILED, updateErr)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_CHANGE_PASSWORD)
+		return
+	}
+
+	// Log out every session, including this one
+	if revokeErr := cfg.revokeAllTokensForUser(request.Context(), user.ID); revokeErr != nil {
+		cfg.LogError(SERVER_MSG_REVOKE_TOKEN_FAILED, revokeErr)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_CHANGE_PASSWORD)
+		return
+	}
+
+	// Then start a new session for the caller with the bumped token version
+	updatedUser, getUserErr := cfg.Db.GetUserById(request.Context(), user.ID)
+	if getUserErr != nil {
+		cfg.LogError(SERVER_MSG_GET_USER_FAILED, getUserErr)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_CHANGE_PASSWORD)
+		return
+	}
+
+	cfg.respondWithNewSession(writer, request, updatedUser, http.StatusOK, CLIENT_MSG_ERROR_CHANGE_PASSWORD)
+}
+
+// Ask to change the email of the authenticated user.
+// A confirmation link is sent to the new email, the email is only changed once the link is opened.
+func (cfg *ApiConfig) ChangeEmailHandler(writer http.ResponseWriter, request *http.Request) {
+	// Decode the request
+	decoder := json.NewDecoder(request.Body)
+	changeRequest := changeEmailRequest{}
+	if decodeErr := decoder.Decode(&changeRequest); decodeErr != nil {
+		RespondWithError(writer, http.StatusBadRequest, decodeErr.Error())
+		return
+	}
+
+	if changeRequest.NewEmail == "" {
+		RespondWithError(writer, http.StatusBadRequest, CLIENT_MSG_EMAIL_CANNOT_BE_EMPTY)
+		return
+	}
+
+	if changeRequest.Password == "" {
+		RespondWithError(writer, http.StatusBadRequest, CLIENT_MSG_PASSWORD_CANNOT_BE_EMPTY)
+		return
+	}
+
+	if emailErr := validators.ValidateEmail(changeRequest.NewEmail); emailErr != nil {
+		RespondWithError(writer, http.StatusBadRequest, CLIENT_MSG_INVALID_EMAIL)
+		return
+	}
+
+	user := UserFromContext(request.Context())
+	if changeRequest.NewEmail == user.Email {
+		RespondWithError(writer, http.StatusBadRequest, CLIENT_MSG_SAME_EMAIL)
+		return
+	}
+
+	// Verify the password
+	if checkPassErr := CheckPasswordHash(user.HashedPassword, changeRequest.Password); checkPassErr != nil {
+		RespondWithError(writer, http.StatusUnauthorized, CLIENT_MSG_INCORRECT_PASSWORD)
+		return
+	}
+
+	// Fail early if the email is taken. The UNIQUE constraint is checked again when the change is confirmed.
+	_, getUserErr := cfg.Db.GetUserByEmailIncludingInactive(request.Context(), changeRequest.NewEmail)
+	if getUserErr == nil {
+		RespondWithError(writer, http.StatusConflict, CLIENT_MSG_EMAIL_ALREADY_TAKEN)
+		return
+	}
+	if !errors.Is(getUserErr, sql.ErrNoRows) {
+		cfg.LogError(SERVER_MSG_GET_USER_FAILED, getUserErr)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_CHANGE_EMAIL)
+		return
+	}
+
+	// Only the latest confirmation link should work
+	if invalidateErr := cfg.Db.InvalidateEmailChangeRequestsForUser(request.Context(), user.ID); invalidateErr != nil {
+		cfg.LogError(SERVER_MSG_CHANGE_EMAIL_FAILED, invalidateErr)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_CHANGE_EMAIL)
+		return
+	}
+
+	// Create the confirmation token and store only its hash
+	confirmToken, tokenErr := MakeRandomToken()
+	if tokenErr != nil {
+		cfg.LogError(SERVER_MSG_CHANGE_EMAIL_FAILED, tokenErr)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_CHANGE_EMAIL)
+		return
+	}
+
+	params := database.CreateEmailChangeRequestParams{
+		UserID:    user.ID,
+		NewEmail:  changeRequest.NewEmail,
+		TokenHash: HashToken(confirmToken),
+		ExpiresAt: pgtype.Timestamp{
+			Time:  time.Now().Add(app.EMAIL_CHANGE_EXPIRE_TIME),
+			Valid: true,
+		},
+	}
+	if _, createErr := cfg.Db.CreateEmailChangeRequest(request.Context(), params); createErr != nil {
+		cfg.LogError(SERVER_MSG_CHANGE_EMAIL_FAILED, createErr)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_CHANGE_EMAIL)
+		return
+	}
+
+	// Email the confirmation link to the new address
+	confirmLink := fmt.Sprintf("%v/api/me/email/confirm?token=%v", cfg.GetBaseUrl(), url.QueryEscape(confirmToken))
+	message := mailer.Message{
+		To:      changeRequest.NewEmail,
+		Subject: "Confirm your new Sanctuary email",
+		Body: fmt.Sprintf(
+			"Someone asked to use this email for their Sanctuary account.\n\nPlease confirm by opening the link below:\n\n%v\n\nThe link expires in %v. If you did not ask for this, you can ignore this email.",
+			confirmLink,
+			app.EMAIL_CHANGE_EXPIRE_TIME,
+		),
+	}
+	if sendErr := cfg.Mailer.Send(request.Context(), message); sendErr != nil {
+		cfg.LogError(SERVER_MSG_SEND_MAIL_FAILED, sendErr)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_CHANGE_EMAIL)
+		return
+	}
+
+	RespondWithJson(writer, http.StatusAccepted, messageResponse{Message: "Please open the link we sent to your new email to confirm the change."})
+}
+
+// Confirm an email change with the token from the confirmation link.
+func (cfg *ApiConfig) ConfirmEmailChangeHandler(writer http.ResponseWriter, request *http.Request) {
+	token := request.URL.Query().Get("token")
+	if token == "" {
+		RespondWithError(writer, http.StatusBadRequest, CLIENT_MSG_INVALID_EMAIL_CHANGE_LINK)
+		return
+	}
+
+	// Find the email change request
+	changeRequest, getRequestErr := cfg.Db.GetEmailChangeRequestByHash(request.Context(), HashToken(token))
+	if getRequestErr != nil {
+		if errors.Is(getRequestErr, sql.ErrNoRows) {
+			RespondWithError(writer, http.StatusBadRequest, CLIENT_MSG_INVALID_EMAIL_CHANGE_LINK)
+			return
+		}
+		cfg.LogError(SERVER_MSG_CHANGE_EMAIL_FAILED, getRequestErr)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_CHANGE_EMAIL)
+		return
+	}
+
+	// Mark the request as used. The query only matches unused and unexpired requests.
+	affectedRows, markUsedErr := cfg.Db.MarkEmailChangeRequestUsed(request.Context(), changeRequest.ID)
+	if markUsedErr != nil {
+		cfg.LogError(SERVER_MSG_CHANGE_EMAIL_FAILED, markUsedErr)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_CHANGE_EMAIL)
+		return
+	}
+	if affectedRows == 0 {
+		RespondWithError(writer, http.StatusBadRequest, CLIENT_MSG_INVALID_EMAIL_CHANGE_LINK)
+		return
+	}
+
+	// Get the old email before it is replaced, to let the owner know about the change
+	oldUser, getUserErr := cfg.Db.GetUserById(request.Context(), changeRequest.UserID)
+	if getUserErr != nil {
+		if errors.Is(getUserErr, sql.ErrNoRows) {
+			RespondWithError(writer, http.StatusBadRequest, CLIENT_MSG_INVALID_EMAIL_CHANGE_LINK)
+			return
+		}
+		cfg.LogError(SERVER_MSG_GET_USER_FAILED, getUserErr)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_CHANGE_EMAIL)
+		return
+	}
+
+	// Swap the email. Someone else may have taken it since the change was requested.
+	params := database.UpdateUserEmailParams{
+		ID:    changeRequest.UserID,
+		Email: changeRequest.NewEmail,
+	}
+	if _, updateErr := cfg.Db.UpdateUserEmail(request.Context(), params); updateErr != nil {
+		if isUniqueViolation(updateErr) {
+			RespondWithError(writer, http.StatusConflict, CLIENT_MSG_EMAIL_ALREADY_TAKEN)
+			return
+		}
+		cfg.LogError(SERVER_MSG_CHANGE_EMAIL_FAILED, updateErr)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_CHANGE_EMAIL)
+		return
+	}
+
+	// Let the old address know. A failure here is only logged, the change is already done.
+	message := mailer.Message{
+		To:      oldUser.Email,
+		Subject: "Your Sanctuary email was changed",
+		Body: fmt.Sprintf(
+			"The email of your Sanctuary account was changed to %v.\n\nIf you did not do this, please reset your password and contact us.",
+			changeRequest.NewEmail,
+		),
+	}
+	if sendErr := cfg.Mailer.Send(request.Context(), message); sendErr != nil {
+		cfg.LogError(SERVER_MSG_SEND_MAIL_FAILED, sendErr)
+	}
+
+	RespondWithJson(writer, http.StatusOK, messageResponse{Message: "Your email has been changed."})
+}
+
+type passwordConfirmationRequest struct {
+	Password string `json:"password"`
+}
+
+type accountDeletionResponse struct {
+	Message string    `json:"message"`
+	PurgeAt time.Time `json:"purge_at"`
+}
+
+// Delete the account of the authenticated user.
+// The account is hidden right away and purged after the grace period. Logging in before then restores it.
+func (cfg *ApiConfig) DeleteAccountHandler(writer http.ResponseWriter, request *http.Request) {
+	user, ok := cfg.confirmPassword(writer, request)
+	if !ok {
+		return
+	}
+
+	if deleteErr := cfg.Db.SoftDeleteUser(request.Context(), user.ID); deleteErr != nil {
+		cfg.LogError(SERVER_MSG_DELETE_ACCOUNT_FAILED, deleteErr)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_DELETE_ACCOUNT)
+		return
+	}
+
+	// Log out every session
+	if revokeErr := cfg.revokeAllTokensForUser(request.Context(), user.ID); revokeErr != nil {
+		cfg.LogError(SERVER_MSG_REVOKE_TOKEN_FAILED, revokeErr)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_DELETE_ACCOUNT)
+		return
+	}
+
+	response := accountDeletionResponse{
+		Message: "Your account has been deleted. Log in before it is purged to restore it.",
+		PurgeAt: cfg.now().Add(app.ACCOUNT_DELETION_GRACE_PERIOD),
+	}
+	RespondWithJson(writer, http.StatusOK, response)
+}
+
+// Deactivate the account of the authenticated user. The account is hidden until the user logs in again.
+func (cfg *ApiConfig) DeactivateAccountHandler(writer http.ResponseWriter, request *http.Request) {
+	user, ok := cfg.confirmPassword(writer, request)
+	if !ok {
+		return
+	}
+
+	if deactivateErr := cfg.Db.DeactivateUser(request.Context(), user.ID); deactivateErr != nil {
+		cfg.LogError(SERVER_MSG_DELETE_ACCOUNT_FAILED, deactivateErr)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_DEACTIVATE_ACCOUNT)
+		return
+	}
+
+	// Log out every session
+	if revokeErr := cfg.revokeAllTokensForUser(request.Context(), user.ID); revokeErr != nil {
+		cfg.LogError(SERVER_MSG_REVOKE_TOKEN_FAILED, revokeErr)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_DEACTIVATE_ACCOUNT)
+		return
+	}
+
+	RespondWithJson(writer, http.StatusOK, messageResponse{Message: "Your account has been deactivated. Log in again to reactivate it."})
+}
+
+// Decode a password confirmation and check it against the authenticated user.
+// Responds with an error and returns false if the password is missing or wrong.
+func (cfg *ApiConfig) confirmPassword(writer http.ResponseWriter, request *http.Request) (database.User, bool) {
+	// Decode the request
+	decoder := json.NewDecoder(request.Body)
+	confirmRequest := passwordConfirmationRequest{}
+	if decodeErr := decoder.Decode(&confirmRequest); decodeErr != nil {
+		RespondWithError(writer, http.StatusBadRequest, decodeErr.Error())
+		return database.User{}, false
+	}
+
+	if confirmRequest.Password == "" {
+		RespondWithError(writer, http.StatusBadRequest, CLIENT_MSG_PASSWORD_CANNOT_BE_EMPTY)
+		return database.User{}, false
+	}
+
+	user := UserFromContext(request.Context())
+	if checkPassErr := CheckPasswordHash(user.HashedPassword, confirmRequest.Password); checkPassErr != nil {
+		RespondWithError(writer, http.StatusUnauthorized, CLIENT_MSG_INCORRECT_PASSWORD)
+		return database.User{}, false
+	}
+
+	return user, true
+}
+
+// Accounts deleted longer than the grace period ago are waiting for the purger and can no longer be restored.
+func (cfg *ApiConfig) isPurgePending(user database.User) bool {
+	return user.DeletedAt.Valid && cfg.now().Sub(user.DeletedAt.Time) >= app.ACCOUNT_DELETION_GRACE_PERIOD
+}
+
+// Logging in undoes a deactivation, and a deletion that is still within the grace period.
+func (cfg *ApiConfig) restoreUserIfInactive(ctx context.Context, user database.User) (database.User, error) {
+	if !user.DeletedAt.Valid && !user.DeactivatedAt.Valid {
+		return user, nil
+	}
+	return cfg.Db.RestoreUser(ctx, user.ID)
+}
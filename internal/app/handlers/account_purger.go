@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/app"
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/app/storage"
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/database"
+	"go.uber.org/zap"
+)
+
+// Purge deleted accounts every interval until ctx is done. Meant to be run in its own goroutine.
+func (cfg *ApiConfig) StartAccountPurger(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		purged, purgeErr := cfg.PurgeDeletedAccounts(ctx)
+		if purgeErr != nil {
+			cfg.LogError(SERVER_MSG_PURGE_ACCOUNT_FAILED, purgeErr)
+		} else if purged > 0 {
+			cfg.Logger.Info("Purged deleted accounts", zap.Int("count", purged))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Hard delete the accounts whose grace period is over, together with their stored media.
+// Posts, comments, likes and sessions are removed by the ON DELETE CASCADE constraints.
+// Returns the number of purged accounts.
+func (cfg *ApiConfig) PurgeDeletedAccounts(ctx context.Context) (int, error) {
+	cutoff := pgtype.Timestamp{
+		Time:  cfg.now().Add(-app.ACCOUNT_DELETION_GRACE_PERIOD),
+		Valid: true,
+	}
+
+	users, getUsersErr := cfg.Db.GetUsersToPurge(ctx, database.GetUsersToPurgeParams{
+		DeletedAt: cutoff,
+		Limit:     app.ACCOUNT_PURGE_BATCH_SIZE,
+	})
+	if getUsersErr != nil {
+		return 0, getUsersErr
+	}
+
+	purged := 0
+	for _, user := range users {
+		// Remove the files first. If that fails the account is kept and retried on the next run.
+		if mediaErr := cfg.deleteUserMedia(ctx, user); mediaErr != nil {
+			cfg.LogError(SERVER_MSG_PURGE_ACCOUNT_FAILED, mediaErr)
+			continue
+		}
+
+		// The cutoff is checked again in case the user restored the account in the meantime
+		affectedRows, purgeErr := cfg.Db.PurgeUser(ctx, database.PurgeUserParams{
+			ID:        user.ID,
+			DeletedAt: cutoff,
+		})
+		if purgeErr != nil {
+			cfg.LogError(SERVER_MSG_PURGE_ACCOUNT_FAILED, purgeErr)
+			continue
+		}
+		purged += int(affectedRows)
+	}
+
+	return purged, nil
+}
+
+// Delete the profile picture and the post media of the user from storage, with all their renditions
+func (cfg *ApiConfig) deleteUserMedia(ctx context.Context, user database.GetUsersToPurgeRow) error {
+	mediaUrls, mediaErr := cfg.Db.GetMediaUrlsForUser(ctx, user.ID)
+	if mediaErr != nil {
+		return mediaErr
+	}
+	for _, profileImageUrl := range []pgtype.Text{user.ProfileImageUrl, user.ProfileImageFeedUrl, user.ProfileImageThumbnailUrl} {
+		if profileImageUrl.Valid {
+			mediaUrls = append(mediaUrls, profileImageUrl.String)
+		}
+	}
+
+	for _, mediaUrl := range mediaUrls {
+		if deleteErr := cfg.deleteUploadedFile(ctx, mediaUrl); deleteErr != nil {
+			return deleteErr
+		}
+	}
+
+	return nil
+}
+
+// Delete a file uploaded with UploadFileHeader or UploadBytes. Urls that are not ours are ignored.
+func (cfg *ApiConfig) deleteUploadedFile(ctx context.Context, downloadUrl string) error {
+	fileKey, ok := storage.KeyFromURL(cfg.Storage, downloadUrl)
+	if !ok {
+		// Not one of our files, nothing to delete
+		return nil
+	}
+
+	return cfg.Storage.Delete(ctx, fileKey)
+}
@@ -19,28 +19,21 @@ type emailAndPasswordRequest struct {
 	Password string `json:"password"`
 }
 
+type registerRequest struct {
+	emailAndPasswordRequest
+	// Optional. A random user name is given when it is empty.
+	UserName string `json:"user_name"`
+}
+
 type userWithTokenResponse struct {
-	ID              int64              `json:"id"`
-	Email           string             `json:"email"`
-	UserName        string             `json:"user_name"`
-	FullName        string             `json:"full_name"`
-	ProfileImageUrl string             `json:"profile_image_url"`
-	Dob             string             `json:"dob"`
-	CreatedAt       time.Time          `json:"created_at"`
-	UpdatedAt       time.Time          `json:"updated_at"`
-	AccessToken     string             `json:"access_token"`
-	Interests       []interestResponse `json:"interests"`
+	privateUserResponse
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token,omitempty"`
 }
 
-type userWithoutTokenResponse struct {
-	ID              int64     `json:"id"`
-	Email           string    `json:"email"`
-	UserName        string    `json:"user_name"`
-	FullName        string    `json:"full_name"`
-	ProfileImageUrl string    `json:"profile_image_url"`
-	Dob             string    `json:"dob"`
-	CreatedAt       time.Time `json:"created_at"`
-	UpdatedAt       time.Time `json:"updated_at"`
+type mfaRequiredResponse struct {
+	MfaRequired bool   `json:"mfa_required"`
+	MfaToken    string `json:"mfa_token"`
 }
 
 // Update user
@@ -61,21 +54,9 @@ func (cfg *ApiConfig) UpdateUserHandler(writer http.ResponseWriter, request *htt
 		return
 	}
 
-	// Get the bearer token from the request
-	token, tokenErr := GetBearerToken(request.Header)
-	if tokenErr != nil {
-		cfg.LogError(SERVER_MSG_ERROR_GET_BEARER_TOKEN, tokenErr)
-		RespondWithError(writer, http.StatusUnauthorized, CLIENT_MSG_ERROR_UPDATE_USER)
-		return
-	}
-
-	// Verify the bearer token and get the id
-	userId, jwtErr := ValidateJWT(token, cfg.TokenSecret)
-	if jwtErr != nil {
-		cfg.LogError(SERVER_MSG_JWT_VALIDATION_FAILED, jwtErr)
-		RespondWithError(writer, http.StatusUnauthorized, CLIENT_MSG_ERROR_UPDATE_USER)
-		return
-	}
+	// Get the authenticated user
+	user := UserFromContext(request.Context())
+	userId := user.ID
 
 	// Get the file from request
 	const maxMemory = 10 << 30
@@ -84,44 +65,34 @@ func (cfg *ApiConfig) UpdateUserHandler(writer http.ResponseWriter, request *htt
 	// Parse multipart form
 	request.ParseMultipartForm(maxMemory)
 
-	// Upload it to AWS Server and get the download url
-	downloadUrl, uploadErr := UploadFileToAWS(
-		"profile",
-		"image/",
-		request,
-		cfg.S3Client,
-		cfg.S3Bucket,
-		cfg.S3Region,
-	)
-	if uploadErr != nil {
-		cfg.LogError(SERVER_MSG_ERROR_UPLOADING_PHOTO, uploadErr)
-		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_UPLOADING_PROFILE_PICTURE)
-		return
-	}
-
 	// Create params to update user
 	params := database.UpdateUserProfileParams{
 		ID:       userId,
-		FullName: request.FormValue("full_name"),
-		UserName: request.FormValue("user_name"),
+		FullName: pgtype.Text{String: request.FormValue("full_name"), Valid: true},
 		Dob: pgtype.Date{
 			Time:  dobParsed,
 			Valid: true,
 		},
-		ProfileImageUrl: pgtype.Text{
-			String: downloadUrl,
-			Valid:  true,
-		},
 	}
 
 	// Update the user in db using the id from bearer token
-	updatedUser, updateErr := cfg.Db.UpdateUserProfile(request.Context(), params)
+	userName := request.FormValue("user_name")
+	updatedUser, updateErr := cfg.updateProfile(request.Context(), user, params, &userName)
 	if updateErr != nil {
-		cfg.LogError(SERVER_MSG_UPDATE_USER_ERROR, updateErr)
-		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_UPDATE_USER)
+		cfg.respondWithUpdateProfileError(writer, user, updateErr)
 		return
 	}
 
+	// The profile picture is optional. PUT /api/me/avatar is the way to change only the picture.
+	if _, _, fileErr := request.FormFile("profile"); fileErr == nil {
+		avatarUser, avatarErr := cfg.replaceProfileImage(request, updatedUser)
+		if avatarErr != nil {
+			cfg.respondWithProfileImageError(writer, avatarErr)
+			return
+		}
+		updatedUser = avatarUser
+	}
+
 	// Bulk inserting into users_has_interests
 	// Get Duplicate interest ids
 	interestsIds := request.MultipartForm.Value["ids"]
@@ -194,7 +165,7 @@ func (cfg *ApiConfig) UpdateUserHandler(writer http.ResponseWriter, request *htt
 	}
 
 	// Create JWT
-	tokenString, jwtErr := MakeJWT(updatedUser.ID, cfg.TokenSecret, app.JWT_EXPIRE_TIME)
+	tokenString, jwtErr := MakeJWT(updatedUser.ID, updatedUser.TokenVersion, cfg.Keys, app.JWT_EXPIRE_TIME)
 	if jwtErr != nil {
 		cfg.LogError(SERVER_MSG_MAKE_JWT_FAILED, jwtErr)
 		RespondWithError(writer, http.StatusInternalServerError, jwtErr.Error())
@@ -203,16 +174,8 @@ func (cfg *ApiConfig) UpdateUserHandler(writer http.ResponseWriter, request *htt
 
 	// Create the response
 	response := userWithTokenResponse{
-		ID:              updatedUser.ID,
-		Email:           updatedUser.Email,
-		UserName:        updatedUser.UserName,
-		FullName:        updatedUser.FullName,
-		ProfileImageUrl: updatedUser.ProfileImageUrl.String,
-		Dob:             FormatNullDobString(updatedUser.Dob.Time),
-		CreatedAt:       updatedUser.CreatedAt.Time,
-		UpdatedAt:       updatedUser.UpdatedAt.Time,
-		Interests:       interests,
-		AccessToken:     tokenString,
+		privateUserResponse: newPrivateUserResponse(updatedUser, interests),
+		AccessToken:         tokenString,
 	}
 
 	RespondWithJson(writer, http.StatusOK, response)
@@ -221,7 +184,7 @@ func (cfg *ApiConfig) UpdateUserHandler(writer http.ResponseWriter, request *htt
 func (cfg *ApiConfig) RegisterHandler(writer http.ResponseWriter, request *http.Request) {
 	// Decode the request
 	decoder := json.NewDecoder(request.Body)
-	loginRequest := emailAndPasswordRequest{}
+	loginRequest := registerRequest{}
 	if decodeErr := decoder.Decode(&loginRequest); decodeErr != nil {
 		RespondWithError(writer, http.StatusBadRequest, decodeErr.Error())
 		return
@@ -237,6 +200,40 @@ func (cfg *ApiConfig) RegisterHandler(writer http.ResponseWriter, request *http.
 		return
 	}
 
+	if emailErr := validators.ValidateEmail(loginRequest.Email); emailErr != nil {
+		RespondWithError(writer, http.StatusBadRequest, CLIENT_MSG_INVALID_EMAIL)
+		return
+	}
+
+	userName := loginRequest.UserName
+	if userName == "" {
+		randomName, randomErr := randomUserName()
+		if randomErr != nil {
+			cfg.LogError(SERVER_MSG_CREATE_USER_FAILED, randomErr)
+			RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_CREATE_USER_ERROR)
+			return
+		}
+		userName = randomName
+	} else {
+		if userNameErr := validators.ValidateUserName(userName); userNameErr != nil {
+			RespondWithError(writer, http.StatusBadRequest, userNameErr.Error())
+			return
+		}
+
+		taken, takenErr := cfg.Db.IsUserNameTaken(request.Context(), database.IsUserNameTakenParams{
+			UserName: userName,
+		})
+		if takenErr != nil {
+			cfg.LogError(SERVER_MSG_CREATE_USER_FAILED, takenErr)
+			RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_CREATE_USER_ERROR)
+			return
+		}
+		if taken {
+			RespondWithError(writer, http.StatusConflict, CLIENT_MSG_USER_NAME_TAKEN)
+			return
+		}
+	}
+
 	// Hash the password
 	password := loginRequest.Password
 	hashedPassword, hashErr := HashPassword(password)
@@ -251,46 +248,29 @@ func (cfg *ApiConfig) RegisterHandler(writer http.ResponseWriter, request *http.
 		Email:          loginRequest.Email,
 		HashedPassword: hashedPassword,
 		FullName:       "",
-		UserName:       "",
+		UserName:       userName,
 	}
 	createdUser, createUserErr := cfg.Db.CreateUser(request.Context(), createUserParams)
 	if createUserErr != nil {
+		if isUniqueViolationOn(createUserErr, usersUserNameUniqueIndex) {
+			RespondWithError(writer, http.StatusConflict, CLIENT_MSG_USER_NAME_TAKEN)
+			return
+		}
+		if isUniqueViolation(createUserErr) {
+			RespondWithError(writer, http.StatusConflict, CLIENT_MSG_EMAIL_ALREADY_TAKEN)
+			return
+		}
 		cfg.LogError(SERVER_MSG_CREATE_USER_FAILED, createUserErr)
 		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_CREATE_USER_ERROR)
 		return
 	}
 
-	// Create JWT
-	tokenString, jwtErr := MakeJWT(createdUser.ID, cfg.TokenSecret, app.JWT_EXPIRE_TIME)
-	if jwtErr != nil {
-		cfg.LogError(SERVER_MSG_MAKE_JWT_FAILED, jwtErr)
-		RespondWithError(writer, http.StatusInternalServerError, jwtErr.Error())
-		return
-	}
-
-	// Get Interests For User
-	interests, getInterestsErr := getInterestsForUser(createdUser.ID, request, cfg.Db)
-	if getInterestsErr != nil {
-		cfg.LogError(SERVER_MSG_GETTING_INTEREST_FOR_USER_FAILED, getInterestsErr)
-		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_CREATE_USER_ERROR)
-		return
+	// Send the verification email. The account is created anyway, the user can ask for another email later.
+	if _, sendErr := cfg.sendVerificationEmail(request.Context(), createdUser); sendErr != nil {
+		cfg.LogError(SERVER_MSG_SEND_MAIL_FAILED, sendErr)
 	}
 
-	// Create the response
-	response := userWithTokenResponse{
-		ID:              createdUser.ID,
-		Email:           createdUser.Email,
-		UserName:        createdUser.UserName,
-		FullName:        createdUser.FullName,
-		ProfileImageUrl: createdUser.ProfileImageUrl.String,
-		Dob:             FormatNullDobString(createdUser.Dob.Time),
-		CreatedAt:       createdUser.CreatedAt.Time,
-		UpdatedAt:       createdUser.UpdatedAt.Time,
-		AccessToken:     tokenString,
-		Interests:       interests,
-	}
-
-	RespondWithJson(writer, http.StatusCreated, response)
+	cfg.respondWithNewSession(writer, request, createdUser, http.StatusCreated, CLIENT_MSG_CREATE_USER_ERROR)
 }
 
 func (cfg *ApiConfig) LoginHandler(writer http.ResponseWriter, request *http.Request) {
@@ -312,56 +292,118 @@ func (cfg *ApiConfig) LoginHandler(writer http.ResponseWriter, request *http.Req
 		return
 	}
 
+	// Refuse to check the password while the email or the ip address is locked out
+	attemptEmail := normalizeLoginEmail(loginRequest.Email)
+	ipAddress := clientIP(request)
+	retryAfter, retryAfterErr := cfg.loginRetryAfter(request.Context(), attemptEmail, ipAddress)
+	if retryAfterErr != nil {
+		cfg.LogError(SERVER_MSG_LOGIN_FAILED, retryAfterErr)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_LOGIN_ERROR)
+		return
+	}
+	if retryAfter > 0 {
+		respondWithLoginLockout(writer, retryAfter)
+		return
+	}
+
 	// Find user by email
-	userFromDb, getUserErr := cfg.Db.GetUserByEmail(request.Context(), loginRequest.Email)
-	if getUserErr != nil {
-		if errors.Is(getUserErr, sql.ErrNoRows) {
-			RespondWithError(writer, http.StatusNotFound, CLIENT_MSG_INCORRECT_EMAIL_OR_PASSWORD)
-			return
-		}
+	// Deleted and deactivated users are included, logging in restores them.
+	userFromDb, getUserErr := cfg.Db.GetUserByEmailIncludingInactive(request.Context(), loginRequest.Email)
+	if getUserErr != nil && !errors.Is(getUserErr, sql.ErrNoRows) {
 		cfg.LogError(SERVER_MSG_LOGIN_FAILED, getUserErr)
-		RespondWithError(writer, http.StatusNotFound, getUserErr.Error())
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_LOGIN_ERROR)
+		return
+	}
+	if errors.Is(getUserErr, sql.ErrNoRows) || cfg.isPurgePending(userFromDb) {
+		// Compare anyway so that unknown emails take as long as wrong passwords
+		CheckPasswordHash(dummyPasswordHash(), loginRequest.Password)
+		cfg.respondWithLoginFailure(writer, request, attemptEmail, ipAddress, pgtype.Int8{})
 		return
 	}
 
 	// Verify password
 	password := loginRequest.Password
 	if checkPassErr := CheckPasswordHash(userFromDb.HashedPassword, password); checkPassErr != nil {
-		RespondWithError(writer, http.StatusNotFound, CLIENT_MSG_INCORRECT_EMAIL_OR_PASSWORD)
+		cfg.respondWithLoginFailure(writer, request, attemptEmail, ipAddress, pgtype.Int8{Int64: userFromDb.ID, Valid: true})
 		return
 	}
 
+	// Users with 2fa enabled have to finish the login with POST /api/login/2fa
+	if userFromDb.TotpEnabledAt.Valid {
+		mfaToken, mfaErr := MakeMFAToken(userFromDb.ID, userFromDb.TokenVersion, cfg.Keys, app.MFA_TOKEN_EXPIRE_TIME)
+		if mfaErr != nil {
+			cfg.LogError(SERVER_MSG_MAKE_JWT_FAILED, mfaErr)
+			RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_LOGIN_ERROR)
+			return
+		}
+
+		RespondWithJson(writer, http.StatusOK, mfaRequiredResponse{MfaRequired: true, MfaToken: mfaToken})
+		return
+	}
+
+	// Record the successful login. Logins with 2fa are recorded by LoginTwoFactorHandler once the second factor is checked.
+	if recordErr := cfg.recordLoginSuccess(request.Context(), attemptEmail, ipAddress); recordErr != nil {
+		cfg.LogError(SERVER_MSG_LOGIN_FAILED, recordErr)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_LOGIN_ERROR)
+		return
+	}
+
+	restoredUser, restoreErr := cfg.restoreUserIfInactive(request.Context(), userFromDb)
+	if restoreErr != nil {
+		cfg.LogError(SERVER_MSG_RESTORE_ACCOUNT_FAILED, restoreErr)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_LOGIN_ERROR)
+		return
+	}
+
+	cfg.respondWithNewSession(writer, request, restoredUser, http.StatusOK, CLIENT_MSG_LOGIN_ERROR)
+}
+
+// Store the failed login and respond with the same 401 for unknown emails and wrong passwords.
+func (cfg *ApiConfig) respondWithLoginFailure(writer http.ResponseWriter, request *http.Request, email, ipAddress string, userId pgtype.Int8) {
+	if recordErr := cfg.recordLoginFailure(request.Context(), email, ipAddress, userId); recordErr != nil {
+		cfg.LogError(SERVER_MSG_LOGIN_FAILED, recordErr)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_LOGIN_ERROR)
+		return
+	}
+
+	RespondWithError(writer, http.StatusUnauthorized, CLIENT_MSG_INCORRECT_EMAIL_OR_PASSWORD)
+}
+
+// Start a new session for the user and respond with the user, an access token and a refresh token.
+// errorMsg is sent to the client if anything goes wrong.
+func (cfg *ApiConfig) respondWithNewSession(writer http.ResponseWriter, request *http.Request, user database.User, statusCode int, errorMsg string) {
 	// Create JWT
-	tokenString, jwtErr := MakeJWT(userFromDb.ID, cfg.TokenSecret, app.JWT_EXPIRE_TIME)
+	tokenString, jwtErr := MakeJWT(user.ID, user.TokenVersion, cfg.Keys, app.JWT_EXPIRE_TIME)
 	if jwtErr != nil {
 		cfg.LogError(SERVER_MSG_MAKE_JWT_FAILED, jwtErr)
 		RespondWithError(writer, http.StatusInternalServerError, jwtErr.Error())
 		return
 	}
 
+	// Create refresh token
+	refreshToken, refreshTokenErr := cfg.createRefreshToken(request.Context(), user.ID, "")
+	if refreshTokenErr != nil {
+		cfg.LogError(SERVER_MSG_CREATE_SESSION_FAILED, refreshTokenErr)
+		RespondWithError(writer, http.StatusInternalServerError, errorMsg)
+		return
+	}
+
 	// Get Interests For User
-	interests, getInterestsErr := getInterestsForUser(userFromDb.ID, request, cfg.Db)
+	interests, getInterestsErr := getInterestsForUser(user.ID, request, cfg.Db)
 	if getInterestsErr != nil {
 		cfg.LogError(SERVER_MSG_GETTING_INTEREST_FOR_USER_FAILED, getInterestsErr)
-		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_INCORRECT_EMAIL_OR_PASSWORD)
+		RespondWithError(writer, http.StatusInternalServerError, errorMsg)
 		return
 	}
 
 	// Create the response
 	response := userWithTokenResponse{
-		ID:              userFromDb.ID,
-		Email:           userFromDb.Email,
-		UserName:        userFromDb.UserName,
-		FullName:        userFromDb.FullName,
-		ProfileImageUrl: userFromDb.ProfileImageUrl.String,
-		Dob:             FormatNullDobString(userFromDb.Dob.Time),
-		CreatedAt:       userFromDb.CreatedAt.Time,
-		UpdatedAt:       userFromDb.UpdatedAt.Time,
-		AccessToken:     tokenString,
-		Interests:       interests,
+		privateUserResponse: newPrivateUserResponse(user, interests),
+		AccessToken:         tokenString,
+		RefreshToken:        refreshToken,
 	}
 
-	RespondWithJson(writer, http.StatusOK, response)
+	RespondWithJson(writer, statusCode, response)
 }
 
 // Get Interests for user
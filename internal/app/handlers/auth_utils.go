@@ -1,15 +1,21 @@
 package handlers
 
 import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"net/http"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/app"
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/app/jwtkeys"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -38,21 +44,40 @@ func CheckPasswordHash(hash, password string) error {
 	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
 }
 
+// Access token claims. TokenVersion must match the user's token_version or the token is treated as revoked.
+type AccessTokenClaims struct {
+	jwt.RegisteredClaims
+	TokenVersion int32 `json:"ver"`
+}
+
+// Checks whether an access token was revoked before it expired.
+type TokenRevocationChecker interface {
+	IsRevoked(ctx context.Context, claims *AccessTokenClaims) (bool, error)
+}
+
 // JWT
-func MakeJWT(id int64, tokenSecret string, expiresIn time.Duration) (string, error) {
+func MakeJWT(id int64, tokenVersion int32, keys *jwtkeys.KeySet, expiresIn time.Duration) (string, error) {
 	if id == 0 {
 		return "", fmt.Errorf("the id must not be 0")
 	}
 
-	token := jwt.NewWithClaims(
-		jwt.SigningMethodHS256, jwt.RegisteredClaims{
+	// Unique token id so that a single token can be revoked on logout
+	jti, jtiErr := MakeRandomToken()
+	if jtiErr != nil {
+		return "", jtiErr
+	}
+
+	signedToken, err := keys.Sign(AccessTokenClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
 			Issuer:    "sanctuary",
+			Audience:  jwt.ClaimStrings{app.JWT_AUDIENCE_ACCESS},
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expiresIn)),
 			Subject:   strconv.FormatInt(id, 10),
-		})
-
-	signedToken, err := token.SignedString([]byte(tokenSecret))
+		},
+		TokenVersion: tokenVersion,
+	})
 	if err != nil {
 		return "", fmt.Errorf("error signing token %w", err)
 	}
@@ -60,20 +85,62 @@ func MakeJWT(id int64, tokenSecret string, expiresIn time.Duration) (string, err
 	return signedToken, nil
 }
 
-// Validate JWT Token
-func ValidateJWT(tokenString, tokenSecret string) (int64, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &jwt.RegisteredClaims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return 0, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+// Parse JWT Token. Only checks the signature, expiry and audience, not revocation.
+func ParseJWT(tokenString string, keys *jwtkeys.KeySet) (*AccessTokenClaims, error) {
+	claims := &AccessTokenClaims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, keys.Keyfunc)
+	if err != nil {
+		return nil, err
+	}
+
+	// Access tokens issued before audiences were added have none. They expire within JWT_EXPIRE_TIME,
+	// so they are only accepted until then instead of logging everyone out. Every other kind of token has its own audience.
+	if len(claims.Audience) == 0 && claims.IssuedAt != nil && claims.ExpiresAt != nil &&
+		claims.ExpiresAt.Sub(claims.IssuedAt.Time) <= app.JWT_EXPIRE_TIME {
+		return claims, nil
+	}
+	if !slices.Contains(claims.Audience, app.JWT_AUDIENCE_ACCESS) {
+		return nil, jwt.ErrTokenInvalidAudience
+	}
+
+	return claims, nil
+}
+
+// Validate JWT Token and return its claims.
+// Pass nil revocations to skip the revocation check.
+func ValidateJWTClaims(ctx context.Context, tokenString string, keys *jwtkeys.KeySet, revocations TokenRevocationChecker) (*AccessTokenClaims, error) {
+	claims, err := ParseJWT(tokenString, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	if revocations != nil {
+		revoked, revokedErr := revocations.IsRevoked(ctx, claims)
+		if revokedErr != nil {
+			return nil, revokedErr
+		}
+		if revoked {
+			return nil, errors.New("the token has been revoked")
 		}
+	}
 
-		return []byte(tokenSecret), nil
-	})
+	return claims, nil
+}
+
+// Validate JWT Token and return the user id.
+// Pass nil revocations to skip the revocation check.
+func ValidateJWT(ctx context.Context, tokenString string, keys *jwtkeys.KeySet, revocations TokenRevocationChecker) (int64, error) {
+	claims, err := ValidateJWTClaims(ctx, tokenString, keys, revocations)
 	if err != nil {
 		return 0, err
 	}
 
-	userIdString, userIdErr := token.Claims.GetSubject()
+	return claims.UserId()
+}
+
+// Get the user id from the subject claim
+func (claims *AccessTokenClaims) UserId() (int64, error) {
+	userIdString, userIdErr := claims.GetSubject()
 	if userIdErr != nil {
 		return 0, userIdErr
 	}
@@ -85,6 +152,102 @@ func ValidateJWT(tokenString, tokenSecret string) (int64, error) {
 	return userId, nil
 }
 
+// Email verification token claims. The email is included so the link stops working once the email changes.
+type EmailVerificationClaims struct {
+	jwt.RegisteredClaims
+	Email string `json:"email"`
+}
+
+// Make a signed email verification token
+func MakeEmailVerificationToken(id int64, email string, keys *jwtkeys.KeySet, expiresIn time.Duration) (string, error) {
+	if id == 0 {
+		return "", fmt.Errorf("the id must not be 0")
+	}
+
+	signedToken, err := keys.Sign(EmailVerificationClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    "sanctuary",
+			Audience:  jwt.ClaimStrings{app.JWT_AUDIENCE_EMAIL_VERIFICATION},
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expiresIn)),
+			Subject:   strconv.FormatInt(id, 10),
+		},
+		Email: email,
+	})
+	if err != nil {
+		return "", fmt.Errorf("error signing token %w", err)
+	}
+
+	return signedToken, nil
+}
+
+// Validate email verification token and return the user id and email
+func ValidateEmailVerificationToken(tokenString string, keys *jwtkeys.KeySet) (int64, string, error) {
+	claims := &EmailVerificationClaims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, keys.Keyfunc, jwt.WithAudience(app.JWT_AUDIENCE_EMAIL_VERIFICATION))
+	if err != nil {
+		return 0, "", err
+	}
+
+	userId, parseErr := strconv.ParseInt(claims.Subject, 10, 64)
+	if parseErr != nil {
+		return 0, "", fmt.Errorf("error parsing user id : %w", parseErr)
+	}
+
+	return userId, claims.Email, nil
+}
+
+// Make a short lived token that proves the password was correct but the second factor is still missing.
+// It is not an access token, so it cannot be used on any other endpoint.
+func MakeMFAToken(id int64, tokenVersion int32, keys *jwtkeys.KeySet, expiresIn time.Duration) (string, error) {
+	if id == 0 {
+		return "", fmt.Errorf("the id must not be 0")
+	}
+
+	signedToken, err := keys.Sign(AccessTokenClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    "sanctuary",
+			Audience:  jwt.ClaimStrings{app.JWT_AUDIENCE_MFA},
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expiresIn)),
+			Subject:   strconv.FormatInt(id, 10),
+		},
+		TokenVersion: tokenVersion,
+	})
+	if err != nil {
+		return "", fmt.Errorf("error signing token %w", err)
+	}
+
+	return signedToken, nil
+}
+
+// Validate mfa token and return its claims
+func ValidateMFAToken(tokenString string, keys *jwtkeys.KeySet) (*AccessTokenClaims, error) {
+	claims := &AccessTokenClaims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, keys.Keyfunc, jwt.WithAudience(app.JWT_AUDIENCE_MFA))
+	if err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+// Make a random opaque token. Used for refresh tokens and jwt ids.
+func MakeRandomToken() (string, error) {
+	randomBytes := make([]byte, 32)
+	if _, err := rand.Read(randomBytes); err != nil {
+		return "", fmt.Errorf("error generating random token %w", err)
+	}
+	return hex.EncodeToString(randomBytes), nil
+}
+
+// Hash an opaque token before storing it.
+// The tokens are 32 random bytes, so a fast hash is enough here. No need for bcrypt.
+func HashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
 // Get Bearer Token
 func GetBearerToken(headers http.Header) (string, error) {
 	// Get bearer token
@@ -0,0 +1,202 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/app"
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/database"
+)
+
+// Block User. Hides the user's posts and comments, stops them from liking or commenting on your posts and removes follows and follow requests both ways.
+func (cfg *ApiConfig) BlockUserHandler(writer http.ResponseWriter, request *http.Request) {
+	userId := UserIdFromContext(request.Context())
+
+	blocked, ok := cfg.getActiveUserFromPath(writer, request, CLIENT_MSG_ERROR_BLOCK)
+	if !ok {
+		return
+	}
+	if blocked.ID == userId {
+		RespondWithError(writer, http.StatusBadRequest, CLIENT_MSG_CANNOT_BLOCK_YOURSELF)
+		return
+	}
+
+	// The block and the removed follows are saved together
+	txErr := cfg.inTx(request.Context(), func(qtx *database.Queries) error {
+		// Blocking someone you already blocked does nothing
+		params := database.CreateUserBlockParams{
+			BlockerID: userId,
+			BlockedID: blocked.ID,
+		}
+		if _, err := qtx.CreateUserBlock(request.Context(), params); err != nil {
+			return err
+		}
+
+		deleteFollowsParams := database.DeleteFollowsBetweenParams{
+			UserID:      userId,
+			OtherUserID: blocked.ID,
+		}
+		if err := qtx.DeleteFollowsBetween(request.Context(), deleteFollowsParams); err != nil {
+			return err
+		}
+
+		deleteRequestsParams := database.DeleteFollowRequestsBetweenParams{
+			UserID:      userId,
+			OtherUserID: blocked.ID,
+		}
+		return qtx.DeleteFollowRequestsBetween(request.Context(), deleteRequestsParams)
+	})
+	if txErr != nil {
+		cfg.LogError(txErr.Error(), txErr)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_BLOCK)
+		return
+	}
+
+	writer.WriteHeader(http.StatusOK)
+}
+
+// Unblock User
+func (cfg *ApiConfig) UnblockUserHandler(writer http.ResponseWriter, request *http.Request) {
+	userId := UserIdFromContext(request.Context())
+
+	blockedId, parseErr := userIdFromPath(request)
+	if parseErr != nil {
+		RespondWithError(writer, http.StatusBadRequest, CLIENT_MSG_INVALID_USER_ID)
+		return
+	}
+
+	params := database.DeleteUserBlockParams{
+		BlockerID: userId,
+		BlockedID: blockedId,
+	}
+	if _, err := cfg.Db.DeleteUserBlock(request.Context(), params); err != nil {
+		cfg.LogError(err.Error(), err)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_UNBLOCK)
+		return
+	}
+
+	writer.WriteHeader(http.StatusOK)
+}
+
+// Mute User. Only hides the user's posts and comments.
+func (cfg *ApiConfig) MuteUserHandler(writer http.ResponseWriter, request *http.Request) {
+	userId := UserIdFromContext(request.Context())
+
+	muted, ok := cfg.getActiveUserFromPath(writer, request, CLIENT_MSG_ERROR_MUTE)
+	if !ok {
+		return
+	}
+	if muted.ID == userId {
+		RespondWithError(writer, http.StatusBadRequest, CLIENT_MSG_CANNOT_MUTE_YOURSELF)
+		return
+	}
+
+	// Muting someone you already muted does nothing
+	params := database.CreateUserMuteParams{
+		MuterID: userId,
+		MutedID: muted.ID,
+	}
+	if _, err := cfg.Db.CreateUserMute(request.Context(), params); err != nil {
+		cfg.LogError(err.Error(), err)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_MUTE)
+		return
+	}
+
+	writer.WriteHeader(http.StatusOK)
+}
+
+// Unmute User
+func (cfg *ApiConfig) UnmuteUserHandler(writer http.ResponseWriter, request *http.Request) {
+	userId := UserIdFromContext(request.Context())
+
+	mutedId, parseErr := userIdFromPath(request)
+	if parseErr != nil {
+		RespondWithError(writer, http.StatusBadRequest, CLIENT_MSG_INVALID_USER_ID)
+		return
+	}
+
+	params := database.DeleteUserMuteParams{
+		MuterID: userId,
+		MutedID: mutedId,
+	}
+	if _, err := cfg.Db.DeleteUserMute(request.Context(), params); err != nil {
+		cfg.LogError(err.Error(), err)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_UNMUTE)
+		return
+	}
+
+	writer.WriteHeader(http.StatusOK)
+}
+
+// Get the users the authenticated user blocked, most recent first
+func (cfg *ApiConfig) GetBlockedUsersHandler(writer http.ResponseWriter, request *http.Request) {
+	page, pageErr := pageFromRequest(request)
+	if pageErr != nil {
+		RespondWithError(writer, http.StatusBadRequest, CLIENT_MSG_INVALID_PAGE)
+		return
+	}
+
+	// Get one more than the page size to know if there is a next page
+	params := database.GetBlockedUsersParams{
+		UserID:     UserIdFromContext(request.Context()),
+		PageLimit:  app.PAGE_SIZE + 1,
+		PageOffset: int32((page - 1) * app.PAGE_SIZE),
+	}
+	blockedUsers, err := cfg.Db.GetBlockedUsers(request.Context(), params)
+	if err != nil {
+		cfg.LogError(err.Error(), err)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_GET_BLOCKS)
+		return
+	}
+
+	users := []publicUserResponse{}
+	for _, blockedUser := range blockedUsers {
+		users = append(users, newPublicUserResponseFromColumns(
+			blockedUser.ID,
+			blockedUser.UserName,
+			blockedUser.FullName,
+			blockedUser.CreatedAt,
+			blockedUser.ProfileImageUrl,
+			blockedUser.ProfileImageFeedUrl,
+			blockedUser.ProfileImageThumbnailUrl,
+		))
+	}
+
+	RespondWithJson(writer, http.StatusOK, cfg.userListResponse(users, page, "/api/me/blocks"))
+}
+
+// Get the users the authenticated user muted, most recent first
+func (cfg *ApiConfig) GetMutedUsersHandler(writer http.ResponseWriter, request *http.Request) {
+	page, pageErr := pageFromRequest(request)
+	if pageErr != nil {
+		RespondWithError(writer, http.StatusBadRequest, CLIENT_MSG_INVALID_PAGE)
+		return
+	}
+
+	// Get one more than the page size to know if there is a next page
+	params := database.GetMutedUsersParams{
+		UserID:     UserIdFromContext(request.Context()),
+		PageLimit:  app.PAGE_SIZE + 1,
+		PageOffset: int32((page - 1) * app.PAGE_SIZE),
+	}
+	mutedUsers, err := cfg.Db.GetMutedUsers(request.Context(), params)
+	if err != nil {
+		cfg.LogError(err.Error(), err)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_GET_MUTES)
+		return
+	}
+
+	users := []publicUserResponse{}
+	for _, mutedUser := range mutedUsers {
+		users = append(users, newPublicUserResponseFromColumns(
+			mutedUser.ID,
+			mutedUser.UserName,
+			mutedUser.FullName,
+			mutedUser.CreatedAt,
+			mutedUser.ProfileImageUrl,
+			mutedUser.ProfileImageFeedUrl,
+			mutedUser.ProfileImageThumbnailUrl,
+		))
+	}
+
+	RespondWithJson(writer, http.StatusOK, cfg.userListResponse(users, page, "/api/me/mutes"))
+}
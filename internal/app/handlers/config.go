@@ -1,20 +1,32 @@
 package handlers
 
 import (
-	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/app/jwtkeys"
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/app/mailer"
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/app/ranking"
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/app/storage"
 	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/database"
 	"go.uber.org/zap"
 )
 
 // Api Config struct
 type ApiConfig struct {
-	Db          *database.Queries
-	Platform    string
-	TokenSecret string
-	S3Bucket    string
-	S3Region    string
-	S3Client    *s3.Client
+	Db       *database.Queries
+	Pool     *pgxpool.Pool
+	Platform string
+	Keys     *jwtkeys.KeySet
+	// Where uploaded files are stored
+	Storage     storage.Storage
 	Logger      *zap.Logger
+	Revocations *TokenRevocationCache
+	Mailer      mailer.Mailer
+	// Weights of the for you feed. Defaults to ranking.DefaultWeights when not set.
+	FeedWeights *ranking.Weights
+	// Clock used for time based checks such as totp codes. Defaults to time.Now when nil.
+	Now func() time.Time
 }
 
 // Get Base url
@@ -27,7 +39,23 @@ func (cfg *ApiConfig) GetBaseUrl() string {
 	}
 }
 
+// Get the current time from the configured clock
+func (cfg *ApiConfig) now() time.Time {
+	if cfg.Now != nil {
+		return cfg.Now()
+	}
+	return time.Now()
+}
+
 // Util function to log error
 func (cfg *ApiConfig) LogError(message string, err error) {
 	cfg.Logger.Error("Create user error", zap.Error(err))
 }
+
+// Get the configured feed weights
+func (cfg *ApiConfig) feedWeights() ranking.Weights {
+	if cfg.FeedWeights != nil {
+		return *cfg.FeedWeights
+	}
+	return ranking.DefaultWeights()
+}
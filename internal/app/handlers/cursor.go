@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Position of an item in a list ordered by (created_at, id).
+// Clients get cursors as opaque strings and send them back in the cursor query parameter.
+type Cursor struct {
+	CreatedAt time.Time `json:"t"`
+	ID        int64     `json:"i"`
+	// Prev cursors ask for the items before the cursor instead of the items after it
+	Prev bool `json:"p,omitempty"`
+}
+
+// A page of items with the cursors of the pages around it. A cursor is empty if there is no page there.
+type CursorPage[T any] struct {
+	Items      []T
+	NextCursor string
+	PrevCursor string
+}
+
+func EncodeCursor(cursor Cursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func DecodeCursor(value string) (Cursor, error) {
+	data, decodeErr := base64.RawURLEncoding.DecodeString(value)
+	if decodeErr != nil {
+		return Cursor{}, fmt.Errorf("invalid cursor %w", decodeErr)
+	}
+
+	cursor := Cursor{}
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return Cursor{}, fmt.Errorf("invalid cursor %w", err)
+	}
+	if cursor.CreatedAt.IsZero() || cursor.ID <= 0 {
+		return Cursor{}, fmt.Errorf("invalid cursor %v", value)
+	}
+	return cursor, nil
+}
+
+// Make a page from items fetched with a limit of pageSize + 1, so that the extra item tells if there are more.
+//
+// cursor is the cursor the items were fetched with, nil for the first page.
+// For a Prev cursor the items are expected in reverse order, the way the query walking backwards returns them.
+// key gets the cursor of an item.
+func NewCursorPage[T any](items []T, pageSize int, cursor *Cursor, key func(T) Cursor) CursorPage[T] {
+	hasMore := len(items) > pageSize
+	if hasMore {
+		items = items[:pageSize]
+	}
+
+	backwards := cursor != nil && cursor.Prev
+	if backwards {
+		items = slices.Clone(items)
+		slices.Reverse(items)
+	}
+
+	page := CursorPage[T]{Items: items}
+	if len(items) == 0 {
+		return page
+	}
+
+	first := key(items[0])
+	first.Prev = true
+	last := key(items[len(items)-1])
+	last.Prev = false
+
+	if backwards {
+		// The page the client came from comes next
+		page.NextCursor = EncodeCursor(last)
+		if hasMore {
+			page.PrevCursor = EncodeCursor(first)
+		}
+	} else {
+		if hasMore {
+			page.NextCursor = EncodeCursor(last)
+		}
+		if cursor != nil {
+			page.PrevCursor = EncodeCursor(first)
+		}
+	}
+	return page
+}
+
+// Get the cursor query parameter. Returns nil if there is none.
+func cursorFromRequest(request *http.Request) (*Cursor, error) {
+	value := request.URL.Query().Get("cursor")
+	if value == "" {
+		return nil, nil
+	}
+
+	cursor, err := DecodeCursor(value)
+	if err != nil {
+		return nil, err
+	}
+	return &cursor, nil
+}
+
+// Get the position a query should continue from.
+// Without a cursor the first page starts from infinity, or from -infinity for lists that are oldest first.
+func cursorPosition(cursor *Cursor, start pgtype.InfinityModifier) (pgtype.Timestamp, int64) {
+	if cursor == nil {
+		return pgtype.Timestamp{InfinityModifier: start, Valid: true}, 0
+	}
+	return pgtype.Timestamp{Time: cursor.CreatedAt, Valid: true}, cursor.ID
+}
+
+// Build the meta response of a cursor page. path is the path of the list endpoint.
+func (cfg *ApiConfig) cursorMetaResponse(path string, nextCursor, prevCursor string) MetaResponse {
+	nextPageUrl := ""
+	if nextCursor != "" {
+		nextPageUrl = fmt.Sprintf("%v%v?cursor=%v", cfg.GetBaseUrl(), path, url.QueryEscape(nextCursor))
+	}
+
+	return MetaResponse{
+		NextPageUrl: nextPageUrl,
+		NextCursor:  nextCursor,
+		PrevCursor:  prevCursor,
+	}
+}
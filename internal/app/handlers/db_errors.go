@@ -0,0 +1,25 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Postgres error code for unique_violation
+const pgUniqueViolation = "23505"
+
+// Case insensitive unique index on users.user_name
+const usersUserNameUniqueIndex = "users_user_name_unique_idx"
+
+// Check whether the error comes from a UNIQUE constraint, e.g. an email that is already taken.
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation
+}
+
+// Check whether the error comes from a specific UNIQUE constraint or index
+func isUniqueViolationOn(err error, constraintName string) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation && pgErr.ConstraintName == constraintName
+}
@@ -15,8 +15,27 @@ const SERVER_MSG_PASSWORD_HASH_FAILED = "Password hash failed"
 const SERVER_MSG_GETTING_INTEREST_FOR_USER_FAILED = "Getting interests for user failed"
 const SERVER_MSG_LOGIN_FAILED = "Login Failed"
 const SERVER_MSG_CREATE_USER_FAILED = "Create user failed."
+const SERVER_MSG_CREATE_SESSION_FAILED = "Create session failed."
+const SERVER_MSG_GET_SESSION_FAILED = "Get session failed."
+const SERVER_MSG_REFRESH_TOKEN_REUSED = "Refresh token reused. Revoking session family."
+const SERVER_MSG_REVOKE_SESSION_FAILED = "Revoke session failed."
+const SERVER_MSG_REVOKE_TOKEN_FAILED = "Revoke token failed."
+const SERVER_MSG_GET_TOKEN_VERSION_FAILED = "Get token version failed."
+const SERVER_MSG_GET_USER_FAILED = "Get user failed."
+const SERVER_MSG_CREATE_RESET_TOKEN_FAILED = "Create password reset token failed."
+const SERVER_MSG_GET_RESET_TOKEN_FAILED = "Get password reset token failed."
+const SERVER_MSG_UPDATE_PASSWORD_FAILED = "Update password failed."
+const SERVER_MSG_SEND_MAIL_FAILED = "Send mail failed."
+const SERVER_MSG_VERIFY_EMAIL_FAILED = "Verify email failed."
+const SERVER_MSG_TWO_FACTOR_FAILED = "Two factor authentication failed."
+const SERVER_MSG_CHANGE_EMAIL_FAILED = "Change email failed."
+const SERVER_MSG_DELETE_ACCOUNT_FAILED = "Delete account failed."
+const SERVER_MSG_RESTORE_ACCOUNT_FAILED = "Restore account failed."
+const SERVER_MSG_PURGE_ACCOUNT_FAILED = "Purge account failed."
+const SERVER_MSG_PURGE_POST_MEDIA_FAILED = "Purge post media failed."
 
 // Client
+const CLIENT_MSG_UNAUTHORIZED = "You are not authorized. Please log in and try again."
 const CLIENT_MSG_ERROR_UPDATE_USER = "Something went wrong while updating your personal information. Please try agin."
 const CLIENT_MSG_ERROR_UPLOADING_PROFILE_PICTURE = "Something went wrong while uploading your profile picture. Please try agin."
 const CLIENT_MSG_EMAIL_CANNOT_BE_EMPTY = "Email cannot be empty"
@@ -24,3 +43,92 @@ const CLIENT_MSG_PASSWORD_CANNOT_BE_EMPTY = "Password cannot be empty"
 
 const CLIENT_MSG_CREATE_USER_ERROR = "Something went wrong while creating the user. Please try again."
 const CLIENT_MSG_INCORRECT_EMAIL_OR_PASSWORD = "Incorrect email or password. Please try again."
+const CLIENT_MSG_LOGIN_ERROR = "Something went wrong while logging in. Please try again."
+const CLIENT_MSG_REFRESH_TOKEN_CANNOT_BE_EMPTY = "Refresh token cannot be empty"
+const CLIENT_MSG_INVALID_REFRESH_TOKEN = "Your session has expired. Please log in again."
+const CLIENT_MSG_ERROR_REFRESH_TOKEN = "Something went wrong while refreshing your session. Please try again."
+const CLIENT_MSG_ERROR_LOGOUT = "Something went wrong while logging out. Please try again."
+const CLIENT_MSG_ERROR_FORGOT_PASSWORD = "Something went wrong while sending the password reset email. Please try again."
+const CLIENT_MSG_ERROR_RESET_PASSWORD = "Something went wrong while resetting your password. Please try again."
+const CLIENT_MSG_INVALID_RESET_TOKEN = "The password reset token is invalid or has expired."
+const CLIENT_MSG_INVALID_EMAIL = "Please enter a valid email."
+const CLIENT_MSG_INVALID_VERIFICATION_LINK = "The verification link is invalid or has expired."
+const CLIENT_MSG_ERROR_VERIFY_EMAIL = "Something went wrong while verifying your email. Please try again."
+const CLIENT_MSG_EMAIL_ALREADY_VERIFIED = "Your email is already verified."
+const CLIENT_MSG_EMAIL_NOT_VERIFIED = "Please verify your email first."
+const CLIENT_MSG_ERROR_SEND_VERIFICATION_EMAIL = "Something went wrong while sending the verification email. Please try again."
+const CLIENT_MSG_VERIFICATION_EMAIL_COOLDOWN = "A verification email was sent recently. Please wait a moment before asking for another one."
+const CLIENT_MSG_INVALID_TWO_FACTOR_CODE = "The code is invalid. Please try again."
+const CLIENT_MSG_INVALID_MFA_TOKEN = "Your login has expired. Please log in again."
+const CLIENT_MSG_TWO_FACTOR_ALREADY_ENABLED = "Two factor authentication is already enabled."
+const CLIENT_MSG_TWO_FACTOR_NOT_ENABLED = "Two factor authentication is not enabled."
+const CLIENT_MSG_TWO_FACTOR_NOT_ENROLLED = "Please start the two factor authentication setup first."
+const CLIENT_MSG_ERROR_TWO_FACTOR = "Something went wrong while setting up two factor authentication. Please try again."
+const CLIENT_MSG_TOO_MANY_LOGIN_ATTEMPTS = "Too many failed login attempts. Please try again later."
+const CLIENT_MSG_EMAIL_ALREADY_TAKEN = "This email is already in use."
+const CLIENT_MSG_INCORRECT_PASSWORD = "Incorrect password. Please try again."
+const CLIENT_MSG_NEW_PASSWORD_CANNOT_BE_EMPTY = "New password cannot be empty"
+const CLIENT_MSG_ERROR_CHANGE_PASSWORD = "Something went wrong while changing your password. Please try again."
+const CLIENT_MSG_SAME_EMAIL = "The new email is the same as your current email."
+const CLIENT_MSG_ERROR_CHANGE_EMAIL = "Something went wrong while changing your email. Please try again."
+const CLIENT_MSG_INVALID_EMAIL_CHANGE_LINK = "The confirmation link is invalid or has expired."
+const CLIENT_MSG_ERROR_DELETE_ACCOUNT = "Something went wrong while deleting your account. Please try again."
+const CLIENT_MSG_ERROR_DEACTIVATE_ACCOUNT = "Something went wrong while deactivating your account. Please try again."
+const CLIENT_MSG_INVALID_USER_ID = "User id must be a number"
+const CLIENT_MSG_USER_NOT_FOUND = "The user does not exist."
+const CLIENT_MSG_ERROR_GET_USER_PROFILE = "Something went wrong while getting the user profile. Please try again."
+const CLIENT_MSG_CANNOT_FOLLOW_YOURSELF = "You cannot follow yourself."
+const CLIENT_MSG_ERROR_FOLLOW = "Something went wrong while following the user. Please try again."
+const CLIENT_MSG_ERROR_UNFOLLOW = "Something went wrong while unfollowing the user. Please try again."
+const CLIENT_MSG_ERROR_GET_FOLLOWS = "Something went wrong while getting the list. Please try again."
+const CLIENT_MSG_ERROR_GET_FEED = "Something went wrong while getting your feed. Please try again."
+const CLIENT_MSG_INVALID_CURSOR = "The cursor is invalid."
+const CLIENT_MSG_INVALID_PAGE = "The page is too large."
+const CLIENT_MSG_CANNOT_BLOCK_YOURSELF = "You cannot block yourself."
+const CLIENT_MSG_CANNOT_MUTE_YOURSELF = "You cannot mute yourself."
+const CLIENT_MSG_ERROR_BLOCK = "Something went wrong while blocking the user. Please try again."
+const CLIENT_MSG_ERROR_UNBLOCK = "Something went wrong while unblocking the user. Please try again."
+const CLIENT_MSG_ERROR_MUTE = "Something went wrong while muting the user. Please try again."
+const CLIENT_MSG_ERROR_UNMUTE = "Something went wrong while unmuting the user. Please try again."
+const CLIENT_MSG_ERROR_GET_BLOCKS = "Something went wrong while getting the users you blocked. Please try again."
+const CLIENT_MSG_ERROR_GET_MUTES = "Something went wrong while getting the users you muted. Please try again."
+const CLIENT_MSG_BLOCKED = "You cannot interact with this user."
+const CLIENT_MSG_POST_NOT_FOUND = "The post does not exist."
+const CLIENT_MSG_IS_PRIVATE_CANNOT_BE_EMPTY = "is_private must be provided"
+const CLIENT_MSG_ERROR_UPDATE_PRIVACY = "Something went wrong while updating your privacy setting. Please try again."
+const CLIENT_MSG_ERROR_CANCEL_FOLLOW_REQUEST = "Something went wrong while cancelling the follow request. Please try again."
+const CLIENT_MSG_ERROR_APPROVE_FOLLOW_REQUEST = "Something went wrong while approving the follow request. Please try again."
+const CLIENT_MSG_ERROR_DENY_FOLLOW_REQUEST = "Something went wrong while denying the follow request. Please try again."
+const CLIENT_MSG_ERROR_GET_FOLLOW_REQUESTS = "Something went wrong while getting your follow requests. Please try again."
+const CLIENT_MSG_FOLLOW_REQUEST_NOT_FOUND = "The follow request does not exist."
+const CLIENT_MSG_PRIVATE_FOLLOWS = "This account is private. Follow it to see its followers and who it follows."
+const CLIENT_MSG_PROFILE_PICTURE_CANNOT_BE_EMPTY = "Profile picture must be provided"
+const CLIENT_MSG_ERROR_DELETE_PROFILE_PICTURE = "Something went wrong while removing your profile picture. Please try again."
+const CLIENT_MSG_ADMIN_ONLY = "Only admins can do this."
+const CLIENT_MSG_INVALID_INTEREST_ID = "Interest id must be a number"
+const CLIENT_MSG_INTEREST_NOT_FOUND = "The interest does not exist."
+const CLIENT_MSG_INTEREST_IDS_CANNOT_BE_EMPTY = "ids must be provided"
+const CLIENT_MSG_INTEREST_ALREADY_EXISTS = "An interest with this name already exists."
+const CLIENT_MSG_CANNOT_MERGE_INTEREST_INTO_ITSELF = "An interest cannot be merged into itself."
+const CLIENT_MSG_ERROR_UPDATE_INTERESTS = "Something went wrong while updating your interests. Please try again."
+const CLIENT_MSG_ERROR_CREATE_INTEREST = "Something went wrong while creating the interest. Please try again."
+const CLIENT_MSG_ERROR_RENAME_INTEREST = "Something went wrong while renaming the interest. Please try again."
+const CLIENT_MSG_ERROR_DELETE_INTEREST = "Something went wrong while deleting the interest. Please try again."
+const CLIENT_MSG_ERROR_MERGE_INTEREST = "Something went wrong while merging the interests. Please try again."
+const CLIENT_MSG_USER_NAME_TAKEN = "This user name is already taken."
+const CLIENT_MSG_USER_NAME_CHANGE_COOLDOWN = "You changed your user name recently. Please try again later."
+const CLIENT_MSG_ERROR_CHECK_USER_NAME = "Something went wrong while checking the user name. Please try again."
+const CLIENT_MSG_INVALID_POST_ID = "Post id must be a number"
+const CLIENT_MSG_POST_CONTENT_CANNOT_BE_EMPTY = "Please provide content for the post."
+const CLIENT_MSG_NOT_POST_OWNER = "You can only change your own posts."
+const CLIENT_MSG_POST_EDIT_WINDOW_OVER = "This post can no longer be edited."
+const CLIENT_MSG_ERROR_EDIT_POST = "Something went wrong while editing the post. Please try again."
+const CLIENT_MSG_ERROR_DELETE_POST = "Something went wrong while deleting the post. Please try again."
+const CLIENT_MSG_ERROR_GET_POST_REVISIONS = "Something went wrong while getting the edit history of the post. Please try again."
+const CLIENT_MSG_TOO_MANY_POST_MEDIA = "A post can have at most 10 images or videos."
+const CLIENT_MSG_UNSUPPORTED_POST_MEDIA = "Only jpeg, png, webp and gif images, and mp4 and webm videos can be attached."
+const CLIENT_MSG_POST_IMAGE_TOO_LARGE = "Images can be at most 20 MB."
+const CLIENT_MSG_POST_GIF_TOO_LARGE = "Animated gifs can be at most 15 MB."
+const CLIENT_MSG_POST_VIDEO_TOO_LARGE = "Videos can be at most 200 MB."
+const CLIENT_MSG_IMAGE_TOO_MANY_PIXELS = "Images can be at most 50 megapixels."
+const CLIENT_MSG_UNSUPPORTED_PROFILE_PICTURE = "The profile picture must be a jpeg, png, webp or gif image."
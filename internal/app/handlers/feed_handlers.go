@@ -0,0 +1,211 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/app"
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/app/ranking"
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/database"
+)
+
+// Home Feed Handler. Posts of the users the authenticated user follows and their own posts, newest first.
+func (cfg *ApiConfig) HomeFeedHandler(writer http.ResponseWriter, request *http.Request) {
+	// Page numbers keep working until every client has moved to cursors
+	if request.URL.Query().Has("page") {
+		cfg.homeFeedByPage(writer, request)
+		return
+	}
+
+	// Get the authenticated user id
+	userId := UserIdFromContext(request.Context())
+
+	cursor, cursorErr := cursorFromRequest(request)
+	if cursorErr != nil {
+		RespondWithError(writer, http.StatusBadRequest, CLIENT_MSG_INVALID_CURSOR)
+		return
+	}
+
+	// Newest first, so the previous page is the newer posts
+	var posts []database.VisiblePost
+	cursorCreatedAt, cursorId := cursorPosition(cursor, pgtype.Infinity)
+	if cursor != nil && cursor.Prev {
+		params := database.GetHomeFeedAfterParams{
+			ViewerID:        userId,
+			CursorCreatedAt: cursorCreatedAt,
+			CursorID:        cursorId,
+			PageLimit:       app.PAGE_SIZE + 1,
+		}
+		newerPosts, getHomeFeedErr := cfg.Db.GetHomeFeedAfter(request.Context(), params)
+		if getHomeFeedErr != nil {
+			cfg.LogError(getHomeFeedErr.Error(), getHomeFeedErr)
+			RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_GET_FEED)
+			return
+		}
+		posts = newerPosts
+	} else {
+		params := database.GetHomeFeedBeforeParams{
+			ViewerID:        userId,
+			CursorCreatedAt: cursorCreatedAt,
+			CursorID:        cursorId,
+			PageLimit:       app.PAGE_SIZE + 1,
+		}
+		olderPosts, getHomeFeedErr := cfg.Db.GetHomeFeedBefore(request.Context(), params)
+		if getHomeFeedErr != nil {
+			cfg.LogError(getHomeFeedErr.Error(), getHomeFeedErr)
+			RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_GET_FEED)
+			return
+		}
+		posts = olderPosts
+	}
+
+	cfg.respondWithPostPage(writer, request, "/api/feed/home", posts, cursor)
+}
+
+// Home feed with ?page=
+func (cfg *ApiConfig) homeFeedByPage(writer http.ResponseWriter, request *http.Request) {
+	// Get the authenticated user id
+	userId := UserIdFromContext(request.Context())
+
+	page, pageErr := pageFromRequest(request)
+	if pageErr != nil {
+		RespondWithError(writer, http.StatusBadRequest, CLIENT_MSG_INVALID_PAGE)
+		return
+	}
+
+	// Get one more than the page size to know if there is a next page
+	params := database.GetHomeFeedParams{
+		ViewerID: userId,
+		Offset:   int32((page - 1) * app.PAGE_SIZE),
+		Limit:    app.PAGE_SIZE + 1,
+	}
+	posts, getHomeFeedErr := cfg.Db.GetHomeFeed(request.Context(), params)
+	if getHomeFeedErr != nil {
+		cfg.LogError(getHomeFeedErr.Error(), getHomeFeedErr)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_GET_FEED)
+		return
+	}
+
+	nextPageUrl := ""
+	if len(posts) > app.PAGE_SIZE {
+		posts = posts[:app.PAGE_SIZE]
+		nextPageUrl = fmt.Sprintf("%v/api/feed/home?page=%v", cfg.GetBaseUrl(), page+1)
+	}
+
+	postList, mediaErr := cfg.newPostResponses(request.Context(), posts)
+	if mediaErr != nil {
+		cfg.LogError(mediaErr.Error(), mediaErr)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_GET_FEED)
+		return
+	}
+
+	response := PostListResponse{
+		Data: postList,
+		Meta: MetaResponse{
+			CurrentPage: page,
+			NextPageUrl: nextPageUrl,
+		},
+	}
+
+	RespondWithJson(writer, http.StatusOK, response)
+}
+
+// For You Feed Handler. Recent posts ranked by the interests of the authenticated user, engagement and age.
+// The ranking is worked out on every request, so a post can move to another page as it gets likes and ages.
+func (cfg *ApiConfig) ForYouFeedHandler(writer http.ResponseWriter, request *http.Request) {
+	// Get the authenticated user id
+	userId := UserIdFromContext(request.Context())
+
+	page, pageErr := pageFromRequest(request)
+	if pageErr != nil {
+		RespondWithError(writer, http.StatusBadRequest, CLIENT_MSG_INVALID_PAGE)
+		return
+	}
+	now := cfg.now()
+
+	params := database.GetForYouCandidatesParams{
+		ViewerID: userId,
+		Since: pgtype.Timestamp{
+			Time:  now.Add(-app.FOR_YOU_CANDIDATE_WINDOW),
+			Valid: true,
+		},
+		CandidateLimit: app.FOR_YOU_CANDIDATE_LIMIT,
+	}
+	posts, getCandidatesErr := cfg.Db.GetForYouCandidates(request.Context(), params)
+	if getCandidatesErr != nil {
+		cfg.LogError(getCandidatesErr.Error(), getCandidatesErr)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_GET_FEED)
+		return
+	}
+
+	postIds := []int64{}
+	postsById := map[int64]database.VisiblePost{}
+	for _, post := range posts {
+		postIds = append(postIds, post.ID)
+		postsById[post.ID] = post
+	}
+
+	postInterests, postInterestsErr := cfg.Db.GetInterestIdsForPosts(request.Context(), postIds)
+	if postInterestsErr != nil {
+		cfg.LogError(postInterestsErr.Error(), postInterestsErr)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_GET_FEED)
+		return
+	}
+	interestIdsByPost := map[int64][]int64{}
+	for _, postInterest := range postInterests {
+		interestIdsByPost[postInterest.PostID] = append(interestIdsByPost[postInterest.PostID], postInterest.InterestID)
+	}
+
+	userInterests, userInterestsErr := cfg.Db.GetInterestsForUser(request.Context(), userId)
+	if userInterestsErr != nil {
+		cfg.LogError(userInterestsErr.Error(), userInterestsErr)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_GET_FEED)
+		return
+	}
+	userInterestIds := []int64{}
+	for _, interest := range userInterests {
+		userInterestIds = append(userInterestIds, interest.ID)
+	}
+
+	candidates := []ranking.Candidate{}
+	for _, post := range posts {
+		candidates = append(candidates, ranking.Candidate{
+			ID:           post.ID,
+			CreatedAt:    post.CreatedAt.Time,
+			LikeCount:    post.LikeCount,
+			CommentCount: post.CommentCount,
+			InterestIDs:  interestIdsByPost[post.ID],
+		})
+	}
+	ranked := ranking.Rank(candidates, userInterestIds, now, cfg.feedWeights())
+
+	start := min((page-1)*app.PAGE_SIZE, len(ranked))
+	end := min(start+app.PAGE_SIZE, len(ranked))
+
+	pagePosts := []database.VisiblePost{}
+	for _, candidate := range ranked[start:end] {
+		pagePosts = append(pagePosts, postsById[candidate.ID])
+	}
+	postList, mediaErr := cfg.newPostResponses(request.Context(), pagePosts)
+	if mediaErr != nil {
+		cfg.LogError(mediaErr.Error(), mediaErr)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_GET_FEED)
+		return
+	}
+
+	nextPageUrl := ""
+	if end < len(ranked) {
+		nextPageUrl = fmt.Sprintf("%v/api/feed/for-you?page=%v", cfg.GetBaseUrl(), page+1)
+	}
+
+	response := PostListResponse{
+		Data: postList,
+		Meta: MetaResponse{
+			CurrentPage: page,
+			NextPageUrl: nextPageUrl,
+		},
+	}
+
+	RespondWithJson(writer, http.StatusOK, response)
+}
@@ -1,57 +1,43 @@
 package handlers
 
 import (
+	"bytes"
+	"context"
 	"crypto/rand"
 	"encoding/hex"
-	"errors"
 	"fmt"
 	"io"
-	"mime"
-	"net/http"
-	"os"
-	"path/filepath"
-	"strings"
+	"mime/multipart"
 
-	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/app/media"
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/app/storage"
 )
 
-func UploadFileToAWS(inputParamName string, fileType string, request *http.Request, s3Client *s3.Client, s3Bucket string, s3Region string) (string, error) {
-	file, header, fileErr := request.FormFile(inputParamName)
-	if fileErr != nil {
-		return "", fileErr
-	}
-	defer file.Close()
-
-	// Check if the uploaded file has the correct mime type
-	mediaType, _, mimeErr := mime.ParseMediaType(header.Header.Get("Content-Type"))
-	if mimeErr != nil {
-		return "", mimeErr
-	}
-
-	if !strings.HasPrefix(mediaType, fileType) {
-		return "", errors.New("wrong file type")
-	}
-
-	// Get the original file extension
-	originalExtension := filepath.Ext(header.Filename)
-	if originalExtension == "" {
-		return "", errors.New("the original file has no extension")
+// Upload one file of a multipart form under the prefix folder. Used when a form field has several files.
+// contentType must be sniffed from the file, the content type and name the client sent are not trusted.
+// The extension comes from contentType.
+func UploadFileHeader(ctx context.Context, header *multipart.FileHeader, prefix string, contentType string, fileStorage storage.Storage) (string, error) {
+	extension := media.Extension(contentType)
+	if extension == "" {
+		return "", media.ErrUnsupportedFormat
 	}
 
-	tmpFile, tmpErr := os.CreateTemp("", "sanctuary-upload-*"+originalExtension)
-	if tmpErr != nil {
-		return "", tmpErr
+	file, openErr := header.Open()
+	if openErr != nil {
+		return "", openErr
 	}
-	defer os.Remove(tmpFile.Name())
-	defer tmpFile.Close()
+	defer file.Close()
 
-	// Copy the contents to tmp file
-	io.Copy(tmpFile, file)
+	return putObject(ctx, file, prefix, extension, contentType, fileStorage)
+}
 
-	// Reset temp file's pointer to beginning
-	tmpFile.Seek(0, io.SeekStart)
+// Upload a file made by the server, like the poster of a gif, under the prefix folder
+func UploadBytes(ctx context.Context, data []byte, prefix string, extension string, contentType string, fileStorage storage.Storage) (string, error) {
+	return putObject(ctx, bytes.NewReader(data), prefix, extension, contentType, fileStorage)
+}
 
+// Upload body with a random name and get its download url
+func putObject(ctx context.Context, body io.Reader, prefix string, extension string, contentType string, fileStorage storage.Storage) (string, error) {
 	// Random 32 bytes for image name
 	randomBytes := make([]byte, 32)
 	_, randomBytesErr := rand.Read(randomBytes)
@@ -60,29 +46,13 @@ func UploadFileToAWS(inputParamName string, fileType string, request *http.Reque
 	}
 	random32BytesString := hex.EncodeToString(randomBytes)
 
-	// Create file key
-	prefix := ""
-	if inputParamName == "profile" {
-		prefix = "profiles"
-	} else {
-		prefix = "images"
-	}
-	fileKey := fmt.Sprintf("%v/%v%v", prefix, random32BytesString, originalExtension)
+	fileKey := fmt.Sprintf("%v/%v%v", prefix, random32BytesString, extension)
 
 	// Upload the file
-	_, putObjErr := s3Client.PutObject(request.Context(), &s3.PutObjectInput{
-		Bucket:      aws.String(s3Bucket),
-		Key:         aws.String(fileKey),
-		Body:        tmpFile,
-		ContentType: aws.String(mediaType),
-	})
-
-	if putObjErr != nil {
-		return "", putObjErr
+	if putErr := fileStorage.Put(ctx, fileKey, body, contentType); putErr != nil {
+		return "", putErr
 	}
 
 	// Get the download url
-	downloadUrl := fmt.Sprintf("https://%v.s3.%v.amazonaws.com/%v", s3Bucket, s3Region, fileKey)
-
-	return downloadUrl, nil
+	return fileStorage.URL(fileKey), nil
 }
@@ -0,0 +1,457 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/app"
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/database"
+)
+
+// User List Response
+type UserListResponse struct {
+	Data []publicUserResponse `json:"data"`
+	Meta MetaResponse         `json:"meta"`
+}
+
+// Follow User
+func (cfg *ApiConfig) FollowUserHandler(writer http.ResponseWriter, request *http.Request) {
+	userId := UserIdFromContext(request.Context())
+
+	followee, ok := cfg.getActiveUserFromPath(writer, request, CLIENT_MSG_ERROR_FOLLOW)
+	if !ok {
+		return
+	}
+	if followee.ID == userId {
+		RespondWithError(writer, http.StatusBadRequest, CLIENT_MSG_CANNOT_FOLLOW_YOURSELF)
+		return
+	}
+
+	// No follows between users when either one blocked the other
+	blocked, ok := cfg.isBlockedBetween(writer, request, followee.ID, CLIENT_MSG_ERROR_FOLLOW)
+	if !ok {
+		return
+	}
+	if blocked {
+		RespondWithError(writer, http.StatusForbidden, CLIENT_MSG_BLOCKED)
+		return
+	}
+
+	// Private accounts approve their followers first
+	if followee.IsPrivate {
+		cfg.requestToFollow(writer, request, followee)
+		return
+	}
+
+	// Following someone you already follow does nothing
+	params := database.CreateFollowParams{
+		FollowerID: userId,
+		FolloweeID: followee.ID,
+	}
+	if _, err := cfg.Db.CreateFollow(request.Context(), params); err != nil {
+		cfg.LogError(err.Error(), err)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_FOLLOW)
+		return
+	}
+
+	writer.WriteHeader(http.StatusOK)
+}
+
+// Send a follow request to a private account. Responds 202 until the request is approved.
+func (cfg *ApiConfig) requestToFollow(writer http.ResponseWriter, request *http.Request, followee database.User) {
+	userId := UserIdFromContext(request.Context())
+
+	followStatus, followStatusErr := cfg.Db.GetFollowStatus(request.Context(), database.GetFollowStatusParams{
+		FollowerID: userId,
+		FolloweeID: followee.ID,
+	})
+	if followStatusErr != nil {
+		cfg.LogError(followStatusErr.Error(), followStatusErr)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_FOLLOW)
+		return
+	}
+	if followStatus.IsFollowing {
+		writer.WriteHeader(http.StatusOK)
+		return
+	}
+
+	// Requesting again while a request is pending does nothing
+	params := database.CreateFollowRequestParams{
+		RequesterID: userId,
+		TargetID:    followee.ID,
+	}
+	if _, err := cfg.Db.CreateFollowRequest(request.Context(), params); err != nil {
+		cfg.LogError(err.Error(), err)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_FOLLOW)
+		return
+	}
+
+	RespondWithJson(writer, http.StatusAccepted, messageResponse{Message: "Your follow request has been sent."})
+}
+
+// Unfollow User
+func (cfg *ApiConfig) UnfollowUserHandler(writer http.ResponseWriter, request *http.Request) {
+	userId := UserIdFromContext(request.Context())
+
+	followeeId, parseErr := userIdFromPath(request)
+	if parseErr != nil {
+		RespondWithError(writer, http.StatusBadRequest, CLIENT_MSG_INVALID_USER_ID)
+		return
+	}
+
+	// Unfollowing is allowed even if the user is no longer active
+	params := database.DeleteFollowParams{
+		FollowerID: userId,
+		FolloweeID: followeeId,
+	}
+	if _, err := cfg.Db.DeleteFollow(request.Context(), params); err != nil {
+		cfg.LogError(err.Error(), err)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_UNFOLLOW)
+		return
+	}
+
+	writer.WriteHeader(http.StatusOK)
+}
+
+// Get Followers of a user
+func (cfg *ApiConfig) GetFollowersHandler(writer http.ResponseWriter, request *http.Request) {
+	// Page numbers keep working until every client has moved to cursors
+	if request.URL.Query().Has("page") {
+		cfg.getFollowersByPage(writer, request)
+		return
+	}
+
+	user, ok := cfg.getFollowListUserFromPath(writer, request)
+	if !ok {
+		return
+	}
+
+	cursor, cursorErr := cursorFromRequest(request)
+	if cursorErr != nil {
+		RespondWithError(writer, http.StatusBadRequest, CLIENT_MSG_INVALID_CURSOR)
+		return
+	}
+
+	// Newest follows first, so the previous page is the newer follows
+	follows := []database.GetFollowersBeforeRow{}
+	cursorCreatedAt, cursorId := cursorPosition(cursor, pgtype.Infinity)
+	if cursor != nil && cursor.Prev {
+		params := database.GetFollowersAfterParams{
+			ViewerID:        UserIdFromContext(request.Context()),
+			UserID:          user.ID,
+			CursorCreatedAt: cursorCreatedAt,
+			CursorID:        cursorId,
+			PageLimit:       app.PAGE_SIZE + 1,
+		}
+		newerFollows, err := cfg.Db.GetFollowersAfter(request.Context(), params)
+		if err != nil {
+			cfg.LogError(err.Error(), err)
+			RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_GET_FOLLOWS)
+			return
+		}
+		for _, follow := range newerFollows {
+			follows = append(follows, database.GetFollowersBeforeRow(follow))
+		}
+	} else {
+		params := database.GetFollowersBeforeParams{
+			ViewerID:        UserIdFromContext(request.Context()),
+			UserID:          user.ID,
+			CursorCreatedAt: cursorCreatedAt,
+			CursorID:        cursorId,
+			PageLimit:       app.PAGE_SIZE + 1,
+		}
+		olderFollows, err := cfg.Db.GetFollowersBefore(request.Context(), params)
+		if err != nil {
+			cfg.LogError(err.Error(), err)
+			RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_GET_FOLLOWS)
+			return
+		}
+		for _, follow := range olderFollows {
+			follows = append(follows, database.GetFollowersBeforeRow(follow))
+		}
+	}
+
+	cfg.respondWithFollowPage(writer, fmt.Sprintf("/api/users/%v/followers", user.ID), follows, cursor)
+}
+
+// Get Followers of a user with ?page=
+func (cfg *ApiConfig) getFollowersByPage(writer http.ResponseWriter, request *http.Request) {
+	user, ok := cfg.getFollowListUserFromPath(writer, request)
+	if !ok {
+		return
+	}
+
+	page, pageErr := pageFromRequest(request)
+	if pageErr != nil {
+		RespondWithError(writer, http.StatusBadRequest, CLIENT_MSG_INVALID_PAGE)
+		return
+	}
+
+	// Get one more than the page size to know if there is a next page
+	params := database.GetFollowersParams{
+		UserID:     user.ID,
+		ViewerID:   UserIdFromContext(request.Context()),
+		PageLimit:  app.PAGE_SIZE + 1,
+		PageOffset: int32((page - 1) * app.PAGE_SIZE),
+	}
+	followers, err := cfg.Db.GetFollowers(request.Context(), params)
+	if err != nil {
+		cfg.LogError(err.Error(), err)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_GET_FOLLOWS)
+		return
+	}
+
+	users := []publicUserResponse{}
+	for _, follower := range followers {
+		users = append(users, newPublicUserResponseFromColumns(
+			follower.ID,
+			follower.UserName,
+			follower.FullName,
+			follower.CreatedAt,
+			follower.ProfileImageUrl,
+			follower.ProfileImageFeedUrl,
+			follower.ProfileImageThumbnailUrl,
+		).withFollowStatus(follower.IsFollowing, follower.FollowsYou))
+	}
+
+	RespondWithJson(writer, http.StatusOK, cfg.userListResponse(users, page, fmt.Sprintf("/api/users/%v/followers", user.ID)))
+}
+
+// Get the users a user follows
+func (cfg *ApiConfig) GetFollowingHandler(writer http.ResponseWriter, request *http.Request) {
+	// Page numbers keep working until every client has moved to cursors
+	if request.URL.Query().Has("page") {
+		cfg.getFollowingByPage(writer, request)
+		return
+	}
+
+	user, ok := cfg.getFollowListUserFromPath(writer, request)
+	if !ok {
+		return
+	}
+
+	cursor, cursorErr := cursorFromRequest(request)
+	if cursorErr != nil {
+		RespondWithError(writer, http.StatusBadRequest, CLIENT_MSG_INVALID_CURSOR)
+		return
+	}
+
+	// Newest follows first, so the previous page is the newer follows
+	follows := []database.GetFollowersBeforeRow{}
+	cursorCreatedAt, cursorId := cursorPosition(cursor, pgtype.Infinity)
+	if cursor != nil && cursor.Prev {
+		params := database.GetFollowingAfterParams{
+			ViewerID:        UserIdFromContext(request.Context()),
+			UserID:          user.ID,
+			CursorCreatedAt: cursorCreatedAt,
+			CursorID:        cursorId,
+			PageLimit:       app.PAGE_SIZE + 1,
+		}
+		newerFollows, err := cfg.Db.GetFollowingAfter(request.Context(), params)
+		if err != nil {
+			cfg.LogError(err.Error(), err)
+			RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_GET_FOLLOWS)
+			return
+		}
+		for _, follow := range newerFollows {
+			follows = append(follows, database.GetFollowersBeforeRow(follow))
+		}
+	} else {
+		params := database.GetFollowingBeforeParams{
+			ViewerID:        UserIdFromContext(request.Context()),
+			UserID:          user.ID,
+			CursorCreatedAt: cursorCreatedAt,
+			CursorID:        cursorId,
+			PageLimit:       app.PAGE_SIZE + 1,
+		}
+		olderFollows, err := cfg.Db.GetFollowingBefore(request.Context(), params)
+		if err != nil {
+			cfg.LogError(err.Error(), err)
+			RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_GET_FOLLOWS)
+			return
+		}
+		for _, follow := range olderFollows {
+			follows = append(follows, database.GetFollowersBeforeRow(follow))
+		}
+	}
+
+	cfg.respondWithFollowPage(writer, fmt.Sprintf("/api/users/%v/following", user.ID), follows, cursor)
+}
+
+// Get the users a user follows with ?page=
+func (cfg *ApiConfig) getFollowingByPage(writer http.ResponseWriter, request *http.Request) {
+	user, ok := cfg.getFollowListUserFromPath(writer, request)
+	if !ok {
+		return
+	}
+
+	page, pageErr := pageFromRequest(request)
+	if pageErr != nil {
+		RespondWithError(writer, http.StatusBadRequest, CLIENT_MSG_INVALID_PAGE)
+		return
+	}
+
+	// Get one more than the page size to know if there is a next page
+	params := database.GetFollowingParams{
+		UserID:     user.ID,
+		ViewerID:   UserIdFromContext(request.Context()),
+		PageLimit:  app.PAGE_SIZE + 1,
+		PageOffset: int32((page - 1) * app.PAGE_SIZE),
+	}
+	following, err := cfg.Db.GetFollowing(request.Context(), params)
+	if err != nil {
+		cfg.LogError(err.Error(), err)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_GET_FOLLOWS)
+		return
+	}
+
+	users := []publicUserResponse{}
+	for _, followee := range following {
+		users = append(users, newPublicUserResponseFromColumns(
+			followee.ID,
+			followee.UserName,
+			followee.FullName,
+			followee.CreatedAt,
+			followee.ProfileImageUrl,
+			followee.ProfileImageFeedUrl,
+			followee.ProfileImageThumbnailUrl,
+		).withFollowStatus(followee.IsFollowing, followee.FollowsYou))
+	}
+
+	RespondWithJson(writer, http.StatusOK, cfg.userListResponse(users, page, fmt.Sprintf("/api/users/%v/following", user.ID)))
+}
+
+// Get the active user from the user_id path value. Responds with an error and returns false if there is none.
+func (cfg *ApiConfig) getActiveUserFromPath(writer http.ResponseWriter, request *http.Request, errorMsg string) (database.User, bool) {
+	userId, parseErr := userIdFromPath(request)
+	if parseErr != nil {
+		RespondWithError(writer, http.StatusBadRequest, CLIENT_MSG_INVALID_USER_ID)
+		return database.User{}, false
+	}
+
+	user, getUserErr := cfg.Db.GetUserById(request.Context(), userId)
+	if getUserErr != nil {
+		if errors.Is(getUserErr, sql.ErrNoRows) {
+			RespondWithError(writer, http.StatusNotFound, CLIENT_MSG_USER_NOT_FOUND)
+			return database.User{}, false
+		}
+		cfg.LogError(getUserErr.Error(), getUserErr)
+		RespondWithError(writer, http.StatusInternalServerError, errorMsg)
+		return database.User{}, false
+	}
+
+	return user, true
+}
+
+// Get the active user from the user_id path value like getActiveUserFromPath.
+// A user who blocked the authenticated user, or was blocked by them, is not found either.
+func (cfg *ApiConfig) getUnblockedUserFromPath(writer http.ResponseWriter, request *http.Request, errorMsg string) (database.User, bool) {
+	user, ok := cfg.getActiveUserFromPath(writer, request, errorMsg)
+	if !ok {
+		return database.User{}, false
+	}
+
+	blocked, ok := cfg.isBlockedBetween(writer, request, user.ID, errorMsg)
+	if !ok {
+		return database.User{}, false
+	}
+	if blocked {
+		RespondWithError(writer, http.StatusNotFound, CLIENT_MSG_USER_NOT_FOUND)
+		return database.User{}, false
+	}
+
+	return user, true
+}
+
+// Get the user whose followers or following are listed from the user_id path value.
+// Like their posts, the follows of a private account are only shown to themselves and their followers.
+func (cfg *ApiConfig) getFollowListUserFromPath(writer http.ResponseWriter, request *http.Request) (database.User, bool) {
+	user, ok := cfg.getUnblockedUserFromPath(writer, request, CLIENT_MSG_ERROR_GET_FOLLOWS)
+	if !ok {
+		return database.User{}, false
+	}
+
+	userId := UserIdFromContext(request.Context())
+	if !user.IsPrivate || user.ID == userId {
+		return user, true
+	}
+
+	followStatus, err := cfg.Db.GetFollowStatus(request.Context(), database.GetFollowStatusParams{
+		FollowerID: userId,
+		FolloweeID: user.ID,
+	})
+	if err != nil {
+		cfg.LogError(err.Error(), err)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_GET_FOLLOWS)
+		return database.User{}, false
+	}
+	if !followStatus.IsFollowing {
+		RespondWithError(writer, http.StatusForbidden, CLIENT_MSG_PRIVATE_FOLLOWS)
+		return database.User{}, false
+	}
+
+	return user, true
+}
+
+// Whether the authenticated user and the other user blocked each other, in either direction.
+// Responds with an error and returns false if the check fails.
+func (cfg *ApiConfig) isBlockedBetween(writer http.ResponseWriter, request *http.Request, otherUserId int64, errorMsg string) (bool, bool) {
+	blocked, err := cfg.Db.IsBlockedBetween(request.Context(), database.IsBlockedBetweenParams{
+		UserID:      UserIdFromContext(request.Context()),
+		OtherUserID: otherUserId,
+	})
+	if err != nil {
+		cfg.LogError(err.Error(), err)
+		RespondWithError(writer, http.StatusInternalServerError, errorMsg)
+		return false, false
+	}
+
+	return blocked, true
+}
+
+// Respond with a page of follows fetched with cursor. All the follow list queries select the same columns, so their rows convert to GetFollowersBeforeRow.
+func (cfg *ApiConfig) respondWithFollowPage(writer http.ResponseWriter, path string, follows []database.GetFollowersBeforeRow, cursor *Cursor) {
+	page := NewCursorPage(follows, app.PAGE_SIZE, cursor, func(follow database.GetFollowersBeforeRow) Cursor {
+		return Cursor{CreatedAt: follow.FollowedAt.Time, ID: follow.FollowID}
+	})
+
+	users := []publicUserResponse{}
+	for _, follow := range page.Items {
+		users = append(users, newPublicUserResponseFromColumns(
+			follow.ID,
+			follow.UserName,
+			follow.FullName,
+			follow.CreatedAt,
+			follow.ProfileImageUrl,
+			follow.ProfileImageFeedUrl,
+			follow.ProfileImageThumbnailUrl,
+		).withFollowStatus(follow.IsFollowing, follow.FollowsYou))
+	}
+
+	response := UserListResponse{
+		Data: users,
+		Meta: cfg.cursorMetaResponse(path, page.NextCursor, page.PrevCursor),
+	}
+
+	RespondWithJson(writer, http.StatusOK, response)
+}
+
+// Build a page of users. users has one more item than the page size if there is a next page.
+func (cfg *ApiConfig) userListResponse(users []publicUserResponse, page int, path string) UserListResponse {
+	nextPageUrl := ""
+	if len(users) > app.PAGE_SIZE {
+		users = users[:app.PAGE_SIZE]
+		nextPageUrl = fmt.Sprintf("%v%v?page=%v", cfg.GetBaseUrl(), path, page+1)
+	}
+
+	return UserListResponse{
+		Data: users,
+		Meta: MetaResponse{
+			CurrentPage: page,
+			NextPageUrl: nextPageUrl,
+		},
+	}
+}
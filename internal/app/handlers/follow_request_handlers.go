@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/app"
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/database"
+)
+
+type privacyRequest struct {
+	IsPrivate *bool `json:"is_private"`
+}
+
+// Make the authenticated user's account private or public.
+// Going public approves every pending follow request.
+func (cfg *ApiConfig) UpdatePrivacyHandler(writer http.ResponseWriter, request *http.Request) {
+	// Decode the request
+	decoder := json.NewDecoder(request.Body)
+	privacy := privacyRequest{}
+	if decodeErr := decoder.Decode(&privacy); decodeErr != nil {
+		RespondWithError(writer, http.StatusBadRequest, decodeErr.Error())
+		return
+	}
+
+	if privacy.IsPrivate == nil {
+		RespondWithError(writer, http.StatusBadRequest, CLIENT_MSG_IS_PRIVATE_CANNOT_BE_EMPTY)
+		return
+	}
+
+	user := UserFromContext(request.Context())
+	updatedUser, updateErr := cfg.Db.SetUserPrivate(request.Context(), database.SetUserPrivateParams{
+		ID:        user.ID,
+		IsPrivate: *privacy.IsPrivate,
+	})
+	if updateErr != nil {
+		cfg.LogError(SERVER_MSG_UPDATE_USER_ERROR, updateErr)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_UPDATE_PRIVACY)
+		return
+	}
+
+	if !updatedUser.IsPrivate {
+		if approveErr := cfg.Db.ApproveAllFollowRequests(request.Context(), user.ID); approveErr != nil {
+			cfg.LogError(approveErr.Error(), approveErr)
+			RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_UPDATE_PRIVACY)
+			return
+		}
+	}
+
+	cfg.respondWithMe(writer, request, updatedUser, CLIENT_MSG_ERROR_UPDATE_PRIVACY)
+}
+
+// Cancel a follow request the authenticated user sent
+func (cfg *ApiConfig) CancelFollowRequestHandler(writer http.ResponseWriter, request *http.Request) {
+	userId := UserIdFromContext(request.Context())
+
+	targetId, parseErr := userIdFromPath(request)
+	if parseErr != nil {
+		RespondWithError(writer, http.StatusBadRequest, CLIENT_MSG_INVALID_USER_ID)
+		return
+	}
+
+	// Cancelling a request that was already answered does nothing
+	params := database.DeleteFollowRequestParams{
+		RequesterID: userId,
+		TargetID:    targetId,
+	}
+	if _, err := cfg.Db.DeleteFollowRequest(request.Context(), params); err != nil {
+		cfg.LogError(err.Error(), err)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_CANCEL_FOLLOW_REQUEST)
+		return
+	}
+
+	writer.WriteHeader(http.StatusOK)
+}
+
+// Approve a follow request sent to the authenticated user
+func (cfg *ApiConfig) ApproveFollowRequestHandler(writer http.ResponseWriter, request *http.Request) {
+	userId := UserIdFromContext(request.Context())
+
+	requesterId, parseErr := userIdFromPath(request)
+	if parseErr != nil {
+		RespondWithError(writer, http.StatusBadRequest, CLIENT_MSG_INVALID_USER_ID)
+		return
+	}
+
+	// Moves the request into follows
+	params := database.ApproveFollowRequestParams{
+		RequesterID: requesterId,
+		TargetID:    userId,
+	}
+	approved, err := cfg.Db.ApproveFollowRequest(request.Context(), params)
+	if err != nil {
+		cfg.LogError(err.Error(), err)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_APPROVE_FOLLOW_REQUEST)
+		return
+	}
+	if approved == 0 {
+		RespondWithError(writer, http.StatusNotFound, CLIENT_MSG_FOLLOW_REQUEST_NOT_FOUND)
+		return
+	}
+
+	writer.WriteHeader(http.StatusOK)
+}
+
+// Deny a follow request sent to the authenticated user
+func (cfg *ApiConfig) DenyFollowRequestHandler(writer http.ResponseWriter, request *http.Request) {
+	userId := UserIdFromContext(request.Context())
+
+	requesterId, parseErr := userIdFromPath(request)
+	if parseErr != nil {
+		RespondWithError(writer, http.StatusBadRequest, CLIENT_MSG_INVALID_USER_ID)
+		return
+	}
+
+	params := database.DeleteFollowRequestParams{
+		RequesterID: requesterId,
+		TargetID:    userId,
+	}
+	denied, err := cfg.Db.DeleteFollowRequest(request.Context(), params)
+	if err != nil {
+		cfg.LogError(err.Error(), err)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_DENY_FOLLOW_REQUEST)
+		return
+	}
+	if denied == 0 {
+		RespondWithError(writer, http.StatusNotFound, CLIENT_MSG_FOLLOW_REQUEST_NOT_FOUND)
+		return
+	}
+
+	writer.WriteHeader(http.StatusOK)
+}
+
+// Get the pending follow requests sent to the authenticated user, most recent first
+func (cfg *ApiConfig) GetFollowRequestsHandler(writer http.ResponseWriter, request *http.Request) {
+	page, pageErr := pageFromRequest(request)
+	if pageErr != nil {
+		RespondWithError(writer, http.StatusBadRequest, CLIENT_MSG_INVALID_PAGE)
+		return
+	}
+
+	// Get one more than the page size to know if there is a next page
+	params := database.GetFollowRequestsParams{
+		UserID:     UserIdFromContext(request.Context()),
+		PageLimit:  app.PAGE_SIZE + 1,
+		PageOffset: int32((page - 1) * app.PAGE_SIZE),
+	}
+	requesters, err := cfg.Db.GetFollowRequests(request.Context(), params)
+	if err != nil {
+		cfg.LogError(err.Error(), err)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_GET_FOLLOW_REQUESTS)
+		return
+	}
+
+	users := []publicUserResponse{}
+	for _, requester := range requesters {
+		users = append(users, newPublicUserResponseFromColumns(
+			requester.ID,
+			requester.UserName,
+			requester.FullName,
+			requester.CreatedAt,
+			requester.ProfileImageUrl,
+			requester.ProfileImageFeedUrl,
+			requester.ProfileImageThumbnailUrl,
+		))
+	}
+
+	RespondWithJson(writer, http.StatusOK, cfg.userListResponse(users, page, "/api/me/follow-requests"))
+}
@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"mime/multipart"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/app/imaging"
+)
+
+// Urls of the renditions of a processed image uploaded to storage.
+// The feed and thumbnail urls are empty when the image is not bigger than them, the next bigger one is used instead.
+type uploadedImage struct {
+	fullUrl      string
+	feedUrl      string
+	thumbnailUrl string
+	width        int
+	height       int
+}
+
+// Urls of the renditions of an image as clients get them. Every rendition has a url.
+type imageVariantsResponse struct {
+	Thumbnail string `json:"thumbnail"`
+	Feed      string `json:"feed"`
+	Full      string `json:"full"`
+}
+
+// Renditions that were not made, including all of them for images uploaded before there were renditions,
+// use the next bigger one. Nil when there is no image.
+func newImageVariantsResponse(fullUrl string, feedUrl pgtype.Text, thumbnailUrl pgtype.Text) *imageVariantsResponse {
+	if fullUrl == "" {
+		return nil
+	}
+
+	variants := imageVariantsResponse{
+		Thumbnail: thumbnailUrl.String,
+		Feed:      feedUrl.String,
+		Full:      fullUrl,
+	}
+	if variants.Feed == "" {
+		variants.Feed = variants.Full
+	}
+	if variants.Thumbnail == "" {
+		variants.Thumbnail = variants.Feed
+	}
+	return &variants
+}
+
+// Process an uploaded image and upload its renditions under the prefix folder.
+// Errors of imaging.Process are returned as they are, so the caller can tell the client what is wrong with the image.
+// If an upload fails, the renditions that were uploaded are deleted again.
+func (cfg *ApiConfig) uploadProcessedImage(ctx context.Context, fileHeader *multipart.FileHeader, prefix string) (uploadedImage, error) {
+	file, openErr := fileHeader.Open()
+	if openErr != nil {
+		return uploadedImage{}, openErr
+	}
+	defer file.Close()
+
+	data, readErr := io.ReadAll(file)
+	if readErr != nil {
+		return uploadedImage{}, readErr
+	}
+
+	variants, processErr := imaging.Process(data)
+	if processErr != nil {
+		return uploadedImage{}, processErr
+	}
+
+	// The full rendition comes first
+	image := uploadedImage{
+		width:  variants[0].Width,
+		height: variants[0].Height,
+	}
+	for _, variant := range variants {
+		downloadUrl, uploadErr := UploadBytes(
+			ctx,
+			variant.Data,
+			prefix,
+			variant.Extension,
+			variant.ContentType,
+			cfg.Storage,
+		)
+		if uploadErr != nil {
+			cfg.deleteUploadedImage(ctx, image)
+			return uploadedImage{}, uploadErr
+		}
+
+		switch variant.Rendition {
+		case imaging.RenditionFull:
+			image.fullUrl = downloadUrl
+		case imaging.RenditionFeed:
+			image.feedUrl = downloadUrl
+		case imaging.RenditionThumbnail:
+			image.thumbnailUrl = downloadUrl
+		}
+	}
+
+	return image, nil
+}
+
+// Delete the renditions of an image that will not be used. Failures are only logged.
+func (cfg *ApiConfig) deleteUploadedImage(ctx context.Context, image uploadedImage) {
+	// The request may already be cancelled, which is often why the files are deleted
+	ctx = context.WithoutCancel(ctx)
+	for _, url := range []string{image.fullUrl, image.feedUrl, image.thumbnailUrl} {
+		if url == "" {
+			continue
+		}
+		if deleteErr := cfg.deleteUploadedFile(ctx, url); deleteErr != nil {
+			cfg.LogError(deleteErr.Error(), deleteErr)
+		}
+	}
+}
+
+// A url to save in the db, null if it is empty
+func optionalUrlText(url string) pgtype.Text {
+	return pgtype.Text{String: url, Valid: url != ""}
+}
@@ -1,8 +1,17 @@
 package handlers
 
 import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
 	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/app/validators"
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/database"
 )
 
 type interestResponse struct {
@@ -12,6 +21,30 @@ type interestResponse struct {
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+type interestIdsRequest struct {
+	Ids []int64 `json:"ids"`
+}
+
+type interestNameRequest struct {
+	Name string `json:"name"`
+}
+
+type mergeInterestRequest struct {
+	IntoId int64 `json:"into_id"`
+}
+
+// The interest to merge was deleted or merged in the meantime
+var errInterestNotFound = errors.New("interest not found")
+
+func newInterestResponse(interest database.Interest) interestResponse {
+	return interestResponse{
+		ID:        interest.ID,
+		Name:      interest.Name,
+		CreatedAt: interest.CreatedAt.Time,
+		UpdatedAt: interest.UpdatedAt.Time,
+	}
+}
+
 // Get All Interests
 func (cfg *ApiConfig) GetAllInterests(writer http.ResponseWriter, request *http.Request) {
 	interests, err := cfg.Db.GetAllInterests(request.Context())
@@ -34,3 +67,259 @@ func (cfg *ApiConfig) GetAllInterests(writer http.ResponseWriter, request *http.
 
 	RespondWithJson(writer, http.StatusOK, response)
 }
+
+// Replace all the interests of the authenticated user. An empty list removes every interest.
+func (cfg *ApiConfig) ReplaceMyInterestsHandler(writer http.ResponseWriter, request *http.Request) {
+	decoder := json.NewDecoder(request.Body)
+	interestsRequest := interestIdsRequest{}
+	if decodeErr := decoder.Decode(&interestsRequest); decodeErr != nil {
+		RespondWithError(writer, http.StatusBadRequest, decodeErr.Error())
+		return
+	}
+
+	if interestsRequest.Ids == nil {
+		RespondWithError(writer, http.StatusBadRequest, CLIENT_MSG_INTEREST_IDS_CANNOT_BE_EMPTY)
+		return
+	}
+
+	interestIds, errCode, validationErr := validators.ValidateInterestIds(request.Context(), interestsRequest.Ids, cfg.Db)
+	if validationErr != nil {
+		RespondWithError(writer, errCode, validationErr.Error())
+		return
+	}
+
+	user := UserFromContext(request.Context())
+	now := pgtype.Timestamp{Time: time.Now(), Valid: true}
+
+	// The old interests are only removed if the new ones are saved
+	txErr := cfg.inTx(request.Context(), func(qtx *database.Queries) error {
+		if err := qtx.DeleteInterestsForUser(request.Context(), user.ID); err != nil {
+			return err
+		}
+		if len(interestIds) == 0 {
+			return nil
+		}
+
+		params := []database.CreateUsersHasInterestsParams{}
+		for _, interestId := range interestIds {
+			params = append(params, database.CreateUsersHasInterestsParams{
+				UserID:     user.ID,
+				InterestID: interestId,
+				CreatedAt:  now,
+				UpdatedAt:  now,
+			})
+		}
+		_, err := qtx.CreateUsersHasInterests(request.Context(), params)
+		return err
+	})
+	if txErr != nil {
+		cfg.LogError(SERVER_MSG_CREATE_USER_HAS_INTEREST_ERROR, txErr)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_UPDATE_INTERESTS)
+		return
+	}
+
+	cfg.respondWithMe(writer, request, user, CLIENT_MSG_ERROR_UPDATE_INTERESTS)
+}
+
+// Remove one interest from the authenticated user
+func (cfg *ApiConfig) RemoveMyInterestHandler(writer http.ResponseWriter, request *http.Request) {
+	interestId, parseErr := interestIdFromPath(request)
+	if parseErr != nil {
+		RespondWithError(writer, http.StatusBadRequest, CLIENT_MSG_INVALID_INTEREST_ID)
+		return
+	}
+
+	params := database.DeleteInterestForUserParams{
+		UserID:     UserIdFromContext(request.Context()),
+		InterestID: interestId,
+	}
+	removed, err := cfg.Db.DeleteInterestForUser(request.Context(), params)
+	if err != nil {
+		cfg.LogError(err.Error(), err)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_UPDATE_INTERESTS)
+		return
+	}
+	if removed == 0 {
+		RespondWithError(writer, http.StatusNotFound, CLIENT_MSG_INTEREST_NOT_FOUND)
+		return
+	}
+
+	writer.WriteHeader(http.StatusOK)
+}
+
+// Create an interest. Admin only.
+func (cfg *ApiConfig) CreateInterestHandler(writer http.ResponseWriter, request *http.Request) {
+	name, ok := decodeInterestName(writer, request)
+	if !ok {
+		return
+	}
+
+	interest, err := cfg.Db.CreateInterest(request.Context(), name)
+	if err != nil {
+		if isUniqueViolation(err) {
+			RespondWithError(writer, http.StatusConflict, CLIENT_MSG_INTEREST_ALREADY_EXISTS)
+			return
+		}
+		cfg.LogError(err.Error(), err)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_CREATE_INTEREST)
+		return
+	}
+
+	RespondWithJson(writer, http.StatusCreated, newInterestResponse(interest))
+}
+
+// Rename an interest. Admin only.
+func (cfg *ApiConfig) RenameInterestHandler(writer http.ResponseWriter, request *http.Request) {
+	interestId, parseErr := interestIdFromPath(request)
+	if parseErr != nil {
+		RespondWithError(writer, http.StatusBadRequest, CLIENT_MSG_INVALID_INTEREST_ID)
+		return
+	}
+
+	name, ok := decodeInterestName(writer, request)
+	if !ok {
+		return
+	}
+
+	interest, err := cfg.Db.RenameInterest(request.Context(), database.RenameInterestParams{
+		ID:   interestId,
+		Name: name,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			RespondWithError(writer, http.StatusNotFound, CLIENT_MSG_INTEREST_NOT_FOUND)
+			return
+		}
+		if isUniqueViolation(err) {
+			RespondWithError(writer, http.StatusConflict, CLIENT_MSG_INTEREST_ALREADY_EXISTS)
+			return
+		}
+		cfg.LogError(err.Error(), err)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_RENAME_INTEREST)
+		return
+	}
+
+	RespondWithJson(writer, http.StatusOK, newInterestResponse(interest))
+}
+
+// Soft delete an interest. Users and posts keep it, but it is no longer shown. Admin only.
+func (cfg *ApiConfig) DeleteInterestHandler(writer http.ResponseWriter, request *http.Request) {
+	interestId, parseErr := interestIdFromPath(request)
+	if parseErr != nil {
+		RespondWithError(writer, http.StatusBadRequest, CLIENT_MSG_INVALID_INTEREST_ID)
+		return
+	}
+
+	deleted, err := cfg.Db.SoftDeleteInterest(request.Context(), interestId)
+	if err != nil {
+		cfg.LogError(err.Error(), err)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_DELETE_INTEREST)
+		return
+	}
+	if deleted == 0 {
+		RespondWithError(writer, http.StatusNotFound, CLIENT_MSG_INTEREST_NOT_FOUND)
+		return
+	}
+
+	writer.WriteHeader(http.StatusOK)
+}
+
+// Merge an interest into another one. The users and posts of the merged interest move to the other one,
+// then the merged interest is soft deleted. Admin only.
+func (cfg *ApiConfig) MergeInterestHandler(writer http.ResponseWriter, request *http.Request) {
+	fromId, parseErr := interestIdFromPath(request)
+	if parseErr != nil {
+		RespondWithError(writer, http.StatusBadRequest, CLIENT_MSG_INVALID_INTEREST_ID)
+		return
+	}
+
+	decoder := json.NewDecoder(request.Body)
+	mergeRequest := mergeInterestRequest{}
+	if decodeErr := decoder.Decode(&mergeRequest); decodeErr != nil {
+		RespondWithError(writer, http.StatusBadRequest, decodeErr.Error())
+		return
+	}
+	if mergeRequest.IntoId == fromId {
+		RespondWithError(writer, http.StatusBadRequest, CLIENT_MSG_CANNOT_MERGE_INTEREST_INTO_ITSELF)
+		return
+	}
+
+	into, getErr := cfg.Db.GetInterestById(request.Context(), mergeRequest.IntoId)
+	if getErr != nil {
+		if errors.Is(getErr, sql.ErrNoRows) {
+			RespondWithError(writer, http.StatusNotFound, CLIENT_MSG_INTEREST_NOT_FOUND)
+			return
+		}
+		cfg.LogError(getErr.Error(), getErr)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_MERGE_INTEREST)
+		return
+	}
+
+	txErr := cfg.inTx(request.Context(), func(qtx *database.Queries) error {
+		return mergeInterest(request.Context(), qtx, fromId, into.ID)
+	})
+	if txErr != nil {
+		if errors.Is(txErr, errInterestNotFound) {
+			RespondWithError(writer, http.StatusNotFound, CLIENT_MSG_INTEREST_NOT_FOUND)
+			return
+		}
+		cfg.LogError(txErr.Error(), txErr)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_MERGE_INTEREST)
+		return
+	}
+
+	RespondWithJson(writer, http.StatusOK, newInterestResponse(into))
+}
+
+// Move the users and posts of an interest to another one and soft delete it. Must run in a transaction.
+func mergeInterest(ctx context.Context, qtx *database.Queries, fromId int64, intoId int64) error {
+	// Deleting first also locks the interest against a concurrent merge
+	deleted, deleteErr := qtx.SoftDeleteInterest(ctx, fromId)
+	if deleteErr != nil {
+		return deleteErr
+	}
+	if deleted == 0 {
+		return errInterestNotFound
+	}
+
+	if err := qtx.MergeUsersHasInterests(ctx, database.MergeUsersHasInterestsParams{
+		IntoID: intoId,
+		FromID: fromId,
+	}); err != nil {
+		return err
+	}
+	if err := qtx.DeleteUsersHasInterestsForInterest(ctx, fromId); err != nil {
+		return err
+	}
+
+	if err := qtx.MergePostInterests(ctx, database.MergePostInterestsParams{
+		IntoID: intoId,
+		FromID: fromId,
+	}); err != nil {
+		return err
+	}
+	return qtx.DeletePostInterestsForInterest(ctx, fromId)
+}
+
+// Decode and validate the name of a create or rename request
+func decodeInterestName(writer http.ResponseWriter, request *http.Request) (string, bool) {
+	decoder := json.NewDecoder(request.Body)
+	nameRequest := interestNameRequest{}
+	if decodeErr := decoder.Decode(&nameRequest); decodeErr != nil {
+		RespondWithError(writer, http.StatusBadRequest, decodeErr.Error())
+		return "", false
+	}
+
+	name, nameErr := validators.ValidateInterestName(nameRequest.Name)
+	if nameErr != nil {
+		RespondWithError(writer, http.StatusBadRequest, nameErr.Error())
+		return "", false
+	}
+
+	return name, true
+}
+
+// Parse the interest_id path value
+func interestIdFromPath(request *http.Request) (int64, error) {
+	return strconv.ParseInt(request.PathValue("interest_id"), 10, 64)
+}
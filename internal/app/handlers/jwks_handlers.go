@@ -0,0 +1,14 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/app"
+)
+
+// Publish the public keys so that other services can verify Sanctuary tokens on their own.
+func (cfg *ApiConfig) JWKSHandler(writer http.ResponseWriter, request *http.Request) {
+	writer.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%.0f", app.JWKS_CACHE_MAX_AGE.Seconds()))
+	RespondWithJson(writer, http.StatusOK, cfg.Keys.JWKS())
+}
@@ -0,0 +1,194 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/app"
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/database"
+)
+
+const lockoutReasonAccount = "account"
+const lockoutReasonIp = "ip"
+
+// Hash compared against when the email does not exist, so that unknown emails take as long as wrong passwords.
+var dummyPasswordHash = sync.OnceValue(func() string {
+	hash, _ := HashPassword("sanctuary-dummy-password")
+	return hash
+})
+
+// Get the lockout after failedAttempts failed logins.
+// The first freeAttempts failures are free, after that the lockout starts at base and doubles with every failure, up to max.
+func LoginLockoutDuration(failedAttempts, freeAttempts int64, base, max time.Duration) time.Duration {
+	if failedAttempts < freeAttempts {
+		return 0
+	}
+
+	lockout := base
+	for i := freeAttempts; i < failedAttempts; i++ {
+		lockout *= 2
+		if lockout >= max {
+			return max
+		}
+	}
+	return lockout
+}
+
+// Get how long the client has to wait before trying to log in to this email again. Returns 0 if it does not have to wait.
+func (cfg *ApiConfig) loginRetryAfter(ctx context.Context, email, ipAddress string) (time.Duration, error) {
+	now := cfg.now()
+	since := pgtype.Timestamp{
+		Time:  now.Add(-app.LOGIN_ATTEMPT_WINDOW),
+		Valid: true,
+	}
+
+	emailFailures, emailErr := cfg.Db.GetLoginFailuresForEmail(ctx, database.GetLoginFailuresForEmailParams{
+		Email:     email,
+		CreatedAt: since,
+	})
+	if emailErr != nil {
+		return 0, emailErr
+	}
+
+	ipFailures, ipErr := cfg.Db.GetLoginFailuresForIp(ctx, database.GetLoginFailuresForIpParams{
+		IpAddress: ipAddress,
+		CreatedAt: since,
+	})
+	if ipErr != nil {
+		return 0, ipErr
+	}
+
+	emailLockout := LoginLockoutDuration(emailFailures.FailedAttempts, app.LOGIN_FREE_ATTEMPTS_PER_ACCOUNT, app.LOGIN_LOCKOUT_BASE, app.LOGIN_LOCKOUT_MAX)
+	ipLockout := LoginLockoutDuration(ipFailures.FailedAttempts, app.LOGIN_FREE_ATTEMPTS_PER_IP, app.LOGIN_LOCKOUT_BASE, app.LOGIN_LOCKOUT_MAX)
+
+	retryAfter := time.Duration(0)
+	if emailLockout > 0 && emailFailures.LastFailedAt.Valid {
+		retryAfter = max(retryAfter, emailFailures.LastFailedAt.Time.Add(emailLockout).Sub(now))
+	}
+	if ipLockout > 0 && ipFailures.LastFailedAt.Valid {
+		retryAfter = max(retryAfter, ipFailures.LastFailedAt.Time.Add(ipLockout).Sub(now))
+	}
+	return retryAfter, nil
+}
+
+// Store a failed login. Stores a lockout event as well if this failure locks the account or the ip address.
+func (cfg *ApiConfig) recordLoginFailure(ctx context.Context, email, ipAddress string, userId pgtype.Int8) error {
+	now := cfg.now()
+	params := database.CreateLoginAttemptParams{
+		Email:     email,
+		IpAddress: ipAddress,
+		Succeeded: false,
+		CreatedAt: pgtype.Timestamp{
+			Time:  now,
+			Valid: true,
+		},
+	}
+	if err := cfg.Db.CreateLoginAttempt(ctx, params); err != nil {
+		return err
+	}
+
+	since := pgtype.Timestamp{
+		Time:  now.Add(-app.LOGIN_ATTEMPT_WINDOW),
+		Valid: true,
+	}
+
+	emailFailures, emailErr := cfg.Db.GetLoginFailuresForEmail(ctx, database.GetLoginFailuresForEmailParams{
+		Email:     email,
+		CreatedAt: since,
+	})
+	if emailErr != nil {
+		return emailErr
+	}
+	emailLockout := LoginLockoutDuration(emailFailures.FailedAttempts, app.LOGIN_FREE_ATTEMPTS_PER_ACCOUNT, app.LOGIN_LOCKOUT_BASE, app.LOGIN_LOCKOUT_MAX)
+	if emailLockout > 0 {
+		if err := cfg.createLockout(ctx, email, ipAddress, userId, lockoutReasonAccount, emailFailures.FailedAttempts, now.Add(emailLockout)); err != nil {
+			return err
+		}
+	}
+
+	ipFailures, ipErr := cfg.Db.GetLoginFailuresForIp(ctx, database.GetLoginFailuresForIpParams{
+		IpAddress: ipAddress,
+		CreatedAt: since,
+	})
+	if ipErr != nil {
+		return ipErr
+	}
+	ipLockout := LoginLockoutDuration(ipFailures.FailedAttempts, app.LOGIN_FREE_ATTEMPTS_PER_IP, app.LOGIN_LOCKOUT_BASE, app.LOGIN_LOCKOUT_MAX)
+	if ipLockout > 0 {
+		if err := cfg.createLockout(ctx, email, ipAddress, userId, lockoutReasonIp, ipFailures.FailedAttempts, now.Add(ipLockout)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Store a successful login. Failures of the email before this one no longer count.
+func (cfg *ApiConfig) recordLoginSuccess(ctx context.Context, email, ipAddress string) error {
+	now := cfg.now()
+	params := database.CreateLoginAttemptParams{
+		Email:     email,
+		IpAddress: ipAddress,
+		Succeeded: true,
+		CreatedAt: pgtype.Timestamp{
+			Time:  now,
+			Valid: true,
+		},
+	}
+	if err := cfg.Db.CreateLoginAttempt(ctx, params); err != nil {
+		return err
+	}
+
+	// Attempts outside the window are never looked at again
+	return cfg.Db.DeleteOldLoginAttempts(ctx, pgtype.Timestamp{
+		Time:  now.Add(-app.LOGIN_ATTEMPT_WINDOW),
+		Valid: true,
+	})
+}
+
+func (cfg *ApiConfig) createLockout(ctx context.Context, email, ipAddress string, userId pgtype.Int8, reason string, failedAttempts int64, lockedUntil time.Time) error {
+	params := database.CreateAccountLockoutParams{
+		UserID:         userId,
+		Email:          email,
+		IpAddress:      ipAddress,
+		Reason:         reason,
+		FailedAttempts: failedAttempts,
+		LockedUntil: pgtype.Timestamp{
+			Time:  lockedUntil,
+			Valid: true,
+		},
+		CreatedAt: pgtype.Timestamp{
+			Time:  cfg.now(),
+			Valid: true,
+		},
+	}
+	return cfg.Db.CreateAccountLockout(ctx, params)
+}
+
+// Respond with 429 and a Retry-After header in whole seconds
+func respondWithLoginLockout(writer http.ResponseWriter, retryAfter time.Duration) {
+	writer.Header().Set(app.RETRY_AFTER, fmt.Sprintf("%.0f", math.Ceil(retryAfter.Seconds())))
+	RespondWithError(writer, http.StatusTooManyRequests, CLIENT_MSG_TOO_MANY_LOGIN_ATTEMPTS)
+}
+
+// Emails are tracked case insensitively so that changing the case does not reset the counter
+func normalizeLoginEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// Get the ip address of the client.
+// Uses the connection address, headers like X-Forwarded-For can be set by anyone.
+func clientIP(request *http.Request) string {
+	host, _, err := net.SplitHostPort(request.RemoteAddr)
+	if err != nil {
+		return request.RemoteAddr
+	}
+	return host
+}
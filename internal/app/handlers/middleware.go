@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/database"
+)
+
+type contextKey string
+
+const userIdContextKey contextKey = "user_id"
+const userContextKey contextKey = "user"
+const claimsContextKey contextKey = "claims"
+
+// Authenticated wraps a handler that requires a valid access token.
+// The user id and the token claims are put into the request context.
+func (cfg *ApiConfig) Authenticated(next http.HandlerFunc) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		ctx, ok := cfg.authenticate(writer, request)
+		if !ok {
+			return
+		}
+
+		next(writer, request.WithContext(ctx))
+	}
+}
+
+// AuthenticatedWithUser is the same as Authenticated, but also loads the user from db into the request context.
+func (cfg *ApiConfig) AuthenticatedWithUser(next http.HandlerFunc) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		ctx, ok := cfg.authenticate(writer, request)
+		if !ok {
+			return
+		}
+
+		// Get the user from db
+		user, getUserErr := cfg.Db.GetUserById(ctx, UserIdFromContext(ctx))
+		if getUserErr != nil {
+			cfg.LogError(SERVER_MSG_GET_USER_FAILED, getUserErr)
+			RespondWithError(writer, http.StatusUnauthorized, CLIENT_MSG_UNAUTHORIZED)
+			return
+		}
+
+		ctx = context.WithValue(ctx, userContextKey, user)
+		next(writer, request.WithContext(ctx))
+	}
+}
+
+// RequireVerifiedEmail blocks users that have not verified their email yet.
+// Must be wrapped by AuthenticatedWithUser.
+func (cfg *ApiConfig) RequireVerifiedEmail(next http.HandlerFunc) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		user := UserFromContext(request.Context())
+		if !user.EmailVerifiedAt.Valid {
+			RespondWithError(writer, http.StatusForbidden, CLIENT_MSG_EMAIL_NOT_VERIFIED)
+			return
+		}
+
+		next(writer, request)
+	}
+}
+
+// RequireAdmin only lets admins through.
+// Must be wrapped by AuthenticatedWithUser.
+func (cfg *ApiConfig) RequireAdmin(next http.HandlerFunc) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		user := UserFromContext(request.Context())
+		if !user.IsAdmin {
+			RespondWithError(writer, http.StatusForbidden, CLIENT_MSG_ADMIN_ONLY)
+			return
+		}
+
+		next(writer, request)
+	}
+}
+
+// Validate the bearer token and return a context that carries the user id and the claims.
+// Responds with 401 and returns false if the token is missing or invalid.
+func (cfg *ApiConfig) authenticate(writer http.ResponseWriter, request *http.Request) (context.Context, bool) {
+	// Get the bearer token from the request
+	token, tokenErr := GetBearerToken(request.Header)
+	if tokenErr != nil {
+		cfg.LogError(SERVER_MSG_ERROR_GET_BEARER_TOKEN, tokenErr)
+		RespondWithError(writer, http.StatusUnauthorized, CLIENT_MSG_UNAUTHORIZED)
+		return nil, false
+	}
+
+	// Verify the bearer token
+	claims, jwtErr := ValidateJWTClaims(request.Context(), token, cfg.Keys, cfg.Revocations)
+	if jwtErr != nil {
+		cfg.LogError(SERVER_MSG_JWT_VALIDATION_FAILED, jwtErr)
+		RespondWithError(writer, http.StatusUnauthorized, CLIENT_MSG_UNAUTHORIZED)
+		return nil, false
+	}
+
+	// Get the user id
+	userId, userIdErr := claims.UserId()
+	if userIdErr != nil {
+		cfg.LogError(SERVER_MSG_JWT_VALIDATION_FAILED, userIdErr)
+		RespondWithError(writer, http.StatusUnauthorized, CLIENT_MSG_UNAUTHORIZED)
+		return nil, false
+	}
+
+	ctx := context.WithValue(request.Context(), userIdContextKey, userId)
+	ctx = context.WithValue(ctx, claimsContextKey, claims)
+	return ctx, true
+}
+
+// Get the authenticated user id. Returns 0 if the request did not go through Authenticated.
+func UserIdFromContext(ctx context.Context) int64 {
+	userId, _ := ctx.Value(userIdContextKey).(int64)
+	return userId
+}
+
+// Get the authenticated user. Returns an empty user if the request did not go through AuthenticatedWithUser.
+func UserFromContext(ctx context.Context) database.User {
+	user, _ := ctx.Value(userContextKey).(database.User)
+	return user
+}
+
+// Get the claims of the access token. Returns nil if the request did not go through Authenticated.
+func ClaimsFromContext(ctx context.Context) *AccessTokenClaims {
+	claims, _ := ctx.Value(claimsContextKey).(*AccessTokenClaims)
+	return claims
+}
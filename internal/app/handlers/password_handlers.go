@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/app"
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/app/mailer"
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/database"
+)
+
+type forgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+type resetPasswordRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+// Forgot password. Emails a single use reset token to the user.
+// Always responds with 200 so that the endpoint cannot be used to find out which emails are registered.
+func (cfg *ApiConfig) ForgotPasswordHandler(writer http.ResponseWriter, request *http.Request) {
+	// Decode the request
+	decoder := json.NewDecoder(request.Body)
+	forgotRequest := forgotPasswordRequest{}
+	if decodeErr := decoder.Decode(&forgotRequest); decodeErr != nil {
+		RespondWithError(writer, http.StatusBadRequest, decodeErr.Error())
+		return
+	}
+
+	if forgotRequest.Email == "" {
+		RespondWithError(writer, http.StatusBadRequest, CLIENT_MSG_EMAIL_CANNOT_BE_EMPTY)
+		return
+	}
+
+	// Find user by email
+	user, getUserErr := cfg.Db.GetUserByEmail(request.Context(), forgotRequest.Email)
+	if getUserErr != nil {
+		if errors.Is(getUserErr, sql.ErrNoRows) {
+			writer.WriteHeader(http.StatusOK)
+			return
+		}
+		cfg.LogError(SERVER_MSG_GET_USER_FAILED, getUserErr)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_FORGOT_PASSWORD)
+		return
+	}
+
+	// Only the latest reset token should work
+	if invalidateErr := cfg.Db.InvalidatePasswordResetTokensForUser(request.Context(), user.ID); invalidateErr != nil {
+		cfg.LogError(SERVER_MSG_CREATE_RESET_TOKEN_FAILED, invalidateErr)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_FORGOT_PASSWORD)
+		return
+	}
+
+	// Create the reset token and store only its hash
+	resetToken, tokenErr := MakeRandomToken()
+	if tokenErr != nil {
+		cfg.LogError(SERVER_MSG_CREATE_RESET_TOKEN_FAILED, tokenErr)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_FORGOT_PASSWORD)
+		return
+	}
+
+	params := database.CreatePasswordResetTokenParams{
+		UserID:    user.ID,
+		TokenHash: HashToken(resetToken),
+		ExpiresAt: pgtype.Timestamp{
+			Time:  time.Now().Add(app.PASSWORD_RESET_EXPIRE_TIME),
+			Valid: true,
+		},
+	}
+	if _, createTokenErr := cfg.Db.CreatePasswordResetToken(request.Context(), params); createTokenErr != nil {
+		cfg.LogError(SERVER_MSG_CREATE_RESET_TOKEN_FAILED, createTokenErr)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_FORGOT_PASSWORD)
+		return
+	}
+
+	// Email the token. A failure here is only logged, otherwise the response would reveal that the email exists.
+	message := mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your Sanctuary password",
+		Body: fmt.Sprintf(
+			"Someone asked to reset the password of your Sanctuary account.\n\nYour reset token is:\n\n%v\n\nThe token expires in %v. If you did not ask for this, you can ignore this email.",
+			resetToken,
+			app.PASSWORD_RESET_EXPIRE_TIME,
+		),
+	}
+	if sendErr := cfg.Mailer.Send(request.Context(), message); sendErr != nil {
+		cfg.LogError(SERVER_MSG_SEND_MAIL_FAILED, sendErr)
+	}
+
+	// Return empty response
+	writer.WriteHeader(http.StatusOK)
+}
+
+// Reset password with a token from ForgotPasswordHandler.
+func (cfg *ApiConfig) ResetPasswordHandler(writer http.ResponseWriter, request *http.Request) {
+	// Decode the request
+	decoder := json.NewDecoder(request.Body)
+	resetRequest := resetPasswordRequest{}
+	if decodeErr := decoder.Decode(&resetRequest); decodeErr != nil {
+		RespondWithError(writer, http.StatusBadRequest, decodeErr.Error())
+		return
+	}
+
+	if resetRequest.Token == "" {
+		RespondWithError(writer, http.StatusBadRequest, CLIENT_MSG_INVALID_RESET_TOKEN)
+		return
+	}
+
+	if resetRequest.Password == "" {
+		RespondWithError(writer, http.StatusBadRequest, CLIENT_MSG_PASSWORD_CANNOT_BE_EMPTY)
+		return
+	}
+
+	// Find the reset token
+	resetToken, getTokenErr := cfg.Db.GetPasswordResetTokenByHash(request.Context(), HashToken(resetRequest.Token))
+	if getTokenErr != nil {
+		if errors.Is(getTokenErr, sql.ErrNoRows) {
+			RespondWithError(writer, http.StatusBadRequest, CLIENT_MSG_INVALID_RESET_TOKEN)
+			return
+		}
+		cfg.LogError(SERVER_MSG_GET_RESET_TOKEN_FAILED, getTokenErr)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_RESET_PASSWORD)
+		return
+	}
+
+	// Mark the token as used. The query only matches unused and unexpired tokens.
+	affectedRows, markUsedErr := cfg.Db.MarkPasswordResetTokenUsed(request.Context(), resetToken.ID)
+	if markUsedErr != nil {
+		cfg.LogError(SERVER_MSG_GET_RESET_TOKEN_FAILED, markUsedErr)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_RESET_PASSWORD)
+		return
+	}
+	if affectedRows == 0 {
+		RespondWithError(writer, http.StatusBadRequest, CLIENT_MSG_INVALID_RESET_TOKEN)
+		return
+	}
+
+	// Hash the new password
+	hashedPassword, hashErr := HashPassword(resetRequest.Password)
+	if hashErr != nil {
+		cfg.LogError(SERVER_MSG_PASSWORD_HASH_FAILED, hashErr)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_RESET_PASSWORD)
+		return
+	}
+
+	params := database.UpdateUserPasswordParams{
+		ID:             resetToken.UserID,
+		HashedPassword: hashedPassword,
+	}
+	if updateErr := cfg.Db.UpdateUserPassword(request.Context(), params); updateErr != nil {
+		cfg.LogError(SERVER_MSG_UPDATE_PASSWORD_FAILED, updateErr)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_RESET_PASSWORD)
+		return
+	}
+
+	// Whoever knew the old password should not stay logged in
+	if revokeErr := cfg.revokeAllTokensForUser(request.Context(), resetToken.UserID); revokeErr != nil {
+		cfg.LogError(SERVER_MSG_REVOKE_TOKEN_FAILED, revokeErr)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_RESET_PASSWORD)
+		return
+	}
+
+	// Return empty response
+	writer.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/app"
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/database"
+)
+
+type editPostRequest struct {
+	Content string `json:"content"`
+}
+
+type postRevisionResponse struct {
+	ID        int64     `json:"id"`
+	Content   string    `json:"content"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type postRevisionListResponse struct {
+	Data []postRevisionResponse `json:"data"`
+}
+
+// The post belongs to someone else
+var errNotPostOwner = errors.New("post belongs to another user")
+
+// The post is older than the edit window
+var errPostEditWindowOver = errors.New("post edit window is over")
+
+// Edit the content of a post of the authenticated user.
+// The content before the edit is kept in the edit history of the post.
+func (cfg *ApiConfig) EditPostHandler(writer http.ResponseWriter, request *http.Request) {
+	postId, parseErr := postIdFromPath(request)
+	if parseErr != nil {
+		RespondWithError(writer, http.StatusBadRequest, CLIENT_MSG_INVALID_POST_ID)
+		return
+	}
+
+	// Decode the request
+	decoder := json.NewDecoder(request.Body)
+	editRequest := editPostRequest{}
+	if decodeErr := decoder.Decode(&editRequest); decodeErr != nil {
+		RespondWithError(writer, http.StatusBadRequest, decodeErr.Error())
+		return
+	}
+
+	content := strings.TrimSpace(editRequest.Content)
+	if content == "" {
+		RespondWithError(writer, http.StatusBadRequest, CLIENT_MSG_POST_CONTENT_CANNOT_BE_EMPTY)
+		return
+	}
+
+	userId := UserIdFromContext(request.Context())
+	editErr := cfg.inTx(request.Context(), func(qtx *database.Queries) error {
+		post, getPostErr := qtx.GetPostForUpdate(request.Context(), postId)
+		if getPostErr != nil {
+			return getPostErr
+		}
+		if post.UserID != userId {
+			return errNotPostOwner
+		}
+		if cfg.now().Sub(post.CreatedAt.Time) > app.POST_EDIT_WINDOW {
+			return errPostEditWindowOver
+		}
+
+		// Nothing to change
+		if post.Content == content {
+			return nil
+		}
+
+		if revisionErr := qtx.CreatePostRevision(request.Context(), database.CreatePostRevisionParams{
+			PostID:    post.ID,
+			Content:   post.Content,
+			CreatedAt: post.UpdatedAt,
+		}); revisionErr != nil {
+			return revisionErr
+		}
+
+		_, updateErr := qtx.EditPost(request.Context(), database.EditPostParams{
+			ID:      post.ID,
+			Content: content,
+		})
+		return updateErr
+	})
+	if editErr != nil {
+		cfg.respondWithPostChangeError(writer, editErr, CLIENT_MSG_ERROR_EDIT_POST)
+		return
+	}
+
+	// Get the post with its counts and author
+	postFromDb, getPostErr := cfg.Db.GetPostById(request.Context(), database.GetPostByIdParams{
+		ID:       postId,
+		ViewerID: userId,
+	})
+	if getPostErr != nil {
+		cfg.LogError(getPostErr.Error(), getPostErr)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_EDIT_POST)
+		return
+	}
+
+	response, mediaErr := cfg.newPostResponses(request.Context(), []database.VisiblePost{postFromDb})
+	if mediaErr != nil {
+		cfg.LogError(mediaErr.Error(), mediaErr)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_EDIT_POST)
+		return
+	}
+
+	RespondWithJson(writer, http.StatusOK, response[0])
+}
+
+// Soft delete a post of the authenticated user.
+// Its media is removed from storage later by the post media purger.
+func (cfg *ApiConfig) DeletePostHandler(writer http.ResponseWriter, request *http.Request) {
+	postId, parseErr := postIdFromPath(request)
+	if parseErr != nil {
+		RespondWithError(writer, http.StatusBadRequest, CLIENT_MSG_INVALID_POST_ID)
+		return
+	}
+
+	userId := UserIdFromContext(request.Context())
+	deleteErr := cfg.inTx(request.Context(), func(qtx *database.Queries) error {
+		post, getPostErr := qtx.GetPostForUpdate(request.Context(), postId)
+		if getPostErr != nil {
+			return getPostErr
+		}
+		if post.UserID != userId {
+			return errNotPostOwner
+		}
+
+		if softDeleteErr := qtx.SoftDeletePost(request.Context(), post.ID); softDeleteErr != nil {
+			return softDeleteErr
+		}
+		return qtx.SoftDeletePostMedia(request.Context(), post.ID)
+	})
+	if deleteErr != nil {
+		cfg.respondWithPostChangeError(writer, deleteErr, CLIENT_MSG_ERROR_DELETE_POST)
+		return
+	}
+
+	writer.WriteHeader(http.StatusOK)
+}
+
+// Get the earlier versions of a post, most recent first
+func (cfg *ApiConfig) GetPostRevisionsHandler(writer http.ResponseWriter, request *http.Request) {
+	postId, parseErr := postIdFromPath(request)
+	if parseErr != nil {
+		RespondWithError(writer, http.StatusBadRequest, CLIENT_MSG_INVALID_POST_ID)
+		return
+	}
+
+	if !cfg.postIsVisible(writer, request, postId, CLIENT_MSG_ERROR_GET_POST_REVISIONS) {
+		return
+	}
+
+	revisions, err := cfg.Db.GetPostRevisions(request.Context(), postId)
+	if err != nil {
+		cfg.LogError(err.Error(), err)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_GET_POST_REVISIONS)
+		return
+	}
+
+	response := postRevisionListResponse{Data: []postRevisionResponse{}}
+	for _, revision := range revisions {
+		response.Data = append(response.Data, postRevisionResponse{
+			ID:        revision.ID,
+			Content:   revision.Content,
+			CreatedAt: revision.CreatedAt.Time,
+		})
+	}
+
+	RespondWithJson(writer, http.StatusOK, response)
+}
+
+// Respond to an error from editing or deleting a post
+func (cfg *ApiConfig) respondWithPostChangeError(writer http.ResponseWriter, err error, errorMsg string) {
+	if errors.Is(err, sql.ErrNoRows) {
+		RespondWithError(writer, http.StatusNotFound, CLIENT_MSG_POST_NOT_FOUND)
+		return
+	}
+	if errors.Is(err, errNotPostOwner) {
+		RespondWithError(writer, http.StatusForbidden, CLIENT_MSG_NOT_POST_OWNER)
+		return
+	}
+	if errors.Is(err, errPostEditWindowOver) {
+		RespondWithError(writer, http.StatusForbidden, CLIENT_MSG_POST_EDIT_WINDOW_OVER)
+		return
+	}
+
+	cfg.LogError(err.Error(), err)
+	RespondWithError(writer, http.StatusInternalServerError, errorMsg)
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/app"
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/database"
+	"go.uber.org/zap"
+)
+
+// Remove the media of deleted posts every interval until ctx is done. Meant to be run in its own goroutine.
+func (cfg *ApiConfig) StartPostMediaPurger(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		purged, purgeErr := cfg.PurgeDeletedPostMedia(ctx)
+		if purgeErr != nil {
+			cfg.LogError(SERVER_MSG_PURGE_POST_MEDIA_FAILED, purgeErr)
+		} else if purged > 0 {
+			cfg.Logger.Info("Purged media of deleted posts", zap.Int("count", purged))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Delete the media of deleted posts from storage, then from the db.
+// Returns the number of purged files.
+func (cfg *ApiConfig) PurgeDeletedPostMedia(ctx context.Context) (int, error) {
+	media, getMediaErr := cfg.Db.GetDeletedPostMedia(ctx, database.GetDeletedPostMediaParams{
+		DeletedAt: pgtype.Timestamp{Time: cfg.now(), Valid: true},
+		Limit:     app.POST_MEDIA_PURGE_BATCH_SIZE,
+	})
+	if getMediaErr != nil {
+		return 0, getMediaErr
+	}
+
+	purged := 0
+	for _, medium := range media {
+		// The row is kept if the files could not be deleted, so they are retried on the next run
+		if deleteErr := cfg.deletePostMediumFiles(ctx, medium); deleteErr != nil {
+			cfg.LogError(SERVER_MSG_PURGE_POST_MEDIA_FAILED, deleteErr)
+			continue
+		}
+
+		if deleteRowErr := cfg.Db.DeletePostMedia(ctx, medium.ID); deleteRowErr != nil {
+			cfg.LogError(SERVER_MSG_PURGE_POST_MEDIA_FAILED, deleteRowErr)
+			continue
+		}
+		purged++
+	}
+
+	return purged, nil
+}
+
+// Delete the file of a post medium together with its renditions and its poster
+func (cfg *ApiConfig) deletePostMediumFiles(ctx context.Context, medium database.GetDeletedPostMediaRow) error {
+	fileUrls := []string{medium.MediaUrl}
+	for _, optionalUrl := range []pgtype.Text{medium.FeedUrl, medium.ThumbnailUrl, medium.PosterUrl} {
+		if optionalUrl.Valid {
+			fileUrls = append(fileUrls, optionalUrl.String)
+		}
+	}
+
+	for _, fileUrl := range fileUrls {
+		if deleteErr := cfg.deleteUploadedFile(ctx, fileUrl); deleteErr != nil {
+			return deleteErr
+		}
+	}
+	return nil
+}
@@ -0,0 +1,219 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/app"
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/app/imaging"
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/app/media"
+	"golang.org/x/sync/errgroup"
+)
+
+// A post media file uploaded to storage, not saved in the db yet.
+// Still images have their smaller renditions, url is the full one.
+type postMediaUpload struct {
+	url          string
+	feedUrl      string
+	thumbnailUrl string
+	posterUrl    string
+	mediaType    media.Kind
+	width        pgtype.Int4
+	height       pgtype.Int4
+	durationMs   pgtype.Int4
+}
+
+// Size limit of a post media file and the message for files over it, by the sniffed content type
+func postMediaSizeLimit(contentType string) (int64, string) {
+	switch {
+	case strings.HasPrefix(contentType, "video/"):
+		return app.MAX_POST_VIDEO_SIZE, CLIENT_MSG_POST_VIDEO_TOO_LARGE
+	case contentType == "image/gif":
+		return app.MAX_POST_GIF_SIZE, CLIENT_MSG_POST_GIF_TOO_LARGE
+	default:
+		return app.MAX_POST_IMAGE_SIZE, CLIENT_MSG_POST_IMAGE_TOO_LARGE
+	}
+}
+
+// The media files of a create post request in order. The single "file" field of older clients comes first.
+// Empty files are skipped. The multipart form must be parsed before calling this.
+func postMediaFileHeaders(request *http.Request) []*multipart.FileHeader {
+	if request.MultipartForm == nil {
+		return nil
+	}
+
+	fileHeaders := []*multipart.FileHeader{}
+	for _, field := range []string{"file", "files"} {
+		for _, fileHeader := range request.MultipartForm.File[field] {
+			if fileHeader.Size == 0 {
+				continue
+			}
+			fileHeaders = append(fileHeaders, fileHeader)
+		}
+	}
+	return fileHeaders
+}
+
+// Find out what each media file is by its content and check it against the size limit of its kind.
+// Returns the status code to respond with if a file cannot be used.
+func probePostMedia(fileHeaders []*multipart.FileHeader) ([]media.Info, int, error) {
+	infos := []media.Info{}
+	for _, fileHeader := range fileHeaders {
+		info, errCode, probeErr := probePostMediaFile(fileHeader)
+		if probeErr != nil {
+			return nil, errCode, probeErr
+		}
+		infos = append(infos, info)
+	}
+	return infos, 0, nil
+}
+
+// Check the size of one media file before it is parsed, since gifs are decoded completely
+func probePostMediaFile(fileHeader *multipart.FileHeader) (media.Info, int, error) {
+	file, openErr := fileHeader.Open()
+	if openErr != nil {
+		return media.Info{}, http.StatusInternalServerError, openErr
+	}
+	defer file.Close()
+
+	contentType, sniffErr := media.Sniff(file)
+	if errors.Is(sniffErr, media.ErrUnsupportedFormat) {
+		return media.Info{}, http.StatusBadRequest, errors.New(CLIENT_MSG_UNSUPPORTED_POST_MEDIA)
+	}
+	if sniffErr != nil {
+		return media.Info{}, http.StatusInternalServerError, sniffErr
+	}
+
+	maxSize, tooLargeMsg := postMediaSizeLimit(contentType)
+	if fileHeader.Size > maxSize {
+		return media.Info{}, http.StatusBadRequest, errors.New(tooLargeMsg)
+	}
+
+	info, probeErr := media.Probe(file)
+	if errors.Is(probeErr, media.ErrUnsupportedFormat) || errors.Is(probeErr, media.ErrMalformed) {
+		return media.Info{}, http.StatusBadRequest, errors.New(CLIENT_MSG_UNSUPPORTED_POST_MEDIA)
+	}
+	if probeErr != nil {
+		return media.Info{}, http.StatusInternalServerError, probeErr
+	}
+
+	// Still images are decoded again when they are processed
+	if info.Kind == media.KindImage && info.Width*info.Height > imaging.MaxPixels {
+		return media.Info{}, http.StatusBadRequest, errors.New(CLIENT_MSG_IMAGE_TOO_MANY_PIXELS)
+	}
+
+	return info, 0, nil
+}
+
+// Upload the media files of a post at the same time. The uploads keep the order of the files.
+// infos are the results of probePostMedia for the files. If any upload fails, the files that were uploaded are deleted again.
+// Still images are processed into renditions first, errors of imaging.Process are returned as they are.
+func (cfg *ApiConfig) uploadPostMedia(ctx context.Context, fileHeaders []*multipart.FileHeader, infos []media.Info) ([]postMediaUpload, error) {
+	uploads := make([]postMediaUpload, len(fileHeaders))
+
+	group, groupCtx := errgroup.WithContext(ctx)
+	for index, fileHeader := range fileHeaders {
+		info := infos[index]
+		group.Go(func() error {
+			if info.Kind == media.KindImage {
+				image, imageErr := cfg.uploadProcessedImage(groupCtx, fileHeader, "images")
+				if imageErr != nil {
+					return imageErr
+				}
+				uploads[index] = postMediaUpload{
+					url:          image.fullUrl,
+					feedUrl:      image.feedUrl,
+					thumbnailUrl: image.thumbnailUrl,
+					mediaType:    info.Kind,
+				}
+				// Turning the image can swap the sides, and webp sizes are only known after processing
+				if image.width > 0 && image.height > 0 {
+					uploads[index].width = pgtype.Int4{Int32: int32(image.width), Valid: true}
+					uploads[index].height = pgtype.Int4{Int32: int32(image.height), Valid: true}
+				}
+				return nil
+			}
+
+			// Animated gifs and videos are uploaded as they are, with the content type sniffed from them
+			prefix := "images"
+			if info.Kind == media.KindVideo {
+				prefix = "videos"
+			}
+
+			downloadUrl, uploadErr := UploadFileHeader(
+				groupCtx,
+				fileHeader,
+				prefix,
+				info.ContentType,
+				cfg.Storage,
+			)
+			if uploadErr != nil {
+				return uploadErr
+			}
+
+			upload := postMediaUpload{
+				url:       downloadUrl,
+				mediaType: info.Kind,
+			}
+			if info.Width > 0 && info.Height > 0 {
+				upload.width = pgtype.Int4{Int32: int32(info.Width), Valid: true}
+				upload.height = pgtype.Int4{Int32: int32(info.Height), Valid: true}
+			}
+			if info.Duration > 0 {
+				upload.durationMs = pgtype.Int4{Int32: int32(info.Duration.Milliseconds()), Valid: true}
+			}
+			// Set before uploading the poster, so the file is deleted if that fails
+			uploads[index] = upload
+
+			if info.Poster == nil {
+				return nil
+			}
+			poster := bytes.Buffer{}
+			if encodeErr := png.Encode(&poster, info.Poster); encodeErr != nil {
+				return encodeErr
+			}
+			posterUrl, posterErr := UploadBytes(
+				groupCtx,
+				poster.Bytes(),
+				"posters",
+				".png",
+				"image/png",
+				cfg.Storage,
+			)
+			if posterErr != nil {
+				return posterErr
+			}
+			uploads[index].posterUrl = posterUrl
+			return nil
+		})
+	}
+
+	if err := group.Wait(); err != nil {
+		cfg.deletePostMediaUploads(ctx, uploads)
+		return nil, err
+	}
+
+	return uploads, nil
+}
+
+// Delete uploaded post media that will not be saved. Failures are only logged.
+func (cfg *ApiConfig) deletePostMediaUploads(ctx context.Context, uploads []postMediaUpload) {
+	// The request may already be cancelled, which is often why the files are deleted
+	ctx = context.WithoutCancel(ctx)
+	for _, upload := range uploads {
+		for _, url := range []string{upload.url, upload.feedUrl, upload.thumbnailUrl, upload.posterUrl} {
+			if url == "" {
+				continue
+			}
+			if deleteErr := cfg.deleteUploadedFile(ctx, url); deleteErr != nil {
+				cfg.LogError(deleteErr.Error(), deleteErr)
+			}
+		}
+	}
+}
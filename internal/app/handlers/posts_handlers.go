@@ -1,15 +1,22 @@
 package handlers
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
 	"net/http"
 	"strconv"
 	"time"
 
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/app"
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/app/imaging"
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/app/media"
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/app/ranking"
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/app/validators"
 	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/database"
 )
 
@@ -26,13 +33,13 @@ type RequestWithPostId struct {
 
 // Comment Response
 type CommentResponse struct {
-	ID        int64                    `json:"id"`
-	Content   string                   `json:"content"`
-	CreatedAt time.Time                `json:"created_at"`
-	UpdatedAt time.Time                `json:"updated_at"`
-	PostId    int64                    `json:"post_id"`
-	UserId    int64                    `json:"user_id"`
-	User      userWithoutTokenResponse `json:"user"`
+	ID        int64              `json:"id"`
+	Content   string             `json:"content"`
+	CreatedAt time.Time          `json:"created_at"`
+	UpdatedAt time.Time          `json:"updated_at"`
+	PostId    int64              `json:"post_id"`
+	UserId    int64              `json:"user_id"`
+	User      publicUserResponse `json:"user"`
 }
 
 // Post List Response
@@ -42,42 +49,50 @@ type PostListResponse struct {
 }
 
 // Meta Response
+// CurrentPage is only set when the list is requested with ?page=, cursors are set otherwise.
 type MetaResponse struct {
-	CurrentPage int    `json:"current_page"`
+	CurrentPage int    `json:"current_page,omitempty"`
 	NextPageUrl string `json:"next_page_url"`
+	NextCursor  string `json:"next_cursor"`
+	PrevCursor  string `json:"prev_cursor"`
 }
 
-// Post Response
+// Comment List Response
+type CommentListResponse struct {
+	Data []CommentResponse `json:"data"`
+	Meta MetaResponse      `json:"meta"`
+}
+
+// Post Response. MediaUrl is the first of Media, for clients that only show one image.
 type PostResponse struct {
-	ID           int64                    `json:"id"`
-	Content      string                   `json:"content"`
-	MediaUrl     string                   `json:"media_url"`
-	LikedByUser  bool                     `json:"liked_by_user"`
-	LikeCount    int                      `json:"like_count"`
-	CommentCount int                      `json:"comment_count"`
-	CreatedAt    time.Time                `json:"created_at"`
-	UpdatedAt    time.Time                `json:"updated_at"`
-	User         userWithoutTokenResponse `json:"user"`
+	ID           int64               `json:"id"`
+	Content      string              `json:"content"`
+	MediaUrl     string              `json:"media_url"`
+	Media        []PostMediaResponse `json:"media"`
+	LikedByUser  bool                `json:"liked_by_user"`
+	LikeCount    int                 `json:"like_count"`
+	CommentCount int                 `json:"comment_count"`
+	Edited       bool                `json:"edited"`
+	CreatedAt    time.Time           `json:"created_at"`
+	UpdatedAt    time.Time           `json:"updated_at"`
+	User         publicUserResponse  `json:"user"`
+}
+
+// Type is image, gif or video. Gifs have the first frame as the poster.
+// Still images have their renditions in Variants, Url is the full one.
+type PostMediaResponse struct {
+	Url        string                 `json:"url"`
+	Type       string                 `json:"type"`
+	Width      int                    `json:"width,omitempty"`
+	Height     int                    `json:"height,omitempty"`
+	DurationMs int                    `json:"duration_ms,omitempty"`
+	PosterUrl  string                 `json:"poster_url,omitempty"`
+	Variants   *imageVariantsResponse `json:"variants,omitempty"`
+	Order      int                    `json:"order"`
 }
 
 // Get All Comments for Post
 func (cfg *ApiConfig) GetAllCommentsHandler(writer http.ResponseWriter, request *http.Request) {
-	// Get the bearer token from the request
-	token, tokenErr := GetBearerToken(request.Header)
-	if tokenErr != nil {
-		cfg.LogError(SERVER_MSG_ERROR_GET_BEARER_TOKEN, tokenErr)
-		RespondWithError(writer, http.StatusUnauthorized, "You are not authorized get comments.")
-		return
-	}
-
-	// Verify the bearer token and get the id
-	_, jwtErr := ValidateJWT(token, cfg.TokenSecret)
-	if jwtErr != nil {
-		cfg.LogError(SERVER_MSG_JWT_VALIDATION_FAILED, jwtErr)
-		RespondWithError(writer, http.StatusUnauthorized, "You are not authorized to get comments.")
-		return
-	}
-
 	// Parse the reqeust
 	decoder := json.NewDecoder(request.Body)
 	requestParams := RequestWithPostId{}
@@ -91,9 +106,21 @@ func (cfg *ApiConfig) GetAllCommentsHandler(writer http.ResponseWriter, request
 	postId := requestParams.PostId
 	if postId == 0 {
 		RespondWithError(writer, http.StatusBadRequest, "Post id cannot be empty")
+		return
+	}
+
+	// The same checks as GetPostCommentsHandler
+	if !cfg.postIsVisible(writer, request, int64(postId), "Something went wrong while getting comments.") {
+		return
+	}
+	if !cfg.postAuthorHasNotBlocked(writer, request, int64(postId), "Something went wrong while getting comments.") {
+		return
 	}
 
-	comments, commentsErr := cfg.Db.GetCommentsForPost(request.Context(), int64(postId))
+	comments, commentsErr := cfg.Db.GetCommentsForPost(request.Context(), database.GetCommentsForPostParams{
+		PostID:   int64(postId),
+		ViewerID: UserIdFromContext(request.Context()),
+	})
 	if commentsErr != nil {
 		cfg.LogError(commentsErr.Error(), commentsErr)
 		RespondWithError(writer, http.StatusInternalServerError, "Something went wrong while getting comments.")
@@ -102,57 +129,98 @@ func (cfg *ApiConfig) GetAllCommentsHandler(writer http.ResponseWriter, request
 
 	response := []CommentResponse{}
 	for _, commentFromDb := range comments {
-		commentResponse := CommentResponse{
-			ID:        commentFromDb.ID,
-			Content:   commentFromDb.Content,
-			CreatedAt: commentFromDb.CreatedAt.Time,
-			UpdatedAt: commentFromDb.UpdatedAt.Time,
-			PostId:    commentFromDb.PostID,
-			UserId:    commentFromDb.UserID,
-			User: userWithoutTokenResponse{
-				ID:              commentFromDb.AuthorID,
-				Email:           commentFromDb.AuthorEmail,
-				UserName:        commentFromDb.AuthorUserName,
-				FullName:        commentFromDb.AuthorFullName,
-				ProfileImageUrl: commentFromDb.AuthorProfileImageUrl.String,
-				Dob:             FormatNullDobString(commentFromDb.AuthorDob.Time),
-				CreatedAt:       commentFromDb.AuthorCreatedAt.Time,
-				UpdatedAt:       commentFromDb.AuthorUpdatedAt.Time,
-			},
-		}
-
-		response = append(response, commentResponse)
+		response = append(response, newCommentResponse(commentFromDb))
 	}
 
 	RespondWithJson(writer, http.StatusOK, response)
 }
 
-// Create Comment
-func (cfg *ApiConfig) CreateCommentHandler(writer http.ResponseWriter, request *http.Request) {
-	// Get the bearer token from the request
-	token, tokenErr := GetBearerToken(request.Header)
-	if tokenErr != nil {
-		cfg.LogError(SERVER_MSG_ERROR_GET_BEARER_TOKEN, tokenErr)
-		RespondWithError(writer, http.StatusUnauthorized, "You are not authorized to create comments.")
+// Get the comments of a post, oldest first
+func (cfg *ApiConfig) GetPostCommentsHandler(writer http.ResponseWriter, request *http.Request) {
+	// Get the authenticated user id
+	userId := UserIdFromContext(request.Context())
+
+	postId, postIdErr := strconv.ParseInt(request.PathValue("post_id"), 10, 64)
+	if postIdErr != nil {
+		RespondWithError(writer, http.StatusBadRequest, "Post id must be a number")
 		return
 	}
 
-	// Verify the bearer token and get the id
-	userId, jwtErr := ValidateJWT(token, cfg.TokenSecret)
-	if jwtErr != nil {
-		cfg.LogError(SERVER_MSG_JWT_VALIDATION_FAILED, jwtErr)
-		RespondWithError(writer, http.StatusUnauthorized, "You are not authorized to create comments.")
+	if !cfg.postIsVisible(writer, request, postId, "Something went wrong while getting comments.") {
+		return
+	}
+	if !cfg.postAuthorHasNotBlocked(writer, request, postId, "Something went wrong while getting comments.") {
 		return
 	}
 
-	// Get user from db
-	user, getUserErr := cfg.Db.GetUserById(request.Context(), userId)
-	if getUserErr != nil {
-		cfg.LogError(getUserErr.Error(), getUserErr)
-		RespondWithError(writer, http.StatusInternalServerError, "Something went wrong while commenting. Please log out, log back in and try again.")
+	cursor, cursorErr := cursorFromRequest(request)
+	if cursorErr != nil {
+		RespondWithError(writer, http.StatusBadRequest, CLIENT_MSG_INVALID_CURSOR)
 		return
 	}
 
+	// Oldest first, so the previous page is the older comments
+	comments := []database.GetCommentsForPostRow{}
+	cursorCreatedAt, cursorId := cursorPosition(cursor, pgtype.NegativeInfinity)
+	if cursor != nil && cursor.Prev {
+		params := database.GetCommentsForPostBeforeParams{
+			ViewerID:        userId,
+			PostID:          postId,
+			CursorCreatedAt: cursorCreatedAt,
+			CursorID:        cursorId,
+			PageLimit:       app.PAGE_SIZE + 1,
+		}
+		olderComments, commentsErr := cfg.Db.GetCommentsForPostBefore(request.Context(), params)
+		if commentsErr != nil {
+			cfg.LogError(commentsErr.Error(), commentsErr)
+			RespondWithError(writer, http.StatusInternalServerError, "Something went wrong while getting comments.")
+			return
+		}
+		for _, comment := range olderComments {
+			comments = append(comments, database.GetCommentsForPostRow(comment))
+		}
+	} else {
+		params := database.GetCommentsForPostAfterParams{
+			ViewerID:        userId,
+			PostID:          postId,
+			CursorCreatedAt: cursorCreatedAt,
+			CursorID:        cursorId,
+			PageLimit:       app.PAGE_SIZE + 1,
+		}
+		newerComments, commentsErr := cfg.Db.GetCommentsForPostAfter(request.Context(), params)
+		if commentsErr != nil {
+			cfg.LogError(commentsErr.Error(), commentsErr)
+			RespondWithError(writer, http.StatusInternalServerError, "Something went wrong while getting comments.")
+			return
+		}
+		for _, comment := range newerComments {
+			comments = append(comments, database.GetCommentsForPostRow(comment))
+		}
+	}
+
+	page := NewCursorPage(comments, app.PAGE_SIZE, cursor, func(comment database.GetCommentsForPostRow) Cursor {
+		return Cursor{CreatedAt: comment.CreatedAt.Time, ID: comment.ID}
+	})
+
+	commentList := []CommentResponse{}
+	for _, commentFromDb := range page.Items {
+		commentList = append(commentList, newCommentResponse(commentFromDb))
+	}
+
+	response := CommentListResponse{
+		Data: commentList,
+		Meta: cfg.cursorMetaResponse(fmt.Sprintf("/api/posts/%v/comments", postId), page.NextCursor, page.PrevCursor),
+	}
+
+	RespondWithJson(writer, http.StatusOK, response)
+}
+
+// Create Comment
+func (cfg *ApiConfig) CreateCommentHandler(writer http.ResponseWriter, request *http.Request) {
+	// Get the authenticated user
+	user := UserFromContext(request.Context())
+	userId := user.ID
+
 	// Parse the reqeust
 	decoder := json.NewDecoder(request.Body)
 	requestParams := CommentRequest{}
@@ -173,6 +241,15 @@ func (cfg *ApiConfig) CreateCommentHandler(writer http.ResponseWriter, request *
 		return
 	}
 
+	if !cfg.postIsVisible(writer, request, int64(requestParams.PostId), "Something went wrong while commenting. Please try again.") {
+		return
+	}
+
+	// Users blocked by the author cannot comment
+	if !cfg.postAuthorHasNotBlocked(writer, request, int64(requestParams.PostId), "Something went wrong while commenting. Please try again.") {
+		return
+	}
+
 	// Add comment.
 	params := database.CreateCommentParams{
 		Content: requestParams.Content,
@@ -194,16 +271,7 @@ func (cfg *ApiConfig) CreateCommentHandler(writer http.ResponseWriter, request *
 		UpdatedAt: commentFromDb.UpdatedAt.Time,
 		PostId:    commentFromDb.PostID,
 		UserId:    commentFromDb.UserID,
-		User: userWithoutTokenResponse{
-			ID:              userId,
-			Email:           user.Email,
-			UserName:        user.UserName,
-			FullName:        user.FullName,
-			ProfileImageUrl: user.ProfileImageUrl.String,
-			Dob:             FormatNullDobString(user.Dob.Time),
-			CreatedAt:       user.CreatedAt.Time,
-			UpdatedAt:       user.UpdatedAt.Time,
-		},
+		User:      newPublicUserResponse(user),
 	}
 
 	RespondWithJson(writer, http.StatusCreated, response)
@@ -211,21 +279,8 @@ func (cfg *ApiConfig) CreateCommentHandler(writer http.ResponseWriter, request *
 
 // Post Like
 func (cfg *ApiConfig) PostLikeHandler(writer http.ResponseWriter, request *http.Request) {
-	// Get the bearer token from the request
-	token, tokenErr := GetBearerToken(request.Header)
-	if tokenErr != nil {
-		cfg.LogError(SERVER_MSG_ERROR_GET_BEARER_TOKEN, tokenErr)
-		RespondWithError(writer, http.StatusUnauthorized, "You are not authorized to like the post.")
-		return
-	}
-
-	// Verify the bearer token and get the id
-	userId, jwtErr := ValidateJWT(token, cfg.TokenSecret)
-	if jwtErr != nil {
-		cfg.LogError(SERVER_MSG_JWT_VALIDATION_FAILED, jwtErr)
-		RespondWithError(writer, http.StatusUnauthorized, "You are not authorized to like the post.")
-		return
-	}
+	// Get the authenticated user id
+	userId := UserIdFromContext(request.Context())
 
 	// Parse the reqeust
 	decoder := json.NewDecoder(request.Body)
@@ -239,6 +294,15 @@ func (cfg *ApiConfig) PostLikeHandler(writer http.ResponseWriter, request *http.
 	postId := requestParams.PostId
 	if postId == 0 {
 		RespondWithError(writer, http.StatusBadRequest, "Post id cannot be empty")
+		return
+	}
+
+	// The same checks as GetPostCommentsHandler
+	if !cfg.postIsVisible(writer, request, int64(postId), "Something went wrong while getting comments.") {
+		return
+	}
+	if !cfg.postAuthorHasNotBlocked(writer, request, int64(postId), "Something went wrong while getting comments.") {
+		return
 	}
 
 	// Like and Unlike
@@ -250,6 +314,15 @@ func (cfg *ApiConfig) PostLikeHandler(writer http.ResponseWriter, request *http.
 	if getPostLikeErr != nil {
 		if errors.Is(getPostLikeErr, sql.ErrNoRows) {
 			// Post not yet liked. Insert post like
+			if !cfg.postIsVisible(writer, request, int64(postId), "Something went wrong when liking the post.") {
+				return
+			}
+
+			// Users blocked by the author cannot like. Removing an old like is still allowed.
+			if !cfg.postAuthorHasNotBlocked(writer, request, int64(postId), "Something went wrong when liking the post.") {
+				return
+			}
+
 			// Insert post likes
 			params := database.CreatePostLikeParams{
 				UserID: userId,
@@ -290,43 +363,75 @@ func (cfg *ApiConfig) PostLikeHandler(writer http.ResponseWriter, request *http.
 
 // Get All Posts Handler
 func (cfg *ApiConfig) GetAllPostsHandler(writer http.ResponseWriter, request *http.Request) {
-	// Get the bearer token from the request
-	token, tokenErr := GetBearerToken(request.Header)
-	if tokenErr != nil {
-		cfg.LogError(SERVER_MSG_ERROR_GET_BEARER_TOKEN, tokenErr)
-		RespondWithError(writer, http.StatusUnauthorized, "You are not authorized to get all posts.")
+	// Page numbers keep working until every client has moved to cursors
+	if request.URL.Query().Has("page") {
+		cfg.getAllPostsByPage(writer, request)
 		return
 	}
 
-	// Verify the bearer token and get the id
-	userId, jwtErr := ValidateJWT(token, cfg.TokenSecret)
-	if jwtErr != nil {
-		cfg.LogError(SERVER_MSG_JWT_VALIDATION_FAILED, jwtErr)
-		RespondWithError(writer, http.StatusUnauthorized, "You are not authorized to get all posts.")
+	// Get the authenticated user id
+	userId := UserIdFromContext(request.Context())
+
+	cursor, cursorErr := cursorFromRequest(request)
+	if cursorErr != nil {
+		RespondWithError(writer, http.StatusBadRequest, CLIENT_MSG_INVALID_CURSOR)
 		return
 	}
 
-	// Get the page and calculate the offset
-	var page int
-	pageStr := request.URL.Query().Get("page")
-	if pageStr == "" {
-		// If no page number is provided, default to 1
-		page = 1
+	// Newest first, so the previous page is the newer posts
+	var posts []database.VisiblePost
+	cursorCreatedAt, cursorId := cursorPosition(cursor, pgtype.Infinity)
+	if cursor != nil && cursor.Prev {
+		params := database.GetPostsAfterParams{
+			ViewerID:        userId,
+			CursorCreatedAt: cursorCreatedAt,
+			CursorID:        cursorId,
+			PageLimit:       app.PAGE_SIZE + 1,
+		}
+		newerPosts, getPostsErr := cfg.Db.GetPostsAfter(request.Context(), params)
+		if getPostsErr != nil {
+			cfg.LogError(getPostsErr.Error(), getPostsErr)
+			RespondWithError(writer, http.StatusInternalServerError, "Error retrieving all posts")
+			return
+		}
+		posts = newerPosts
 	} else {
-		// Convert the pageStr to int. If conversion fails, default to 1
-		pageNumber, err := strconv.Atoi(pageStr)
-		if err != nil || pageNumber < 1 {
-			pageNumber = 1
+		params := database.GetPostsBeforeParams{
+			ViewerID:        userId,
+			CursorCreatedAt: cursorCreatedAt,
+			CursorID:        cursorId,
+			PageLimit:       app.PAGE_SIZE + 1,
+		}
+		olderPosts, getPostsErr := cfg.Db.GetPostsBefore(request.Context(), params)
+		if getPostsErr != nil {
+			cfg.LogError(getPostsErr.Error(), getPostsErr)
+			RespondWithError(writer, http.StatusInternalServerError, "Error retrieving all posts")
+			return
 		}
-		page = pageNumber
+		posts = olderPosts
+	}
+
+	cfg.respondWithPostPage(writer, request, "/api/posts", posts, cursor)
+}
+
+// Get all posts with ?page=
+func (cfg *ApiConfig) getAllPostsByPage(writer http.ResponseWriter, request *http.Request) {
+	// Get the authenticated user id
+	userId := UserIdFromContext(request.Context())
+
+	// Get the page and calculate the offset
+	page, pageErr := pageFromRequest(request)
+	if pageErr != nil {
+		RespondWithError(writer, http.StatusBadRequest, CLIENT_MSG_INVALID_PAGE)
+		return
 	}
 	offset := (page - 1) * app.PAGE_SIZE
 
-	// Get all posts
+	// Get one more than the page size to know if there is a next page
 	params := database.GetAllPostsParams{
-		UserID: userId,
-		Offset: int32(offset),
-		Limit:  app.PAGE_SIZE,
+		ViewerID: userId,
+		Offset:   int32(offset),
+		Limit:    app.PAGE_SIZE + 1,
 	}
 	posts, getAllPostsErr := cfg.Db.GetAllPosts(request.Context(), params)
 	if getAllPostsErr != nil {
@@ -335,64 +440,25 @@ func (cfg *ApiConfig) GetAllPostsHandler(writer http.ResponseWriter, request *ht
 		return
 	}
 
-	postList := []PostResponse{}
-
-	for _, postFromDb := range posts {
-
-		// If media url exists, get the first media url.
-		mediaUrl := ""
-		if len(postFromDb.MediaUrlsArray) > 0 {
-			mediaUrl = postFromDb.MediaUrlsArray[0]
-		}
-
-		postResponse := PostResponse{
-			ID:           postFromDb.ID,
-			Content:      postFromDb.Content,
-			MediaUrl:     mediaUrl,
-			LikedByUser:  postFromDb.LikedByUser,
-			LikeCount:    int(postFromDb.LikeCount),
-			CommentCount: int(postFromDb.CommentCount),
-			CreatedAt:    postFromDb.CreatedAt.Time,
-			UpdatedAt:    postFromDb.UpdatedAt.Time,
-			User: userWithoutTokenResponse{
-				ID:              postFromDb.AuthorID,
-				Email:           postFromDb.AuthorEmail,
-				UserName:        postFromDb.AuthorUserName,
-				FullName:        postFromDb.AuthorFullName,
-				ProfileImageUrl: postFromDb.AuthorProfileImageUrl.String,
-				Dob:             FormatNullDobString(postFromDb.AuthorDob.Time),
-				CreatedAt:       postFromDb.AuthorCreatedAt.Time,
-				UpdatedAt:       postFromDb.AuthorUpdatedAt.Time,
-			},
-		}
-
-		postList = append(postList, postResponse)
+	nextPageUrl := ""
+	if len(posts) > app.PAGE_SIZE {
+		posts = posts[:app.PAGE_SIZE]
+		nextPageUrl = fmt.Sprintf("%v/api/posts?page=%v", cfg.GetBaseUrl(), page+1)
 	}
 
-	// Get Total posts
-	totalCount, totalCountErr := cfg.Db.GetPostsCount(request.Context())
-	if totalCountErr != nil {
-		cfg.LogError(totalCountErr.Error(), totalCountErr)
-		RespondWithError(writer, http.StatusInternalServerError, "Something went wrong while fetching posts.")
+	postList, mediaErr := cfg.newPostResponses(request.Context(), posts)
+	if mediaErr != nil {
+		cfg.LogError(mediaErr.Error(), mediaErr)
+		RespondWithError(writer, http.StatusInternalServerError, "Error retrieving all posts")
 		return
 	}
 
-	// Construct Meta Response
-	totalPages := (totalCount + app.PAGE_SIZE - 1) / app.PAGE_SIZE
-	var nextPageUrl string
-	if page < int(totalPages) {
-		nextPageUrl = fmt.Sprintf("%v/api/posts?page=%v", cfg.GetBaseUrl(), page+1)
-	} else {
-		nextPageUrl = ""
-	}
-	metaResponse := MetaResponse{
-		CurrentPage: page,
-		NextPageUrl: nextPageUrl,
-	}
-
 	response := PostListResponse{
 		Data: postList,
-		Meta: metaResponse,
+		Meta: MetaResponse{
+			CurrentPage: page,
+			NextPageUrl: nextPageUrl,
+		},
 	}
 
 	RespondWithJson(writer, http.StatusOK, response)
@@ -400,21 +466,8 @@ func (cfg *ApiConfig) GetAllPostsHandler(writer http.ResponseWriter, request *ht
 
 // Get Post Details Handler
 func (cfg *ApiConfig) GetPostById(writer http.ResponseWriter, request *http.Request) {
-	// Get the bearer token from the request
-	token, tokenErr := GetBearerToken(request.Header)
-	if tokenErr != nil {
-		cfg.LogError(SERVER_MSG_ERROR_GET_BEARER_TOKEN, tokenErr)
-		RespondWithError(writer, http.StatusUnauthorized, "You are not authorized to get the post.")
-		return
-	}
-
-	// Verify the bearer token and get the id
-	userId, jwtErr := ValidateJWT(token, cfg.TokenSecret)
-	if jwtErr != nil {
-		cfg.LogError(SERVER_MSG_JWT_VALIDATION_FAILED, jwtErr)
-		RespondWithError(writer, http.StatusUnauthorized, "You are not authorized to get the post.")
-		return
-	}
+	// Get the authenticated user id
+	userId := UserIdFromContext(request.Context())
 
 	// Parse post id from request
 	postIdStr := request.PathValue("post_id")
@@ -432,72 +485,37 @@ func (cfg *ApiConfig) GetPostById(writer http.ResponseWriter, request *http.Requ
 
 	// Get post by id db call
 	params := database.GetPostByIdParams{
-		ID:     int64(postId),
-		UserID: userId,
+		ID:       int64(postId),
+		ViewerID: userId,
 	}
 	postFromDb, postDetailsErr := cfg.Db.GetPostById(request.Context(), params)
 	if postDetailsErr != nil {
+		// Also not found when the author is blocked, or private and not followed
+		if errors.Is(postDetailsErr, sql.ErrNoRows) {
+			RespondWithError(writer, http.StatusNotFound, CLIENT_MSG_POST_NOT_FOUND)
+			return
+		}
 		cfg.LogError(postDetailsErr.Error(), postDetailsErr)
 		RespondWithError(writer, http.StatusInternalServerError, "Something went wrong while getting the post details.")
 		return
 	}
 
-	// If media url exists, get the first media url.
-	mediaUrl := ""
-	if len(postFromDb.MediaUrlsArray) > 0 {
-		mediaUrl = postFromDb.MediaUrlsArray[0]
-	}
-
-	// Parse the response.
-	response := PostResponse{
-		ID:           postFromDb.ID,
-		Content:      postFromDb.Content,
-		MediaUrl:     mediaUrl,
-		LikedByUser:  postFromDb.LikedByUser,
-		LikeCount:    int(postFromDb.LikeCount),
-		CommentCount: int(postFromDb.CommentCount),
-		CreatedAt:    postFromDb.CreatedAt.Time,
-		UpdatedAt:    postFromDb.UpdatedAt.Time,
-		User: userWithoutTokenResponse{
-			ID:              postFromDb.AuthorID,
-			Email:           postFromDb.AuthorEmail,
-			UserName:        postFromDb.AuthorUserName,
-			FullName:        postFromDb.AuthorFullName,
-			ProfileImageUrl: postFromDb.AuthorProfileImageUrl.String,
-			Dob:             FormatNullDobString(postFromDb.AuthorDob.Time),
-			CreatedAt:       postFromDb.AuthorCreatedAt.Time,
-			UpdatedAt:       postFromDb.AuthorUpdatedAt.Time,
-		},
+	response, mediaErr := cfg.newPostResponses(request.Context(), []database.VisiblePost{postFromDb})
+	if mediaErr != nil {
+		cfg.LogError(mediaErr.Error(), mediaErr)
+		RespondWithError(writer, http.StatusInternalServerError, "Something went wrong while getting the post details.")
+		return
 	}
 
-	RespondWithJson(writer, http.StatusOK, response)
+	RespondWithJson(writer, http.StatusOK, response[0])
 }
 
-// Create post handler.
+// Create post handler. Images, gifs and videos are sent in the "files" field in the order they should be shown.
+// A single image in the "file" field still works for older clients.
 func (cfg *ApiConfig) CreatePostHandler(writer http.ResponseWriter, request *http.Request) {
-	// Get the bearer token from the request
-	token, tokenErr := GetBearerToken(request.Header)
-	if tokenErr != nil {
-		cfg.LogError(SERVER_MSG_ERROR_GET_BEARER_TOKEN, tokenErr)
-		RespondWithError(writer, http.StatusUnauthorized, CLIENT_MSG_ERROR_UPDATE_USER)
-		return
-	}
-
-	// Verify the bearer token and get the id
-	userId, jwtErr := ValidateJWT(token, cfg.TokenSecret)
-	if jwtErr != nil {
-		cfg.LogError(SERVER_MSG_JWT_VALIDATION_FAILED, jwtErr)
-		RespondWithError(writer, http.StatusUnauthorized, CLIENT_MSG_ERROR_UPDATE_USER)
-		return
-	}
-
-	// Get the user from db
-	postUser, userErr := cfg.Db.GetUserById(request.Context(), userId)
-	if userErr != nil {
-		cfg.LogError(userErr.Error(), userErr)
-		RespondWithError(writer, http.StatusInternalServerError, "Something went wrong while creating post. Please try again.")
-		return
-	}
+	// Get the authenticated user
+	postUser := UserFromContext(request.Context())
+	userId := postUser.ID
 
 	// Validate the request
 	content := request.FormValue("content")
@@ -507,95 +525,102 @@ func (cfg *ApiConfig) CreatePostHandler(writer http.ResponseWriter, request *htt
 		return
 	}
 
-	// If "file" is not null, upload the file to aws and get the download url
+	// If files are sent, upload them to storage and get the download urls
 	const maxMemory = 10 << 30
 	request.Body = http.MaxBytesReader(writer, request.Body, maxMemory)
 	request.ParseMultipartForm(maxMemory)
-	defer request.MultipartForm.RemoveAll()
-
-	file, fileHeader, getFileErr := request.FormFile("file")
-	if getFileErr != nil && getFileErr != http.ErrMissingFile {
-		// Actually return the error because the file is actually provided and there's an error parsing it.
+	if request.MultipartForm != nil {
+		defer request.MultipartForm.RemoveAll()
+	}
 
-		cfg.LogError(getFileErr.Error(), getFileErr)
-		RespondWithError(writer, http.StatusBadRequest, "Error uploading the image for the post.")
+	fileHeaders := postMediaFileHeaders(request)
+	if len(fileHeaders) > app.MAX_POST_MEDIA {
+		RespondWithError(writer, http.StatusBadRequest, CLIENT_MSG_TOO_MANY_POST_MEDIA)
 		return
 	}
-	if getFileErr == nil {
-		defer file.Close()
-	}
 
-	// Add post to the db
-	params := database.CreatePostParams{
-		Content: content,
-		UserID:  userId,
+	// Check what the files are and their sizes before uploading
+	mediaInfos, mediaErrCode, mediaErr := probePostMedia(fileHeaders)
+	if mediaErr != nil {
+		cfg.LogError(mediaErr.Error(), mediaErr)
+		if mediaErrCode == http.StatusBadRequest {
+			RespondWithError(writer, mediaErrCode, mediaErr.Error())
+			return
+		}
+		RespondWithError(writer, mediaErrCode, "Something went wrong while uploading the file. Please try again.")
+		return
 	}
 
-	createdPost, createPostErr := cfg.Db.CreatePost(request.Context(), params)
-	if createPostErr != nil {
-		cfg.LogError(createPostErr.Error(), createPostErr)
-		RespondWithError(writer, http.StatusInternalServerError, "Something went wrong while creating post. Please try again.")
+	// Interests chosen by the author
+	interestIds, errCode, interestsErr := validators.ValidatePostInterestIds(request, cfg.Db)
+	if interestsErr != nil {
+		cfg.LogError(interestsErr.Error(), interestsErr)
+		RespondWithError(writer, errCode, interestsErr.Error())
 		return
 	}
 
-	// Media url. Will be empty by default
-	mediaUrl := ""
-
-	// If getFileErr is nil and fileHeader.size is not zero, upload the file and add download url to post media table
-	if getFileErr == nil && fileHeader.Size != 0 {
-
-		// Check file size before uploading. The file size limit is 1GB
-		const maxIndividualFileSize = 1 << 30
-		if fileHeader.Size > maxIndividualFileSize {
-			cfg.LogError("File too large", errors.New("file too large"))
-			RespondWithError(writer, http.StatusBadRequest, "File too large.")
-			return
-		}
+	// Upload everything before touching the db, so a failed upload leaves nothing behind
+	uploads, uploadErr := cfg.uploadPostMedia(request.Context(), fileHeaders, mediaInfos)
+	if errors.Is(uploadErr, imaging.ErrTooManyPixels) {
+		RespondWithError(writer, http.StatusBadRequest, CLIENT_MSG_IMAGE_TOO_MANY_PIXELS)
+		return
+	}
+	if errors.Is(uploadErr, media.ErrUnsupportedFormat) || errors.Is(uploadErr, media.ErrMalformed) {
+		RespondWithError(writer, http.StatusBadRequest, CLIENT_MSG_UNSUPPORTED_POST_MEDIA)
+		return
+	}
+	if uploadErr != nil {
+		cfg.LogError(uploadErr.Error(), uploadErr)
+		RespondWithError(writer, http.StatusInternalServerError, "Something went wrong while uploading the file. Please try again.")
+		return
+	}
 
-		// Upload the file and get the download url
-		downloadUrl, uploadErr := UploadFileToAWS(
-			"file",
-			"image/",
-			request,
-			cfg.S3Client,
-			cfg.S3Bucket,
-			cfg.S3Region,
-		)
-		if uploadErr != nil {
-			cfg.LogError(uploadErr.Error(), uploadErr)
-			RespondWithError(writer, http.StatusInternalServerError, "Something went wrong while uploading the file. Please try again.")
-			return
+	// Add the post, its media and its interests to the db
+	var createdPost database.Post
+	createdMedia := []database.PostMedium{}
+	txErr := cfg.inTx(request.Context(), func(qtx *database.Queries) error {
+		var createPostErr error
+		createdPost, createPostErr = qtx.CreatePost(request.Context(), database.CreatePostParams{
+			Content: content,
+			UserID:  userId,
+		})
+		if createPostErr != nil {
+			return createPostErr
 		}
 
-		// Update the post_media table.
-		postMediaParams := database.CreatePostMediaParams{
-			MediaUrl:   downloadUrl,
-			OrderIndex: 0,
-			PostID:     createdPost.ID,
-		}
-		_, createPostMediaErr := cfg.Db.CreatePostMedia(request.Context(), postMediaParams)
-		if createPostMediaErr != nil {
-			cfg.LogError(createPostMediaErr.Error(), createPostMediaErr)
-			RespondWithError(writer, http.StatusInternalServerError, "There's something wrong while creating the post. Please try again.")
-			return
+		for index, upload := range uploads {
+			medium, createPostMediaErr := qtx.CreatePostMedia(request.Context(), database.CreatePostMediaParams{
+				MediaUrl:     upload.url,
+				OrderIndex:   int32(index),
+				Width:        upload.width,
+				Height:       upload.height,
+				MediaType:    string(upload.mediaType),
+				DurationMs:   upload.durationMs,
+				PosterUrl:    optionalUrlText(upload.posterUrl),
+				ThumbnailUrl: optionalUrlText(upload.thumbnailUrl),
+				FeedUrl:      optionalUrlText(upload.feedUrl),
+				PostID:       createdPost.ID,
+			})
+			if createPostMediaErr != nil {
+				return createPostMediaErr
+			}
+			createdMedia = append(createdMedia, medium)
 		}
 
-		// update media url
-		mediaUrl = downloadUrl
+		// Tag the post with its interests
+		return cfg.tagPostInterests(request.Context(), qtx, createdPost, interestIds)
+	})
+	if txErr != nil {
+		cfg.LogError(txErr.Error(), txErr)
+		cfg.deletePostMediaUploads(request.Context(), uploads)
+		RespondWithError(writer, http.StatusInternalServerError, "Something went wrong while creating post. Please try again.")
+		return
 	}
 
-	postUserResponse := userWithoutTokenResponse{
-		ID:              postUser.ID,
-		Email:           postUser.Email,
-		UserName:        postUser.UserName,
-		FullName:        postUser.FullName,
-		ProfileImageUrl: postUser.ProfileImageUrl.String,
-		Dob:             FormatNullDobString(postUser.Dob.Time),
-		CreatedAt:       postUser.CreatedAt.Time,
-		UpdatedAt:       postUser.UpdatedAt.Time,
-	}
+	postUserResponse := newPublicUserResponse(postUser)
 
 	// Return the post. Need join statement for post_media and user.
+	media := newPostMediaResponses(createdMedia)
 	response := PostResponse{
 		ID:           createdPost.ID,
 		Content:      createdPost.Content,
@@ -605,8 +630,225 @@ func (cfg *ApiConfig) CreatePostHandler(writer http.ResponseWriter, request *htt
 		CreatedAt:    createdPost.CreatedAt.Time,
 		UpdatedAt:    createdPost.UpdatedAt.Time,
 		User:         postUserResponse,
-		MediaUrl:     mediaUrl,
+		MediaUrl:     firstMediaUrl(media),
+		Media:        media,
 	}
 
 	RespondWithJson(writer, http.StatusCreated, response)
 }
+
+// Tag a post with the interests the author chose.
+// If the author did not choose any, the interests are inferred from the content.
+func (cfg *ApiConfig) tagPostInterests(ctx context.Context, db *database.Queries, post database.Post, interestIds []int64) error {
+	inferred := false
+	if len(interestIds) == 0 {
+		interests, getInterestsErr := db.GetAllInterests(ctx)
+		if getInterestsErr != nil {
+			return getInterestsErr
+		}
+
+		interestNames := map[int64]string{}
+		for _, interest := range interests {
+			interestNames[interest.ID] = interest.Name
+		}
+		interestIds = ranking.InferInterests(post.Content, interestNames)
+		inferred = true
+	}
+	if len(interestIds) == 0 {
+		return nil
+	}
+
+	params := []database.CreatePostInterestsParams{}
+	for _, interestId := range interestIds {
+		params = append(params, database.CreatePostInterestsParams{
+			PostID:     post.ID,
+			InterestID: interestId,
+			Inferred:   inferred,
+			CreatedAt:  post.CreatedAt,
+		})
+	}
+	_, err := db.CreatePostInterests(ctx, params)
+	return err
+}
+
+// Respond with a page of posts fetched with cursor
+func (cfg *ApiConfig) respondWithPostPage(writer http.ResponseWriter, request *http.Request, path string, posts []database.VisiblePost, cursor *Cursor) {
+	page := NewCursorPage(posts, app.PAGE_SIZE, cursor, func(post database.VisiblePost) Cursor {
+		return Cursor{CreatedAt: post.CreatedAt.Time, ID: post.ID}
+	})
+
+	postList, mediaErr := cfg.newPostResponses(request.Context(), page.Items)
+	if mediaErr != nil {
+		cfg.LogError(mediaErr.Error(), mediaErr)
+		RespondWithError(writer, http.StatusInternalServerError, "Error retrieving all posts")
+		return
+	}
+
+	response := PostListResponse{
+		Data: postList,
+		Meta: cfg.cursorMetaResponse(path, page.NextCursor, page.PrevCursor),
+	}
+
+	RespondWithJson(writer, http.StatusOK, response)
+}
+
+// Respond with 404 when the post does not exist or its author is private and not followed by the authenticated user
+func (cfg *ApiConfig) postIsVisible(writer http.ResponseWriter, request *http.Request, postId int64, errorMsg string) bool {
+	visible, err := cfg.Db.IsPostVisible(request.Context(), database.IsPostVisibleParams{
+		PostID:   postId,
+		ViewerID: UserIdFromContext(request.Context()),
+	})
+	if err != nil {
+		cfg.LogError(err.Error(), err)
+		RespondWithError(writer, http.StatusInternalServerError, errorMsg)
+		return false
+	}
+	if !visible {
+		RespondWithError(writer, http.StatusNotFound, CLIENT_MSG_POST_NOT_FOUND)
+		return false
+	}
+
+	return true
+}
+
+// Respond with 403 when the author of the post blocked the authenticated user
+func (cfg *ApiConfig) postAuthorHasNotBlocked(writer http.ResponseWriter, request *http.Request, postId int64, errorMsg string) bool {
+	blocked, err := cfg.Db.IsBlockedByPostAuthor(request.Context(), database.IsBlockedByPostAuthorParams{
+		PostID: postId,
+		UserID: UserIdFromContext(request.Context()),
+	})
+	if err != nil {
+		cfg.LogError(err.Error(), err)
+		RespondWithError(writer, http.StatusInternalServerError, errorMsg)
+		return false
+	}
+	if blocked {
+		RespondWithError(writer, http.StatusForbidden, CLIENT_MSG_BLOCKED)
+		return false
+	}
+
+	return true
+}
+
+// Parse the post_id path value
+func postIdFromPath(request *http.Request) (int64, error) {
+	return strconv.ParseInt(request.PathValue("post_id"), 10, 64)
+}
+
+// Get the page number from the page query parameter. Defaults to 1 if it is missing or invalid.
+// Pages whose offset does not fit in the int32 of the queries are an error.
+func pageFromRequest(request *http.Request) (int, error) {
+	page, err := strconv.Atoi(request.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		return 1, nil
+	}
+	if page > math.MaxInt32/app.PAGE_SIZE {
+		return 0, fmt.Errorf("page %v is too large", page)
+	}
+	return page, nil
+}
+
+// Convert posts from the db together with their media
+func (cfg *ApiConfig) newPostResponses(ctx context.Context, posts []database.VisiblePost) ([]PostResponse, error) {
+	postList := []PostResponse{}
+	if len(posts) == 0 {
+		return postList, nil
+	}
+
+	postIds := []int64{}
+	for _, post := range posts {
+		postIds = append(postIds, post.ID)
+	}
+	media, mediaErr := cfg.Db.GetMediaForPosts(ctx, postIds)
+	if mediaErr != nil {
+		return nil, mediaErr
+	}
+	mediaByPost := map[int64][]database.PostMedium{}
+	for _, medium := range media {
+		mediaByPost[medium.PostID] = append(mediaByPost[medium.PostID], medium)
+	}
+
+	for _, post := range posts {
+		postList = append(postList, newPostResponse(post, mediaByPost[post.ID]))
+	}
+	return postList, nil
+}
+
+// Convert a post from the db. All the post queries select from visible_posts.
+// media must be in order.
+func newPostResponse(postFromDb database.VisiblePost, mediaFromDb []database.PostMedium) PostResponse {
+	media := newPostMediaResponses(mediaFromDb)
+
+	// Only edits change updated_at, so a post is edited when it was updated after it was created
+	return PostResponse{
+		ID:           postFromDb.ID,
+		Content:      postFromDb.Content,
+		MediaUrl:     firstMediaUrl(media),
+		Media:        media,
+		LikedByUser:  postFromDb.LikedByUser,
+		LikeCount:    int(postFromDb.LikeCount),
+		CommentCount: int(postFromDb.CommentCount),
+		Edited:       postFromDb.UpdatedAt.Time.After(postFromDb.CreatedAt.Time),
+		CreatedAt:    postFromDb.CreatedAt.Time,
+		UpdatedAt:    postFromDb.UpdatedAt.Time,
+		User: newPublicUserResponseFromColumns(
+			postFromDb.AuthorID,
+			postFromDb.AuthorUserName,
+			postFromDb.AuthorFullName,
+			postFromDb.AuthorCreatedAt,
+			postFromDb.AuthorProfileImageUrl,
+			postFromDb.AuthorProfileImageFeedUrl,
+			postFromDb.AuthorProfileImageThumbnailUrl,
+		).withFollowStatus(postFromDb.AuthorIsFollowing, postFromDb.AuthorFollowsYou),
+	}
+}
+
+// Convert the media of a post, which must be in order
+func newPostMediaResponses(mediaFromDb []database.PostMedium) []PostMediaResponse {
+	responses := []PostMediaResponse{}
+	for index, medium := range mediaFromDb {
+		response := PostMediaResponse{
+			Url:        medium.MediaUrl,
+			Type:       medium.MediaType,
+			Width:      int(medium.Width.Int32),
+			Height:     int(medium.Height.Int32),
+			DurationMs: int(medium.DurationMs.Int32),
+			PosterUrl:  medium.PosterUrl.String,
+			Order:      index,
+		}
+		if medium.MediaType == string(media.KindImage) {
+			response.Variants = newImageVariantsResponse(medium.MediaUrl, medium.FeedUrl, medium.ThumbnailUrl)
+		}
+		responses = append(responses, response)
+	}
+	return responses
+}
+
+// Url of the first media, empty if there is none
+func firstMediaUrl(media []PostMediaResponse) string {
+	if len(media) == 0 {
+		return ""
+	}
+	return media[0].Url
+}
+
+// Convert a comment from the db. All the comment list queries select the same columns, so their rows convert to GetCommentsForPostRow.
+func newCommentResponse(commentFromDb database.GetCommentsForPostRow) CommentResponse {
+	return CommentResponse{
+		ID:        commentFromDb.ID,
+		Content:   commentFromDb.Content,
+		CreatedAt: commentFromDb.CreatedAt.Time,
+		UpdatedAt: commentFromDb.UpdatedAt.Time,
+		PostId:    commentFromDb.PostID,
+		UserId:    commentFromDb.UserID,
+		User: newPublicUserResponseFromColumns(
+			commentFromDb.AuthorID,
+			commentFromDb.AuthorUserName,
+			commentFromDb.AuthorFullName,
+			commentFromDb.AuthorCreatedAt,
+			commentFromDb.AuthorProfileImageUrl,
+			commentFromDb.AuthorProfileImageFeedUrl,
+			commentFromDb.AuthorProfileImageThumbnailUrl,
+		).withFollowStatus(commentFromDb.AuthorIsFollowing, commentFromDb.AuthorFollowsYou),
+	}
+}
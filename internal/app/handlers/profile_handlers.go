@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/app"
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/app/imaging"
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/app/media"
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/app/validators"
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/database"
+)
+
+// Update the profile of the authenticated user with a JSON merge patch.
+// Only the fields that are sent change. Sending null for dob removes it.
+func (cfg *ApiConfig) PatchMeHandler(writer http.ResponseWriter, request *http.Request) {
+	const maxBodySize = 1 << 20
+	body, readErr := io.ReadAll(http.MaxBytesReader(writer, request.Body, maxBodySize))
+	if readErr != nil {
+		RespondWithError(writer, http.StatusBadRequest, readErr.Error())
+		return
+	}
+
+	patch, patchErr := validators.ParseProfilePatch(body, app.TIME_PARSE_LAYOUT, time.Now())
+	if patchErr != nil {
+		RespondWithError(writer, http.StatusBadRequest, patchErr.Error())
+		return
+	}
+
+	user := UserFromContext(request.Context())
+
+	// Nothing to change
+	if patch.IsEmpty() {
+		cfg.respondWithMe(writer, request, user, CLIENT_MSG_ERROR_UPDATE_USER)
+		return
+	}
+
+	params := database.UpdateUserProfileParams{
+		ID:       user.ID,
+		ClearDob: patch.ClearDob,
+	}
+	if patch.FullName != nil {
+		params.FullName = pgtype.Text{String: *patch.FullName, Valid: true}
+	}
+	if patch.Dob != nil {
+		params.Dob = pgtype.Date{Time: *patch.Dob, Valid: true}
+	}
+
+	updatedUser, updateErr := cfg.updateProfile(request.Context(), user, params, patch.UserName)
+	if updateErr != nil {
+		cfg.respondWithUpdateProfileError(writer, user, updateErr)
+		return
+	}
+
+	cfg.respondWithMe(writer, request, updatedUser, CLIENT_MSG_ERROR_UPDATE_USER)
+}
+
+// Upload a new profile picture for the authenticated user. The old one is deleted.
+func (cfg *ApiConfig) PutAvatarHandler(writer http.ResponseWriter, request *http.Request) {
+	const maxMemory = 10 << 20
+	request.Body = http.MaxBytesReader(writer, request.Body, maxMemory)
+
+	if _, _, fileErr := request.FormFile("profile"); fileErr != nil {
+		if errors.Is(fileErr, http.ErrMissingFile) {
+			RespondWithError(writer, http.StatusBadRequest, CLIENT_MSG_PROFILE_PICTURE_CANNOT_BE_EMPTY)
+			return
+		}
+		RespondWithError(writer, http.StatusBadRequest, fileErr.Error())
+		return
+	}
+
+	updatedUser, avatarErr := cfg.replaceProfileImage(request, UserFromContext(request.Context()))
+	if avatarErr != nil {
+		cfg.respondWithProfileImageError(writer, avatarErr)
+		return
+	}
+
+	cfg.respondWithMe(writer, request, updatedUser, CLIENT_MSG_ERROR_UPLOADING_PROFILE_PICTURE)
+}
+
+// Remove the profile picture of the authenticated user
+func (cfg *ApiConfig) DeleteAvatarHandler(writer http.ResponseWriter, request *http.Request) {
+	user := UserFromContext(request.Context())
+
+	updatedUser, updateErr := cfg.Db.SetUserProfileImage(request.Context(), database.SetUserProfileImageParams{
+		ID:              user.ID,
+		ProfileImageUrl: pgtype.Text{},
+	})
+	if updateErr != nil {
+		cfg.LogError(SERVER_MSG_UPDATE_USER_ERROR, updateErr)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_DELETE_PROFILE_PICTURE)
+		return
+	}
+
+	cfg.deleteOldProfileImage(request, user)
+
+	cfg.respondWithMe(writer, request, updatedUser, CLIENT_MSG_ERROR_DELETE_PROFILE_PICTURE)
+}
+
+// Process the profile file of the request, upload its renditions, save their urls for the user and delete the old picture.
+// The multipart form must be parsed before calling this. Errors of imaging.Process are returned as they are.
+func (cfg *ApiConfig) replaceProfileImage(request *http.Request, user database.User) (database.User, error) {
+	_, fileHeader, fileErr := request.FormFile("profile")
+	if fileErr != nil {
+		return database.User{}, fileErr
+	}
+
+	image, uploadErr := cfg.uploadProcessedImage(request.Context(), fileHeader, "profiles")
+	if uploadErr != nil {
+		return database.User{}, uploadErr
+	}
+
+	updatedUser, updateErr := cfg.Db.SetUserProfileImage(request.Context(), database.SetUserProfileImageParams{
+		ID:                       user.ID,
+		ProfileImageUrl:          optionalUrlText(image.fullUrl),
+		ProfileImageThumbnailUrl: optionalUrlText(image.thumbnailUrl),
+		ProfileImageFeedUrl:      optionalUrlText(image.feedUrl),
+	})
+	if updateErr != nil {
+		// The new picture is not used by anyone
+		cfg.deleteUploadedImage(request.Context(), image)
+		return database.User{}, updateErr
+	}
+
+	cfg.deleteOldProfileImage(request, user)
+
+	return updatedUser, nil
+}
+
+// Respond to a failed replaceProfileImage. Images that cannot be used are the client's fault.
+func (cfg *ApiConfig) respondWithProfileImageError(writer http.ResponseWriter, avatarErr error) {
+	switch {
+	case errors.Is(avatarErr, imaging.ErrTooManyPixels):
+		RespondWithError(writer, http.StatusBadRequest, CLIENT_MSG_IMAGE_TOO_MANY_PIXELS)
+	case errors.Is(avatarErr, media.ErrUnsupportedFormat), errors.Is(avatarErr, media.ErrMalformed):
+		RespondWithError(writer, http.StatusBadRequest, CLIENT_MSG_UNSUPPORTED_PROFILE_PICTURE)
+	default:
+		cfg.LogError(SERVER_MSG_ERROR_UPLOADING_PHOTO, avatarErr)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_UPLOADING_PROFILE_PICTURE)
+	}
+}
+
+// Delete the profile picture the user had before with its renditions. The user is already updated, so a failure is only logged.
+func (cfg *ApiConfig) deleteOldProfileImage(request *http.Request, user database.User) {
+	if !user.ProfileImageUrl.Valid {
+		return
+	}
+	cfg.deleteUploadedImage(request.Context(), uploadedImage{
+		fullUrl:      user.ProfileImageUrl.String,
+		feedUrl:      user.ProfileImageFeedUrl.String,
+		thumbnailUrl: user.ProfileImageThumbnailUrl.String,
+	})
+}
+
+// Respond with the user as the user themselves sees it
+func (cfg *ApiConfig) respondWithMe(writer http.ResponseWriter, request *http.Request, user database.User, errorMsg string) {
+	interests, interestsErr := getInterestsForUser(user.ID, request, cfg.Db)
+	if interestsErr != nil {
+		cfg.LogError(SERVER_MSG_GETTING_INTEREST_FOR_USER_FAILED, interestsErr)
+		RespondWithError(writer, http.StatusInternalServerError, errorMsg)
+		return
+	}
+
+	RespondWithJson(writer, http.StatusOK, newPrivateUserResponse(user, interests))
+}
@@ -0,0 +1,224 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/app"
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/database"
+)
+
+type refreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type tokenPairResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// Create a new refresh token session for the user and return the raw token.
+// Pass an empty familyId to start a new session family (login / register).
+// Rotated tokens keep the family id of the token they replace.
+func (cfg *ApiConfig) createRefreshToken(ctx context.Context, userId int64, familyId string) (string, error) {
+	if familyId == "" {
+		newFamilyId, familyIdErr := MakeRandomToken()
+		if familyIdErr != nil {
+			return "", familyIdErr
+		}
+		familyId = newFamilyId
+	}
+
+	refreshToken, tokenErr := MakeRandomToken()
+	if tokenErr != nil {
+		return "", tokenErr
+	}
+
+	params := database.CreateSessionParams{
+		UserID:    userId,
+		FamilyID:  familyId,
+		TokenHash: HashToken(refreshToken),
+		ExpiresAt: pgtype.Timestamp{
+			Time:  time.Now().Add(app.REFRESH_TOKEN_EXPIRE_TIME),
+			Valid: true,
+		},
+	}
+	if _, createSessionErr := cfg.Db.CreateSession(ctx, params); createSessionErr != nil {
+		return "", createSessionErr
+	}
+
+	return refreshToken, nil
+}
+
+// Exchange a refresh token for a new access token and refresh token.
+func (cfg *ApiConfig) RefreshTokenHandler(writer http.ResponseWriter, request *http.Request) {
+	// Decode the request
+	decoder := json.NewDecoder(request.Body)
+	refreshRequest := refreshTokenRequest{}
+	if decodeErr := decoder.Decode(&refreshRequest); decodeErr != nil {
+		RespondWithError(writer, http.StatusBadRequest, decodeErr.Error())
+		return
+	}
+
+	if refreshRequest.RefreshToken == "" {
+		RespondWithError(writer, http.StatusBadRequest, CLIENT_MSG_REFRESH_TOKEN_CANNOT_BE_EMPTY)
+		return
+	}
+
+	// Find the session by the hashed token
+	session, getSessionErr := cfg.Db.GetSessionByTokenHash(request.Context(), HashToken(refreshRequest.RefreshToken))
+	if getSessionErr != nil {
+		if errors.Is(getSessionErr, sql.ErrNoRows) {
+			RespondWithError(writer, http.StatusUnauthorized, CLIENT_MSG_INVALID_REFRESH_TOKEN)
+			return
+		}
+		cfg.LogError(SERVER_MSG_GET_SESSION_FAILED, getSessionErr)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_REFRESH_TOKEN)
+		return
+	}
+
+	// The whole family has already been revoked
+	if session.RevokedAt.Valid {
+		RespondWithError(writer, http.StatusUnauthorized, CLIENT_MSG_INVALID_REFRESH_TOKEN)
+		return
+	}
+
+	// The token has already been exchanged once. Someone is replaying it, so revoke the whole family.
+	if session.UsedAt.Valid {
+		cfg.revokeReusedSessionFamily(request.Context(), session.FamilyID)
+		RespondWithError(writer, http.StatusUnauthorized, CLIENT_MSG_INVALID_REFRESH_TOKEN)
+		return
+	}
+
+	if time.Now().After(session.ExpiresAt.Time) {
+		RespondWithError(writer, http.StatusUnauthorized, CLIENT_MSG_INVALID_REFRESH_TOKEN)
+		return
+	}
+
+	// Mark the token as used. If no rows are affected, another request exchanged it first.
+	affectedRows, markUsedErr := cfg.Db.MarkSessionUsed(request.Context(), session.ID)
+	if markUsedErr != nil {
+		cfg.LogError(SERVER_MSG_GET_SESSION_FAILED, markUsedErr)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_REFRESH_TOKEN)
+		return
+	}
+	if affectedRows == 0 {
+		cfg.revokeReusedSessionFamily(request.Context(), session.FamilyID)
+		RespondWithError(writer, http.StatusUnauthorized, CLIENT_MSG_INVALID_REFRESH_TOKEN)
+		return
+	}
+
+	// Get the current token version so the new access token is not born revoked
+	tokenVersion, tokenVersionErr := cfg.Db.GetUserTokenVersion(request.Context(), session.UserID)
+	if tokenVersionErr != nil {
+		cfg.LogError(SERVER_MSG_GET_TOKEN_VERSION_FAILED, tokenVersionErr)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_REFRESH_TOKEN)
+		return
+	}
+
+	// Create JWT
+	accessToken, jwtErr := MakeJWT(session.UserID, tokenVersion, cfg.Keys, app.JWT_EXPIRE_TIME)
+	if jwtErr != nil {
+		cfg.LogError(SERVER_MSG_MAKE_JWT_FAILED, jwtErr)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_REFRESH_TOKEN)
+		return
+	}
+
+	// Rotate the refresh token within the same family
+	refreshToken, refreshTokenErr := cfg.createRefreshToken(request.Context(), session.UserID, session.FamilyID)
+	if refreshTokenErr != nil {
+		cfg.LogError(SERVER_MSG_CREATE_SESSION_FAILED, refreshTokenErr)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_REFRESH_TOKEN)
+		return
+	}
+
+	response := tokenPairResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+	}
+
+	RespondWithJson(writer, http.StatusOK, response)
+}
+
+// A used refresh token was presented again. Revoke every refresh token in its family.
+func (cfg *ApiConfig) revokeReusedSessionFamily(ctx context.Context, familyId string) {
+	cfg.LogError(SERVER_MSG_REFRESH_TOKEN_REUSED, errors.New("refresh token reused"))
+	if revokeErr := cfg.Db.RevokeSessionFamily(ctx, familyId); revokeErr != nil {
+		cfg.LogError(SERVER_MSG_REVOKE_SESSION_FAILED, revokeErr)
+	}
+}
+
+// Logout. Revokes the current access token.
+// If a refresh token is sent in the body, its whole session family is revoked as well.
+func (cfg *ApiConfig) LogoutHandler(writer http.ResponseWriter, request *http.Request) {
+	// Get the claims of the current access token
+	claims := ClaimsFromContext(request.Context())
+	userId := UserIdFromContext(request.Context())
+
+	// The refresh token is optional
+	logoutRequest := refreshTokenRequest{}
+	decoder := json.NewDecoder(request.Body)
+	if decodeErr := decoder.Decode(&logoutRequest); decodeErr != nil && !errors.Is(decodeErr, io.EOF) {
+		RespondWithError(writer, http.StatusBadRequest, decodeErr.Error())
+		return
+	}
+
+	// Revoke the access token
+	if revokeErr := cfg.Revocations.RevokeToken(request.Context(), claims); revokeErr != nil {
+		cfg.LogError(SERVER_MSG_REVOKE_TOKEN_FAILED, revokeErr)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_LOGOUT)
+		return
+	}
+
+	// Revoke the refresh token family
+	if logoutRequest.RefreshToken != "" {
+		session, getSessionErr := cfg.Db.GetSessionByTokenHash(request.Context(), HashToken(logoutRequest.RefreshToken))
+		if getSessionErr != nil && !errors.Is(getSessionErr, sql.ErrNoRows) {
+			cfg.LogError(SERVER_MSG_GET_SESSION_FAILED, getSessionErr)
+			RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_LOGOUT)
+			return
+		}
+
+		// Only revoke sessions that belong to the user logging out
+		if getSessionErr == nil && session.UserID == userId {
+			if revokeErr := cfg.Db.RevokeSessionFamily(request.Context(), session.FamilyID); revokeErr != nil {
+				cfg.LogError(SERVER_MSG_REVOKE_SESSION_FAILED, revokeErr)
+				RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_LOGOUT)
+				return
+			}
+		}
+	}
+
+	// Return empty response
+	writer.WriteHeader(http.StatusOK)
+}
+
+// Logout from every device. Bumps the user's token version and revokes every refresh token.
+func (cfg *ApiConfig) LogoutAllHandler(writer http.ResponseWriter, request *http.Request) {
+	// Get the authenticated user id
+	userId := UserIdFromContext(request.Context())
+
+	if revokeErr := cfg.revokeAllTokensForUser(request.Context(), userId); revokeErr != nil {
+		cfg.LogError(SERVER_MSG_REVOKE_TOKEN_FAILED, revokeErr)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_LOGOUT)
+		return
+	}
+
+	// Return empty response
+	writer.WriteHeader(http.StatusOK)
+}
+
+// Revoke every access token and refresh token of the user.
+func (cfg *ApiConfig) revokeAllTokensForUser(ctx context.Context, userId int64) error {
+	if err := cfg.Revocations.RevokeAllForUser(ctx, userId); err != nil {
+		return err
+	}
+
+	return cfg.Db.RevokeAllSessionsForUser(ctx, userId)
+}
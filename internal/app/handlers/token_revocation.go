@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/app"
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/database"
+)
+
+type revokedJtiEntry struct {
+	revoked   bool
+	checkedAt time.Time
+}
+
+type tokenVersionEntry struct {
+	version   int32
+	checkedAt time.Time
+}
+
+// Caches revocation lookups so that every authenticated request does not pay an extra db round trip.
+// Revocations made through this cache are visible immediately on this server.
+// Revocations made by other servers are picked up once the cached entry is older than ttl.
+type TokenRevocationCache struct {
+	db        *database.Queries
+	ttl       time.Duration
+	mu        sync.Mutex
+	jtis      map[string]revokedJtiEntry
+	versions  map[int64]tokenVersionEntry
+	lastSweep time.Time
+}
+
+func NewTokenRevocationCache(db *database.Queries, ttl time.Duration) *TokenRevocationCache {
+	return &TokenRevocationCache{
+		db:        db,
+		ttl:       ttl,
+		jtis:      map[string]revokedJtiEntry{},
+		versions:  map[int64]tokenVersionEntry{},
+		lastSweep: time.Now(),
+	}
+}
+
+// Check whether the token id was revoked or the token version is outdated.
+func (cache *TokenRevocationCache) IsRevoked(ctx context.Context, claims *AccessTokenClaims) (bool, error) {
+	userId, parseErr := claims.UserId()
+	if parseErr != nil {
+		return true, parseErr
+	}
+
+	currentVersion, versionErr := cache.TokenVersion(ctx, userId)
+	if versionErr != nil {
+		return true, versionErr
+	}
+	if claims.TokenVersion < currentVersion {
+		return true, nil
+	}
+
+	return cache.isJtiRevoked(ctx, claims.ID)
+}
+
+// Get the current token version of the user.
+func (cache *TokenRevocationCache) TokenVersion(ctx context.Context, userId int64) (int32, error) {
+	cache.mu.Lock()
+	entry, ok := cache.versions[userId]
+	cache.mu.Unlock()
+	if ok && time.Since(entry.checkedAt) < cache.ttl {
+		return entry.version, nil
+	}
+
+	version, err := cache.db.GetUserTokenVersion(ctx, userId)
+	if err != nil {
+		return 0, err
+	}
+
+	cache.mu.Lock()
+	cache.versions[userId] = tokenVersionEntry{version: version, checkedAt: time.Now()}
+	cache.sweepLocked()
+	cache.mu.Unlock()
+
+	return version, nil
+}
+
+// Revoke a single access token. Used by logout.
+func (cache *TokenRevocationCache) RevokeToken(ctx context.Context, claims *AccessTokenClaims) error {
+	userId, parseErr := claims.UserId()
+	if parseErr != nil {
+		return parseErr
+	}
+
+	params := database.CreateRevokedTokenParams{
+		Jti:    claims.ID,
+		UserID: userId,
+		ExpiresAt: pgtype.Timestamp{
+			Time:  claims.ExpiresAt.Time,
+			Valid: true,
+		},
+	}
+	if err := cache.db.CreateRevokedToken(ctx, params); err != nil {
+		return err
+	}
+
+	// Tokens that have expired anyway do not need to be remembered
+	if err := cache.db.DeleteExpiredRevokedTokens(ctx); err != nil {
+		return err
+	}
+
+	cache.mu.Lock()
+	cache.jtis[claims.ID] = revokedJtiEntry{revoked: true, checkedAt: time.Now()}
+	cache.mu.Unlock()
+
+	return nil
+}
+
+// Revoke every access token of the user by bumping the token version.
+func (cache *TokenRevocationCache) RevokeAllForUser(ctx context.Context, userId int64) error {
+	version, err := cache.db.IncrementUserTokenVersion(ctx, userId)
+	if err != nil {
+		return err
+	}
+
+	cache.mu.Lock()
+	cache.versions[userId] = tokenVersionEntry{version: version, checkedAt: time.Now()}
+	cache.mu.Unlock()
+
+	return nil
+}
+
+func (cache *TokenRevocationCache) isJtiRevoked(ctx context.Context, jti string) (bool, error) {
+	cache.mu.Lock()
+	entry, ok := cache.jtis[jti]
+	cache.mu.Unlock()
+	// Revoked tokens stay revoked, so only the "not revoked" entries need to expire.
+	if ok && (entry.revoked || time.Since(entry.checkedAt) < cache.ttl) {
+		return entry.revoked, nil
+	}
+
+	revoked, err := cache.db.IsTokenRevoked(ctx, jti)
+	if err != nil {
+		return true, err
+	}
+
+	cache.mu.Lock()
+	cache.jtis[jti] = revokedJtiEntry{revoked: revoked, checkedAt: time.Now()}
+	cache.sweepLocked()
+	cache.mu.Unlock()
+
+	return revoked, nil
+}
+
+// Drop stale entries so the maps do not grow forever. Must be called with mu held.
+func (cache *TokenRevocationCache) sweepLocked() {
+	if time.Since(cache.lastSweep) < cache.ttl {
+		return
+	}
+
+	for jti, entry := range cache.jtis {
+		// Keep revoked entries around until the access token itself would have expired
+		keepFor := cache.ttl
+		if entry.revoked {
+			keepFor = app.JWT_EXPIRE_TIME
+		}
+		if time.Since(entry.checkedAt) >= keepFor {
+			delete(cache.jtis, jti)
+		}
+	}
+	for userId, entry := range cache.versions {
+		if time.Since(entry.checkedAt) >= cache.ttl {
+			delete(cache.versions, userId)
+		}
+	}
+	cache.lastSweep = time.Now()
+}
@@ -0,0 +1,23 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/database"
+)
+
+// Run fn in a transaction. Everything fn did is rolled back if it returns an error.
+func (cfg *ApiConfig) inTx(ctx context.Context, fn func(qtx *database.Queries) error) error {
+	tx, beginErr := cfg.Pool.Begin(ctx)
+	if beginErr != nil {
+		return beginErr
+	}
+	// Does nothing once the transaction is committed
+	defer tx.Rollback(ctx)
+
+	if err := fn(cfg.Db.WithTx(tx)); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
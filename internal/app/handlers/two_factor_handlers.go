@@ -0,0 +1,335 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/app"
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/app/totp"
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/database"
+)
+
+type twoFactorEnrollResponse struct {
+	Secret     string `json:"secret"`
+	OtpauthUri string `json:"otpauth_uri"`
+}
+
+type twoFactorCodeRequest struct {
+	Code string `json:"code"`
+}
+
+type recoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type twoFactorDisableRequest struct {
+	Password     string `json:"password"`
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recovery_code"`
+}
+
+type twoFactorLoginRequest struct {
+	MfaToken     string `json:"mfa_token"`
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recovery_code"`
+}
+
+// Start 2fa setup. Stores a new secret that only becomes active after ConfirmTwoFactorHandler.
+func (cfg *ApiConfig) EnrollTwoFactorHandler(writer http.ResponseWriter, request *http.Request) {
+	user := UserFromContext(request.Context())
+	if user.TotpEnabledAt.Valid {
+		RespondWithError(writer, http.StatusConflict, CLIENT_MSG_TWO_FACTOR_ALREADY_ENABLED)
+		return
+	}
+
+	secret, secretErr := totp.GenerateSecret()
+	if secretErr != nil {
+		cfg.LogError(SERVER_MSG_TWO_FACTOR_FAILED, secretErr)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_TWO_FACTOR)
+		return
+	}
+
+	params := database.SetUserTotpSecretParams{
+		ID: user.ID,
+		TotpSecret: pgtype.Text{
+			String: secret,
+			Valid:  true,
+		},
+	}
+	if setErr := cfg.Db.SetUserTotpSecret(request.Context(), params); setErr != nil {
+		cfg.LogError(SERVER_MSG_TWO_FACTOR_FAILED, setErr)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_TWO_FACTOR)
+		return
+	}
+
+	response := twoFactorEnrollResponse{
+		Secret:     secret,
+		OtpauthUri: totp.URI(app.TOTP_ISSUER, user.Email, secret),
+	}
+	RespondWithJson(writer, http.StatusOK, response)
+}
+
+// Finish 2fa setup with a code from the authenticator app. Responds with the recovery codes, which are only shown once.
+func (cfg *ApiConfig) ConfirmTwoFactorHandler(writer http.ResponseWriter, request *http.Request) {
+	// Decode the request
+	decoder := json.NewDecoder(request.Body)
+	confirmRequest := twoFactorCodeRequest{}
+	if decodeErr := decoder.Decode(&confirmRequest); decodeErr != nil {
+		RespondWithError(writer, http.StatusBadRequest, decodeErr.Error())
+		return
+	}
+
+	user := UserFromContext(request.Context())
+	if user.TotpEnabledAt.Valid {
+		RespondWithError(writer, http.StatusConflict, CLIENT_MSG_TWO_FACTOR_ALREADY_ENABLED)
+		return
+	}
+	if !user.TotpSecret.Valid {
+		RespondWithError(writer, http.StatusBadRequest, CLIENT_MSG_TWO_FACTOR_NOT_ENROLLED)
+		return
+	}
+
+	// Check the code
+	valid, verifyErr := cfg.verifyTotpCode(request.Context(), user, confirmRequest.Code)
+	if verifyErr != nil {
+		cfg.LogError(SERVER_MSG_TWO_FACTOR_FAILED, verifyErr)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_TWO_FACTOR)
+		return
+	}
+	if !valid {
+		RespondWithError(writer, http.StatusBadRequest, CLIENT_MSG_INVALID_TWO_FACTOR_CODE)
+		return
+	}
+
+	// Create the recovery codes and store only their hashes
+	recoveryCodes, codesErr := totp.GenerateRecoveryCodes(app.RECOVERY_CODE_COUNT)
+	if codesErr != nil {
+		cfg.LogError(SERVER_MSG_TWO_FACTOR_FAILED, codesErr)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_TWO_FACTOR)
+		return
+	}
+
+	if deleteErr := cfg.Db.DeleteRecoveryCodesForUser(request.Context(), user.ID); deleteErr != nil {
+		cfg.LogError(SERVER_MSG_TWO_FACTOR_FAILED, deleteErr)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_TWO_FACTOR)
+		return
+	}
+
+	recoveryCodeParamsSlice := []database.CreateRecoveryCodesParams{}
+	for _, recoveryCode := range recoveryCodes {
+		params := database.CreateRecoveryCodesParams{
+			UserID:   user.ID,
+			CodeHash: HashToken(totp.NormalizeRecoveryCode(recoveryCode)),
+			CreatedAt: pgtype.Timestamp{
+				Time:  cfg.now(),
+				Valid: true,
+			},
+		}
+		recoveryCodeParamsSlice = append(recoveryCodeParamsSlice, params)
+	}
+	if _, createErr := cfg.Db.CreateRecoveryCodes(request.Context(), recoveryCodeParamsSlice); createErr != nil {
+		cfg.LogError(SERVER_MSG_TWO_FACTOR_FAILED, createErr)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_TWO_FACTOR)
+		return
+	}
+
+	if enableErr := cfg.Db.EnableUserTotp(request.Context(), user.ID); enableErr != nil {
+		cfg.LogError(SERVER_MSG_TWO_FACTOR_FAILED, enableErr)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_TWO_FACTOR)
+		return
+	}
+
+	RespondWithJson(writer, http.StatusOK, recoveryCodesResponse{RecoveryCodes: recoveryCodes})
+}
+
+// Turn 2fa off. Needs the password and either a code or a recovery code.
+func (cfg *ApiConfig) DisableTwoFactorHandler(writer http.ResponseWriter, request *http.Request) {
+	// Decode the request
+	decoder := json.NewDecoder(request.Body)
+	disableRequest := twoFactorDisableRequest{}
+	if decodeErr := decoder.Decode(&disableRequest); decodeErr != nil {
+		RespondWithError(writer, http.StatusBadRequest, decodeErr.Error())
+		return
+	}
+
+	if disableRequest.Password == "" {
+		RespondWithError(writer, http.StatusBadRequest, CLIENT_MSG_PASSWORD_CANNOT_BE_EMPTY)
+		return
+	}
+
+	user := UserFromContext(request.Context())
+	if !user.TotpEnabledAt.Valid {
+		RespondWithError(writer, http.StatusConflict, CLIENT_MSG_TWO_FACTOR_NOT_ENABLED)
+		return
+	}
+
+	// Verify password
+	if checkPassErr := CheckPasswordHash(user.HashedPassword, disableRequest.Password); checkPassErr != nil {
+		RespondWithError(writer, http.StatusUnauthorized, CLIENT_MSG_INCORRECT_EMAIL_OR_PASSWORD)
+		return
+	}
+
+	// Verify the second factor
+	valid, verifyErr := cfg.verifySecondFactor(request.Context(), user, disableRequest.Code, disableRequest.RecoveryCode)
+	if verifyErr != nil {
+		cfg.LogError(SERVER_MSG_TWO_FACTOR_FAILED, verifyErr)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_TWO_FACTOR)
+		return
+	}
+	if !valid {
+		RespondWithError(writer, http.StatusUnauthorized, CLIENT_MSG_INVALID_TWO_FACTOR_CODE)
+		return
+	}
+
+	if disableErr := cfg.Db.DisableUserTotp(request.Context(), user.ID); disableErr != nil {
+		cfg.LogError(SERVER_MSG_TWO_FACTOR_FAILED, disableErr)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_TWO_FACTOR)
+		return
+	}
+
+	if deleteErr := cfg.Db.DeleteRecoveryCodesForUser(request.Context(), user.ID); deleteErr != nil {
+		cfg.LogError(SERVER_MSG_TWO_FACTOR_FAILED, deleteErr)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_TWO_FACTOR)
+		return
+	}
+
+	// Return empty response
+	writer.WriteHeader(http.StatusOK)
+}
+
+// Finish a login that LoginHandler answered with mfa_required.
+func (cfg *ApiConfig) LoginTwoFactorHandler(writer http.ResponseWriter, request *http.Request) {
+	// Decode the request
+	decoder := json.NewDecoder(request.Body)
+	loginRequest := twoFactorLoginRequest{}
+	if decodeErr := decoder.Decode(&loginRequest); decodeErr != nil {
+		RespondWithError(writer, http.StatusBadRequest, decodeErr.Error())
+		return
+	}
+
+	// Check the mfa token from LoginHandler
+	claims, mfaErr := ValidateMFAToken(loginRequest.MfaToken, cfg.Keys)
+	if mfaErr != nil {
+		RespondWithError(writer, http.StatusUnauthorized, CLIENT_MSG_INVALID_MFA_TOKEN)
+		return
+	}
+
+	userId, userIdErr := claims.UserId()
+	if userIdErr != nil {
+		RespondWithError(writer, http.StatusUnauthorized, CLIENT_MSG_INVALID_MFA_TOKEN)
+		return
+	}
+
+	// Deleted and deactivated users are included, logging in restores them.
+	user, getUserErr := cfg.Db.GetUserByIdIncludingInactive(request.Context(), userId)
+	if getUserErr != nil {
+		if errors.Is(getUserErr, sql.ErrNoRows) {
+			RespondWithError(writer, http.StatusUnauthorized, CLIENT_MSG_INVALID_MFA_TOKEN)
+			return
+		}
+		cfg.LogError(SERVER_MSG_GET_USER_FAILED, getUserErr)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_LOGIN_ERROR)
+		return
+	}
+
+	// The token is no longer valid once the password was changed or 2fa was turned off
+	if claims.TokenVersion != user.TokenVersion || !user.TotpEnabledAt.Valid || cfg.isPurgePending(user) {
+		RespondWithError(writer, http.StatusUnauthorized, CLIENT_MSG_INVALID_MFA_TOKEN)
+		return
+	}
+
+	// Codes are guessed against the same lockout as passwords
+	attemptEmail := normalizeLoginEmail(user.Email)
+	ipAddress := clientIP(request)
+	retryAfter, retryAfterErr := cfg.loginRetryAfter(request.Context(), attemptEmail, ipAddress)
+	if retryAfterErr != nil {
+		cfg.LogError(SERVER_MSG_LOGIN_FAILED, retryAfterErr)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_LOGIN_ERROR)
+		return
+	}
+	if retryAfter > 0 {
+		respondWithLoginLockout(writer, retryAfter)
+		return
+	}
+
+	// Verify the second factor
+	valid, verifyErr := cfg.verifySecondFactor(request.Context(), user, loginRequest.Code, loginRequest.RecoveryCode)
+	if verifyErr != nil {
+		cfg.LogError(SERVER_MSG_TWO_FACTOR_FAILED, verifyErr)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_LOGIN_ERROR)
+		return
+	}
+	if !valid {
+		if recordErr := cfg.recordLoginFailure(request.Context(), attemptEmail, ipAddress, pgtype.Int8{Int64: user.ID, Valid: true}); recordErr != nil {
+			cfg.LogError(SERVER_MSG_LOGIN_FAILED, recordErr)
+		}
+		RespondWithError(writer, http.StatusUnauthorized, CLIENT_MSG_INVALID_TWO_FACTOR_CODE)
+		return
+	}
+
+	if recordErr := cfg.recordLoginSuccess(request.Context(), attemptEmail, ipAddress); recordErr != nil {
+		cfg.LogError(SERVER_MSG_LOGIN_FAILED, recordErr)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_LOGIN_ERROR)
+		return
+	}
+
+	restoredUser, restoreErr := cfg.restoreUserIfInactive(request.Context(), user)
+	if restoreErr != nil {
+		cfg.LogError(SERVER_MSG_RESTORE_ACCOUNT_FAILED, restoreErr)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_LOGIN_ERROR)
+		return
+	}
+
+	cfg.respondWithNewSession(writer, request, restoredUser, http.StatusOK, CLIENT_MSG_LOGIN_ERROR)
+}
+
+// Check either a totp code or a recovery code. A recovery code is used up once it matches.
+func (cfg *ApiConfig) verifySecondFactor(ctx context.Context, user database.User, code, recoveryCode string) (bool, error) {
+	if code != "" {
+		return cfg.verifyTotpCode(ctx, user, code)
+	}
+
+	if recoveryCode == "" {
+		return false, nil
+	}
+
+	params := database.UseRecoveryCodeParams{
+		UserID:   user.ID,
+		CodeHash: HashToken(totp.NormalizeRecoveryCode(recoveryCode)),
+	}
+	affectedRows, useErr := cfg.Db.UseRecoveryCode(ctx, params)
+	if useErr != nil {
+		return false, useErr
+	}
+	return affectedRows == 1, nil
+}
+
+// Check a totp code against the user's secret.
+// Each time step is only accepted once, so a code that was seen by someone else cannot be replayed.
+func (cfg *ApiConfig) verifyTotpCode(ctx context.Context, user database.User, code string) (bool, error) {
+	if !user.TotpSecret.Valid {
+		return false, nil
+	}
+
+	step, ok := totp.Validate(user.TotpSecret.String, code, cfg.now(), app.TOTP_SKEW)
+	if !ok {
+		return false, nil
+	}
+
+	params := database.UpdateUserTotpLastStepParams{
+		ID: user.ID,
+		TotpLastStep: pgtype.Int8{
+			Int64: step,
+			Valid: true,
+		},
+	}
+	affectedRows, updateErr := cfg.Db.UpdateUserTotpLastStep(ctx, params)
+	if updateErr != nil {
+		return false, updateErr
+	}
+	return affectedRows == 1, nil
+}
@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/app"
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/app/validators"
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/database"
+)
+
+type userNameAvailabilityResponse struct {
+	Name      string `json:"name"`
+	Available bool   `json:"available"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+// The user name belongs to another user, or is still reserved for its previous owner
+var errUserNameTaken = errors.New("user name is taken")
+
+// The user changed their user name too recently
+var errUserNameCooldown = errors.New("user name was changed recently")
+
+// Check whether a user name can be used by the authenticated user
+func (cfg *ApiConfig) CheckUserNameHandler(writer http.ResponseWriter, request *http.Request) {
+	name := request.URL.Query().Get("name")
+	response := userNameAvailabilityResponse{Name: name}
+
+	if validationErr := validators.ValidateUserName(name); validationErr != nil {
+		response.Reason = validationErr.Error()
+		RespondWithJson(writer, http.StatusOK, response)
+		return
+	}
+
+	taken, err := cfg.Db.IsUserNameTaken(request.Context(), database.IsUserNameTakenParams{
+		UserName: name,
+		UserID:   UserIdFromContext(request.Context()),
+	})
+	if err != nil {
+		cfg.LogError(err.Error(), err)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_CHECK_USER_NAME)
+		return
+	}
+	if taken {
+		response.Reason = errUserNameTaken.Error()
+		RespondWithJson(writer, http.StatusOK, response)
+		return
+	}
+
+	response.Available = true
+	RespondWithJson(writer, http.StatusOK, response)
+}
+
+// Get the profile of a user by the handle path value.
+// A handle the user changed away from recently redirects to their profile.
+func (cfg *ApiConfig) GetUserByHandleHandler(writer http.ResponseWriter, request *http.Request) {
+	handle := request.PathValue("handle")
+
+	user, getUserErr := cfg.Db.GetUserByUserName(request.Context(), handle)
+	if getUserErr == nil {
+		// Users blocked either way are not found
+		blocked, ok := cfg.isBlockedBetween(writer, request, user.ID, CLIENT_MSG_ERROR_GET_USER_PROFILE)
+		if !ok {
+			return
+		}
+		if blocked {
+			RespondWithError(writer, http.StatusNotFound, CLIENT_MSG_USER_NOT_FOUND)
+			return
+		}
+
+		cfg.respondWithProfile(writer, request, user)
+		return
+	}
+	if !errors.Is(getUserErr, sql.ErrNoRows) {
+		cfg.LogError(getUserErr.Error(), getUserErr)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_GET_USER_PROFILE)
+		return
+	}
+
+	// Old handles stay reserved for a while and point to the new one
+	userId, reservedErr := cfg.Db.GetUserIdForReservedUserName(request.Context(), handle)
+	if reservedErr != nil {
+		if errors.Is(reservedErr, sql.ErrNoRows) {
+			RespondWithError(writer, http.StatusNotFound, CLIENT_MSG_USER_NOT_FOUND)
+			return
+		}
+		cfg.LogError(reservedErr.Error(), reservedErr)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_GET_USER_PROFILE)
+		return
+	}
+
+	http.Redirect(writer, request, fmt.Sprintf("/api/users/%v", userId), http.StatusTemporaryRedirect)
+}
+
+// Update the profile in one transaction. A new user name is changed with changeUserName, nil keeps the current one.
+func (cfg *ApiConfig) updateProfile(ctx context.Context, user database.User, params database.UpdateUserProfileParams, userName *string) (database.User, error) {
+	updatedUser := user
+	txErr := cfg.inTx(ctx, func(qtx *database.Queries) error {
+		if userName != nil && *userName != user.UserName {
+			if err := cfg.changeUserName(ctx, qtx, user, *userName); err != nil {
+				return err
+			}
+		}
+
+		var updateErr error
+		updatedUser, updateErr = qtx.UpdateUserProfile(ctx, params)
+		return updateErr
+	})
+	if txErr != nil {
+		return database.User{}, txErr
+	}
+
+	return updatedUser, nil
+}
+
+// Change the user name of the user and keep the old one reserved for them. Must run in a transaction.
+// The first change after registering is not limited by the cooldown.
+func (cfg *ApiConfig) changeUserName(ctx context.Context, qtx *database.Queries, user database.User, userName string) error {
+	if userNameCooldownLeft(user, cfg.now()) > 0 {
+		return errUserNameCooldown
+	}
+
+	taken, takenErr := qtx.IsUserNameTaken(ctx, database.IsUserNameTakenParams{
+		UserName: userName,
+		UserID:   user.ID,
+	})
+	if takenErr != nil {
+		return takenErr
+	}
+	if taken {
+		return errUserNameTaken
+	}
+
+	if _, changeErr := qtx.ChangeUserName(ctx, database.ChangeUserNameParams{
+		ID:       user.ID,
+		UserName: userName,
+	}); changeErr != nil {
+		// Someone else took it in the meantime
+		if isUniqueViolation(changeErr) {
+			return errUserNameTaken
+		}
+		return changeErr
+	}
+
+	return qtx.CreateUserNameHistory(ctx, database.CreateUserNameHistoryParams{
+		UserID:   user.ID,
+		UserName: user.UserName,
+		ReservedUntil: pgtype.Timestamp{
+			Time:  cfg.now().Add(app.USER_NAME_RESERVATION_PERIOD),
+			Valid: true,
+		},
+	})
+}
+
+// Respond to an error from updateProfile
+func (cfg *ApiConfig) respondWithUpdateProfileError(writer http.ResponseWriter, user database.User, err error) {
+	if errors.Is(err, errUserNameTaken) {
+		RespondWithError(writer, http.StatusConflict, CLIENT_MSG_USER_NAME_TAKEN)
+		return
+	}
+	if errors.Is(err, errUserNameCooldown) {
+		retryAfter := userNameCooldownLeft(user, cfg.now())
+		writer.Header().Set(app.RETRY_AFTER, fmt.Sprintf("%.0f", math.Ceil(retryAfter.Seconds())))
+		RespondWithError(writer, http.StatusTooManyRequests, CLIENT_MSG_USER_NAME_CHANGE_COOLDOWN)
+		return
+	}
+
+	cfg.LogError(SERVER_MSG_UPDATE_USER_ERROR, err)
+	RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_UPDATE_USER)
+}
+
+// User name given to users who register without one. The user can change it once without waiting for the cooldown.
+func randomUserName() (string, error) {
+	randomBytes := make([]byte, 5)
+	if _, err := rand.Read(randomBytes); err != nil {
+		return "", err
+	}
+	return "user_" + hex.EncodeToString(randomBytes), nil
+}
+
+// Time until the user can change their user name again, zero if they can now
+func userNameCooldownLeft(user database.User, now time.Time) time.Duration {
+	if !user.UserNameChangedAt.Valid {
+		return 0
+	}
+	return max(user.UserNameChangedAt.Time.Add(app.USER_NAME_CHANGE_COOLDOWN).Sub(now), 0)
+}
@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/database"
+)
+
+// User as other users see them. Never includes the email or the dob.
+// IsFollowing, FollowsYou and FollowRequested are from the point of view of the authenticated user.
+// ProfileImage has the renditions of the profile picture, ProfileImageUrl is the full one.
+type publicUserResponse struct {
+	ID              int64                  `json:"id"`
+	UserName        string                 `json:"user_name"`
+	FullName        string                 `json:"full_name"`
+	ProfileImageUrl string                 `json:"profile_image_url"`
+	ProfileImage    *imageVariantsResponse `json:"profile_image,omitempty"`
+	CreatedAt       time.Time              `json:"created_at"`
+	IsFollowing     bool                   `json:"is_following"`
+	FollowsYou      bool                   `json:"follows_you"`
+	FollowRequested bool                   `json:"follow_requested"`
+}
+
+// User as the user themselves sees it
+type privateUserResponse struct {
+	ID              int64                  `json:"id"`
+	Email           string                 `json:"email"`
+	UserName        string                 `json:"user_name"`
+	FullName        string                 `json:"full_name"`
+	ProfileImageUrl string                 `json:"profile_image_url"`
+	ProfileImage    *imageVariantsResponse `json:"profile_image,omitempty"`
+	Dob             string                 `json:"dob"`
+	CreatedAt       time.Time              `json:"created_at"`
+	UpdatedAt       time.Time              `json:"updated_at"`
+	EmailVerified   bool                   `json:"email_verified"`
+	IsPrivate       bool                   `json:"is_private"`
+	Interests       []interestResponse     `json:"interests"`
+}
+
+// Public profile of a user. PendingFollowRequestCount is only shown to the user themselves.
+type publicProfileResponse struct {
+	publicUserResponse
+	IsPrivate                 bool               `json:"is_private"`
+	Interests                 []interestResponse `json:"interests"`
+	PostCount                 int64              `json:"post_count"`
+	LikeCount                 int64              `json:"like_count"`
+	FollowerCount             int64              `json:"follower_count"`
+	FollowingCount            int64              `json:"following_count"`
+	PendingFollowRequestCount *int64             `json:"pending_follow_request_count,omitempty"`
+}
+
+func newPublicUserResponse(user database.User) publicUserResponse {
+	return newPublicUserResponseFromColumns(
+		user.ID,
+		user.UserName,
+		user.FullName,
+		user.CreatedAt,
+		user.ProfileImageUrl,
+		user.ProfileImageFeedUrl,
+		user.ProfileImageThumbnailUrl,
+	)
+}
+
+// Public user from the user columns another query selected, like the author of a post or a follower.
+// Every query that returns users selects the urls of all the renditions of the profile picture for this.
+func newPublicUserResponseFromColumns(
+	id int64,
+	userName string,
+	fullName string,
+	createdAt pgtype.Timestamp,
+	profileImageUrl pgtype.Text,
+	profileImageFeedUrl pgtype.Text,
+	profileImageThumbnailUrl pgtype.Text,
+) publicUserResponse {
+	return publicUserResponse{
+		ID:              id,
+		UserName:        userName,
+		FullName:        fullName,
+		ProfileImageUrl: profileImageUrl.String,
+		ProfileImage:    newImageVariantsResponse(profileImageUrl.String, profileImageFeedUrl, profileImageThumbnailUrl),
+		CreatedAt:       createdAt.Time,
+	}
+}
+
+// The user with IsFollowing and FollowsYou from the point of view of the authenticated user
+func (user publicUserResponse) withFollowStatus(isFollowing bool, followsYou bool) publicUserResponse {
+	user.IsFollowing = isFollowing
+	user.FollowsYou = followsYou
+	return user
+}
+
+func newPrivateUserResponse(user database.User, interests []interestResponse) privateUserResponse {
+	return privateUserResponse{
+		ID:              user.ID,
+		Email:           user.Email,
+		UserName:        user.UserName,
+		FullName:        user.FullName,
+		ProfileImageUrl: user.ProfileImageUrl.String,
+		ProfileImage:    newProfileImageResponse(user),
+		Dob:             FormatNullDobString(user.Dob.Time),
+		CreatedAt:       user.CreatedAt.Time,
+		UpdatedAt:       user.UpdatedAt.Time,
+		EmailVerified:   user.EmailVerifiedAt.Valid,
+		IsPrivate:       user.IsPrivate,
+		Interests:       interests,
+	}
+}
+
+// Renditions of the profile picture, nil if the user has none
+func newProfileImageResponse(user database.User) *imageVariantsResponse {
+	return newImageVariantsResponse(user.ProfileImageUrl.String, user.ProfileImageFeedUrl, user.ProfileImageThumbnailUrl)
+}
+
+// Get User Profile
+func (cfg *ApiConfig) GetUserProfileHandler(writer http.ResponseWriter, request *http.Request) {
+	// Deleted and deactivated users are not found, and neither are users blocked either way
+	user, ok := cfg.getUnblockedUserFromPath(writer, request, CLIENT_MSG_ERROR_GET_USER_PROFILE)
+	if !ok {
+		return
+	}
+
+	cfg.respondWithProfile(writer, request, user)
+}
+
+// Respond with the public profile of a user
+func (cfg *ApiConfig) respondWithProfile(writer http.ResponseWriter, request *http.Request, user database.User) {
+	interests, interestsErr := getInterestsForUser(user.ID, request, cfg.Db)
+	if interestsErr != nil {
+		cfg.LogError(interestsErr.Error(), interestsErr)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_GET_USER_PROFILE)
+		return
+	}
+
+	stats, statsErr := cfg.Db.GetUserProfileStats(request.Context(), user.ID)
+	if statsErr != nil {
+		cfg.LogError(statsErr.Error(), statsErr)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_GET_USER_PROFILE)
+		return
+	}
+
+	followStatus, followStatusErr := cfg.Db.GetFollowStatus(request.Context(), database.GetFollowStatusParams{
+		FollowerID: UserIdFromContext(request.Context()),
+		FolloweeID: user.ID,
+	})
+	if followStatusErr != nil {
+		cfg.LogError(followStatusErr.Error(), followStatusErr)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_GET_USER_PROFILE)
+		return
+	}
+
+	profileUser := newPublicUserResponse(user)
+	profileUser.IsFollowing = followStatus.IsFollowing
+	profileUser.FollowsYou = followStatus.FollowsYou
+	profileUser.FollowRequested = followStatus.FollowRequested
+
+	response := publicProfileResponse{
+		publicUserResponse: profileUser,
+		IsPrivate:          user.IsPrivate,
+		Interests:          interests,
+		PostCount:          stats.PostCount,
+		LikeCount:          stats.LikeCount,
+		FollowerCount:      stats.FollowerCount,
+		FollowingCount:     stats.FollowingCount,
+	}
+	if user.ID == UserIdFromContext(request.Context()) {
+		response.PendingFollowRequestCount = &stats.PendingRequestCount
+	}
+
+	RespondWithJson(writer, http.StatusOK, response)
+}
+
+// Parse the user_id path value
+func userIdFromPath(request *http.Request) (int64, error) {
+	return strconv.ParseInt(request.PathValue("user_id"), 10, 64)
+}
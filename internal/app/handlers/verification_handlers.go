@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/app"
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/app/mailer"
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/database"
+)
+
+type messageResponse struct {
+	Message string `json:"message"`
+}
+
+// Send the verification link to the user.
+// Returns false without sending anything if a verification email was sent within the cooldown.
+func (cfg *ApiConfig) sendVerificationEmail(ctx context.Context, user database.User) (bool, error) {
+	// Claim the send slot first so that concurrent requests cannot both send
+	params := database.UpdateUserVerificationSentAtParams{
+		ID: user.ID,
+		VerificationSentAt: pgtype.Timestamp{
+			Time:  time.Now().Add(-app.VERIFICATION_EMAIL_COOLDOWN),
+			Valid: true,
+		},
+	}
+	affectedRows, updateErr := cfg.Db.UpdateUserVerificationSentAt(ctx, params)
+	if updateErr != nil {
+		return false, updateErr
+	}
+	if affectedRows == 0 {
+		return false, nil
+	}
+
+	token, tokenErr := MakeEmailVerificationToken(user.ID, user.Email, cfg.Keys, app.EMAIL_VERIFICATION_EXPIRE_TIME)
+	if tokenErr != nil {
+		return false, tokenErr
+	}
+
+	verificationLink := fmt.Sprintf("%v/api/verify-email?token=%v", cfg.GetBaseUrl(), url.QueryEscape(token))
+	message := mailer.Message{
+		To:      user.Email,
+		Subject: "Verify your Sanctuary email",
+		Body: fmt.Sprintf(
+			"Welcome to Sanctuary!\n\nPlease verify your email by opening the link below:\n\n%v\n\nThe link expires in %v.",
+			verificationLink,
+			app.EMAIL_VERIFICATION_EXPIRE_TIME,
+		),
+	}
+	if sendErr := cfg.Mailer.Send(ctx, message); sendErr != nil {
+		return false, sendErr
+	}
+
+	return true, nil
+}
+
+// Verify email with the token from the verification link.
+func (cfg *ApiConfig) VerifyEmailHandler(writer http.ResponseWriter, request *http.Request) {
+	token := request.URL.Query().Get("token")
+	if token == "" {
+		RespondWithError(writer, http.StatusBadRequest, CLIENT_MSG_INVALID_VERIFICATION_LINK)
+		return
+	}
+
+	userId, email, tokenErr := ValidateEmailVerificationToken(token, cfg.Keys)
+	if tokenErr != nil {
+		cfg.LogError(SERVER_MSG_JWT_VALIDATION_FAILED, tokenErr)
+		RespondWithError(writer, http.StatusBadRequest, CLIENT_MSG_INVALID_VERIFICATION_LINK)
+		return
+	}
+
+	// Only matches if the user still has the email the link was sent to
+	params := database.MarkUserEmailVerifiedParams{
+		ID:    userId,
+		Email: email,
+	}
+	affectedRows, verifyErr := cfg.Db.MarkUserEmailVerified(request.Context(), params)
+	if verifyErr != nil {
+		cfg.LogError(SERVER_MSG_VERIFY_EMAIL_FAILED, verifyErr)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_VERIFY_EMAIL)
+		return
+	}
+	if affectedRows == 0 {
+		RespondWithError(writer, http.StatusBadRequest, CLIENT_MSG_INVALID_VERIFICATION_LINK)
+		return
+	}
+
+	RespondWithJson(writer, http.StatusOK, messageResponse{Message: "Your email has been verified."})
+}
+
+// Resend the verification email.
+func (cfg *ApiConfig) ResendVerificationEmailHandler(writer http.ResponseWriter, request *http.Request) {
+	// Get the authenticated user
+	user := UserFromContext(request.Context())
+
+	if user.EmailVerifiedAt.Valid {
+		RespondWithError(writer, http.StatusConflict, CLIENT_MSG_EMAIL_ALREADY_VERIFIED)
+		return
+	}
+
+	sent, sendErr := cfg.sendVerificationEmail(request.Context(), user)
+	if sendErr != nil {
+		cfg.LogError(SERVER_MSG_SEND_MAIL_FAILED, sendErr)
+		RespondWithError(writer, http.StatusInternalServerError, CLIENT_MSG_ERROR_SEND_VERIFICATION_EMAIL)
+		return
+	}
+	if !sent {
+		writer.Header().Set(app.RETRY_AFTER, fmt.Sprintf("%.0f", app.VERIFICATION_EMAIL_COOLDOWN.Seconds()))
+		RespondWithError(writer, http.StatusTooManyRequests, CLIENT_MSG_VERIFICATION_EMAIL_COOLDOWN)
+		return
+	}
+
+	// Return empty response
+	writer.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,143 @@
+package imaging
+
+import (
+	"encoding/binary"
+	"image"
+)
+
+// Jpeg markers needed to find the EXIF data
+const (
+	jpegMarkerSOI  = 0xD8
+	jpegMarkerAPP1 = 0xE1
+	jpegMarkerSOS  = 0xDA
+)
+
+// Tag of the orientation in the first IFD of the EXIF data
+const exifTagOrientation = 0x0112
+
+// The EXIF orientation of a jpeg, 1 when there is none.
+// 1 is upright. 2 to 8 say how the camera was held, see applyOrientation.
+func jpegOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != jpegMarkerSOI {
+		return 1
+	}
+
+	// Segments are 0xFF, the marker and a big endian length that counts itself
+	offset := 2
+	for offset+4 <= len(data) {
+		if data[offset] != 0xFF {
+			return 1
+		}
+		marker := data[offset+1]
+		if marker == 0xFF {
+			// Fill byte before the marker
+			offset++
+			continue
+		}
+		if marker == jpegMarkerSOS {
+			// The image data starts, EXIF always comes before it
+			return 1
+		}
+
+		length := int(binary.BigEndian.Uint16(data[offset+2:]))
+		if length < 2 || offset+2+length > len(data) {
+			return 1
+		}
+		segment := data[offset+4 : offset+2+length]
+		if marker == jpegMarkerAPP1 && len(segment) >= 6 && string(segment[:6]) == "Exif\x00\x00" {
+			return exifOrientation(segment[6:])
+		}
+		offset += 2 + length
+	}
+
+	return 1
+}
+
+// Find the orientation in the TIFF structure of the EXIF data. It can be little or big endian.
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+	if order.Uint16(tiff[2:]) != 42 {
+		return 1
+	}
+
+	ifdOffset := int(order.Uint32(tiff[4:]))
+	if ifdOffset < 8 || ifdOffset+2 > len(tiff) {
+		return 1
+	}
+	entryCount := int(order.Uint16(tiff[ifdOffset:]))
+
+	// Each entry is the tag, the type, the count and the value, 12 bytes in all
+	for index := 0; index < entryCount; index++ {
+		entry := ifdOffset + 2 + index*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[entry:]) != exifTagOrientation {
+			continue
+		}
+		// A short, which is stored at the start of the value
+		orientation := int(order.Uint16(tiff[entry+8:]))
+		if orientation < 1 || orientation > 8 {
+			return 1
+		}
+		return orientation
+	}
+
+	return 1
+}
+
+// Turn and flip the image so it is upright for the EXIF orientation.
+// 2, 3 and 4 flip it horizontally, turn it half way and flip it vertically.
+// 5 to 8 swap the sides: 5 mirrors it along the diagonal, 6 turns it clockwise,
+// 7 mirrors it along the other diagonal and 8 turns it counter clockwise.
+func applyOrientation(src *image.RGBA, orientation int) *image.RGBA {
+	if orientation <= 1 || orientation > 8 {
+		return src
+	}
+
+	width, height := src.Bounds().Dx(), src.Bounds().Dy()
+	dstWidth, dstHeight := width, height
+	if orientation >= 5 {
+		dstWidth, dstHeight = height, width
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dstWidth, dstHeight))
+
+	// Which pixel of the source goes to x, y of the result
+	var source func(x, y int) (int, int)
+	switch orientation {
+	case 2:
+		source = func(x, y int) (int, int) { return width - 1 - x, y }
+	case 3:
+		source = func(x, y int) (int, int) { return width - 1 - x, height - 1 - y }
+	case 4:
+		source = func(x, y int) (int, int) { return x, height - 1 - y }
+	case 5:
+		source = func(x, y int) (int, int) { return y, x }
+	case 6:
+		source = func(x, y int) (int, int) { return y, height - 1 - x }
+	case 7:
+		source = func(x, y int) (int, int) { return width - 1 - y, height - 1 - x }
+	case 8:
+		source = func(x, y int) (int, int) { return width - 1 - y, x }
+	}
+
+	for y := 0; y < dstHeight; y++ {
+		for x := 0; x < dstWidth; x++ {
+			sx, sy := source(x, y)
+			copy(dst.Pix[dst.PixOffset(x, y):dst.PixOffset(x, y)+4], src.Pix[src.PixOffset(sx, sy):src.PixOffset(sx, sy)+4])
+		}
+	}
+
+	return dst
+}
@@ -0,0 +1,148 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/draw"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/app/media"
+)
+
+// Size of an image rendition, shown in a different place of the app
+type Rendition string
+
+const (
+	RenditionThumbnail Rendition = "thumbnail"
+	RenditionFeed      Rendition = "feed"
+	RenditionFull      Rendition = "full"
+)
+
+// Longest side of each rendition in pixels. Images are never made bigger.
+const (
+	thumbnailMaxSide = 320
+	feedMaxSide      = 1080
+	fullMaxSide      = 2048
+)
+
+// Images with more pixels are refused before they are decoded, since decoding allocates all of them
+const MaxPixels = 50_000_000
+
+// Quality of the re-encoded jpegs
+const jpegQuality = 85
+
+// Returned for images that have more than MaxPixels pixels
+var ErrTooManyPixels = errors.New("image has too many pixels")
+
+// One rendition of a processed image, ready to be uploaded
+type Variant struct {
+	Rendition   Rendition
+	Data        []byte
+	ContentType string
+	// Extension of the file including the dot
+	Extension string
+	Width     int
+	Height    int
+}
+
+// Re-encode an uploaded still image without its metadata, turned the way its EXIF orientation says.
+// The format is checked by sniffing the bytes, what the client says it is does not matter.
+// The variants come largest first. A rendition is left out when the image is not bigger than it,
+// so use the next bigger variant in its place. Webp images cannot be decoded in pure Go,
+// they only have their metadata removed and come back as the full variant.
+// Returns media.ErrUnsupportedFormat for anything but a still image and media.ErrMalformed for broken files.
+func Process(data []byte) ([]Variant, error) {
+	contentType, sniffErr := media.Sniff(bytes.NewReader(data))
+	if sniffErr != nil {
+		return nil, sniffErr
+	}
+
+	switch contentType {
+	case "image/jpeg", "image/png", "image/gif":
+	case "image/webp":
+		return processWebP(data)
+	default:
+		return nil, media.ErrUnsupportedFormat
+	}
+
+	config, _, configErr := image.DecodeConfig(bytes.NewReader(data))
+	if configErr != nil {
+		return nil, media.ErrMalformed
+	}
+	if config.Width*config.Height > MaxPixels {
+		return nil, ErrTooManyPixels
+	}
+
+	// Only the first frame of a gif is decoded. Animated gifs are kept as they are and never come here.
+	decoded, _, decodeErr := image.Decode(bytes.NewReader(data))
+	if decodeErr != nil {
+		return nil, media.ErrMalformed
+	}
+
+	canvas := toRGBA(decoded)
+	if contentType == "image/jpeg" {
+		canvas = applyOrientation(canvas, jpegOrientation(data))
+	}
+
+	variants := []Variant{}
+	for _, rendition := range []struct {
+		name    Rendition
+		maxSide int
+	}{
+		{RenditionFull, fullMaxSide},
+		{RenditionFeed, feedMaxSide},
+		{RenditionThumbnail, thumbnailMaxSide},
+	} {
+		width, height := fitInside(canvas.Bounds().Dx(), canvas.Bounds().Dy(), rendition.maxSide)
+		// The full rendition is always made, smaller ones only when they are smaller than the one before
+		if len(variants) > 0 && width == canvas.Bounds().Dx() && height == canvas.Bounds().Dy() {
+			continue
+		}
+		// Each rendition is made from the one before, which has fewer pixels to go through
+		canvas = resize(canvas, width, height)
+
+		variant, encodeErr := encode(canvas, contentType, rendition.name)
+		if encodeErr != nil {
+			return nil, encodeErr
+		}
+		variants = append(variants, variant)
+	}
+
+	return variants, nil
+}
+
+// Jpegs stay jpegs. Pngs and gifs become pngs, so transparency is kept.
+func encode(img *image.RGBA, sourceType string, rendition Rendition) (Variant, error) {
+	variant := Variant{
+		Rendition: rendition,
+		Width:     img.Bounds().Dx(),
+		Height:    img.Bounds().Dy(),
+	}
+
+	encoded := bytes.Buffer{}
+	if sourceType == "image/jpeg" {
+		if err := jpeg.Encode(&encoded, img, &jpeg.Options{Quality: jpegQuality}); err != nil {
+			return Variant{}, err
+		}
+		variant.ContentType, variant.Extension = "image/jpeg", ".jpg"
+	} else {
+		if err := png.Encode(&encoded, img); err != nil {
+			return Variant{}, err
+		}
+		variant.ContentType, variant.Extension = "image/png", ".png"
+	}
+
+	variant.Data = encoded.Bytes()
+	return variant, nil
+}
+
+// The decoded image as RGBA starting at 0, 0
+func toRGBA(img image.Image) *image.RGBA {
+	bounds := img.Bounds()
+	canvas := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(canvas, canvas.Bounds(), img, bounds.Min, draw.Src)
+	return canvas
+}
@@ -0,0 +1,50 @@
+package imaging
+
+import (
+	"image"
+)
+
+// Dimensions of an image scaled down so its longest side is at most maxSide, keeping the aspect ratio
+func fitInside(width int, height int, maxSide int) (int, int) {
+	longest := max(width, height)
+	if longest <= maxSide {
+		return width, height
+	}
+	return max(1, (width*maxSide+longest/2)/longest), max(1, (height*maxSide+longest/2)/longest)
+}
+
+// Scale the image down with a box filter. Every pixel of the result is the average of the source pixels it covers.
+// Only for making images smaller.
+func resize(src *image.RGBA, width int, height int) *image.RGBA {
+	srcWidth, srcHeight := src.Bounds().Dx(), src.Bounds().Dy()
+	if width == srcWidth && height == srcHeight {
+		return src
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		top, bottom := y*srcHeight/height, max((y+1)*srcHeight/height, y*srcHeight/height+1)
+		for x := 0; x < width; x++ {
+			left, right := x*srcWidth/width, max((x+1)*srcWidth/width, x*srcWidth/width+1)
+
+			var sum [4]int
+			for sy := top; sy < bottom; sy++ {
+				row := src.Pix[src.PixOffset(left, sy):src.PixOffset(right, sy)]
+				for index := 0; index < len(row); index += 4 {
+					sum[0] += int(row[index])
+					sum[1] += int(row[index+1])
+					sum[2] += int(row[index+2])
+					sum[3] += int(row[index+3])
+				}
+			}
+
+			count := (bottom - top) * (right - left)
+			offset := dst.PixOffset(x, y)
+			for channel := 0; channel < 4; channel++ {
+				dst.Pix[offset+channel] = uint8((sum[channel] + count/2) / count)
+			}
+		}
+	}
+
+	return dst
+}
@@ -0,0 +1,89 @@
+package imaging
+
+import (
+	"encoding/binary"
+
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/app/media"
+)
+
+// Flags of the VP8X chunk that say the file has EXIF or XMP metadata
+const (
+	vp8xFlagExif = 0x08
+	vp8xFlagXmp  = 0x04
+)
+
+// A webp is a RIFF file: "RIFF", the size of the rest, "WEBP" and then chunks.
+// Each chunk is a fourcc, a little endian size and the data, padded to an even length.
+// The EXIF and XMP chunks are dropped and everything else is copied as it is.
+func processWebP(data []byte) ([]Variant, error) {
+	if len(data) < 12 || string(data[:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return nil, media.ErrMalformed
+	}
+
+	stripped := append([]byte{}, data[:12]...)
+	width, height := 0, 0
+	offset := 12
+	for offset < len(data) {
+		if offset+8 > len(data) {
+			return nil, media.ErrMalformed
+		}
+		fourcc := string(data[offset : offset+4])
+		size := int(binary.LittleEndian.Uint32(data[offset+4:]))
+		end := offset + 8 + size
+		if end > len(data) {
+			return nil, media.ErrMalformed
+		}
+		payload := data[offset+8 : end]
+		// The padding byte may be missing at the very end of the file
+		if size%2 == 1 && end < len(data) {
+			end++
+		}
+
+		switch fourcc {
+		case "EXIF", "XMP ":
+			offset = end
+			continue
+		case "VP8X":
+			if len(payload) < 10 {
+				return nil, media.ErrMalformed
+			}
+			// The canvas size is stored minus one in 24 bits
+			width = (int(payload[4]) | int(payload[5])<<8 | int(payload[6])<<16) + 1
+			height = (int(payload[7]) | int(payload[8])<<8 | int(payload[9])<<16) + 1
+		case "VP8 ":
+			// A frame tag of 3 bytes and the start code, then the 14 bit sizes
+			if len(payload) >= 10 && width == 0 {
+				width = int(binary.LittleEndian.Uint16(payload[6:]) & 0x3FFF)
+				height = int(binary.LittleEndian.Uint16(payload[8:]) & 0x3FFF)
+			}
+		case "VP8L":
+			// A signature byte, then the sizes minus one in 14 bits each
+			if len(payload) >= 5 && width == 0 {
+				bits := binary.LittleEndian.Uint32(payload[1:])
+				width = int(bits&0x3FFF) + 1
+				height = int(bits>>14&0x3FFF) + 1
+			}
+		}
+
+		chunk := append([]byte{}, data[offset:end]...)
+		if fourcc == "VP8X" {
+			chunk[8] &^= vp8xFlagExif | vp8xFlagXmp
+		}
+		stripped = append(stripped, chunk...)
+		offset = end
+	}
+
+	if width*height > MaxPixels {
+		return nil, ErrTooManyPixels
+	}
+	binary.LittleEndian.PutUint32(stripped[4:], uint32(len(stripped)-8))
+
+	return []Variant{{
+		Rendition:   RenditionFull,
+		Data:        stripped,
+		ContentType: "image/webp",
+		Extension:   ".webp",
+		Width:       width,
+		Height:      height,
+	}}, nil
+}
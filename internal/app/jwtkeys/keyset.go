@@ -0,0 +1,204 @@
+package jwtkeys
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// A key that can verify tokens, and sign them if the private key is known
+type Key struct {
+	Kid        string
+	Method     jwt.SigningMethod
+	PublicKey  crypto.PublicKey
+	PrivateKey crypto.Signer
+}
+
+// Signs tokens with one key and verifies them with any of the active keys.
+//
+// Keys are looked up by the kid header of the token. Tokens without a kid are only accepted
+// with the HS256 fallback secret, so that tokens issued before the keys were set up keep working until they expire.
+type KeySet struct {
+	signingKey *Key
+	keys       map[string]*Key
+	hmacSecret []byte
+}
+
+// Make a key set that signs and verifies with a shared HS256 secret only
+func NewHMACKeySet(secret string) *KeySet {
+	return &KeySet{
+		keys:       map[string]*Key{},
+		hmacSecret: []byte(secret),
+	}
+}
+
+// Load the keys from the PEM files in dir. The kid of each key is its file name without the extension.
+//
+// Private keys (RSA or Ed25519, PKCS#1 or PKCS#8) can sign and verify.
+// Public keys (PKIX) can only verify, which is how a retired key keeps working until its tokens expire.
+// signingKid picks the key that signs. If it is empty the last private key in file name order signs.
+// hmacSecret is optional. If set, tokens without a kid are verified with it.
+func LoadKeySet(dir, signingKid, hmacSecret string) (*KeySet, error) {
+	paths, globErr := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if globErr != nil {
+		return nil, globErr
+	}
+	sort.Strings(paths)
+
+	keySet := &KeySet{
+		keys: map[string]*Key{},
+	}
+	if hmacSecret != "" {
+		keySet.hmacSecret = []byte(hmacSecret)
+	}
+
+	var lastPrivateKey *Key
+	for _, path := range paths {
+		pemBytes, readErr := os.ReadFile(path)
+		if readErr != nil {
+			return nil, readErr
+		}
+
+		kid := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		key, parseErr := ParseKey(kid, pemBytes)
+		if parseErr != nil {
+			return nil, fmt.Errorf("error loading key %v %w", path, parseErr)
+		}
+
+		keySet.keys[kid] = key
+		if key.PrivateKey != nil {
+			lastPrivateKey = key
+		}
+	}
+
+	if signingKid != "" {
+		key, ok := keySet.keys[signingKid]
+		if !ok || key.PrivateKey == nil {
+			return nil, fmt.Errorf("no private key with kid %v in %v", signingKid, dir)
+		}
+		keySet.signingKey = key
+	} else {
+		keySet.signingKey = lastPrivateKey
+	}
+
+	if keySet.signingKey == nil && keySet.hmacSecret == nil {
+		return nil, fmt.Errorf("no private keys in %v", dir)
+	}
+
+	return keySet, nil
+}
+
+// Parse a PEM encoded RSA or Ed25519 key
+func ParseKey(kid string, pemBytes []byte) (*Key, error) {
+	block, _ := pem.Decode(pemBytes)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		privateKey, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		return newKey(kid, privateKey.Public(), privateKey)
+	case "PRIVATE KEY":
+		privateKey, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		signer, ok := privateKey.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("unsupported private key type %T", privateKey)
+		}
+		return newKey(kid, signer.Public(), signer)
+	case "PUBLIC KEY":
+		publicKey, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		return newKey(kid, publicKey, nil)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block type %v", block.Type)
+	}
+}
+
+func newKey(kid string, publicKey crypto.PublicKey, privateKey crypto.Signer) (*Key, error) {
+	var method jwt.SigningMethod
+	switch publicKey.(type) {
+	case *rsa.PublicKey:
+		method = jwt.SigningMethodRS256
+	case ed25519.PublicKey:
+		method = jwt.SigningMethodEdDSA
+	default:
+		return nil, fmt.Errorf("unsupported key type %T, only RSA and Ed25519 keys are supported", publicKey)
+	}
+
+	return &Key{
+		Kid:        kid,
+		Method:     method,
+		PublicKey:  publicKey,
+		PrivateKey: privateKey,
+	}, nil
+}
+
+// Sign the claims with the signing key, or with the HS256 secret if there is no signing key
+func (keySet *KeySet) Sign(claims jwt.Claims) (string, error) {
+	if keySet.signingKey == nil {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		return token.SignedString(keySet.hmacSecret)
+	}
+
+	token := jwt.NewWithClaims(keySet.signingKey.Method, claims)
+	token.Header["kid"] = keySet.signingKey.Kid
+	return token.SignedString(keySet.signingKey.PrivateKey)
+}
+
+// Keyfunc for jwt.Parse. Picks the verification key by kid and rejects tokens
+// whose alg does not match the key, so a public key can never be used as an HMAC secret.
+func (keySet *KeySet) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		if keySet.hmacSecret == nil {
+			return nil, errors.New("the token has no kid")
+		}
+		if token.Method != jwt.SigningMethodHS256 {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return keySet.hmacSecret, nil
+	}
+
+	key, ok := keySet.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown kid %v", kid)
+	}
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+	return key.PublicKey, nil
+}
+
+// Get the public keys in JWKS format, sorted by kid
+func (keySet *KeySet) JWKS() JWKSet {
+	kids := []string{}
+	for kid := range keySet.keys {
+		kids = append(kids, kid)
+	}
+	sort.Strings(kids)
+
+	jwks := JWKSet{Keys: []JWK{}}
+	for _, kid := range kids {
+		jwks.Keys = append(jwks.Keys, toJWK(keySet.keys[kid]))
+	}
+	return jwks
+}
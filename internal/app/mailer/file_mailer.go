@@ -0,0 +1,41 @@
+package mailer
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Writes every email as an .eml file into Dir instead of sending it.
+// Used for local dev and tests so they run without a mail server.
+type FileMailer struct {
+	Dir  string
+	From string
+}
+
+func (mailer *FileMailer) Send(ctx context.Context, message Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(mailer.Dir, 0o755); err != nil {
+		return fmt.Errorf("error creating mail directory %w", err)
+	}
+
+	// Random suffix so mails sent in the same nanosecond do not overwrite each other
+	randomBytes := make([]byte, 4)
+	if _, err := rand.Read(randomBytes); err != nil {
+		return err
+	}
+	fileName := fmt.Sprintf("%v-%v.eml", time.Now().UTC().Format("20060102T150405.000000000"), hex.EncodeToString(randomBytes))
+
+	if err := os.WriteFile(filepath.Join(mailer.Dir, fileName), buildMessage(mailer.From, message), 0o644); err != nil {
+		return fmt.Errorf("error writing email %w", err)
+	}
+
+	return nil
+}
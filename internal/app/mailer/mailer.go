@@ -0,0 +1,40 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Email message
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Sends emails. Use SMTPMailer in production and FileMailer for dev and tests.
+type Mailer interface {
+	Send(ctx context.Context, message Message) error
+}
+
+// Build a plain text RFC 5322 message
+func buildMessage(from string, message Message) []byte {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "From: %v\r\n", headerValue(from))
+	fmt.Fprintf(&builder, "To: %v\r\n", headerValue(message.To))
+	fmt.Fprintf(&builder, "Subject: %v\r\n", headerValue(message.Subject))
+	fmt.Fprintf(&builder, "Date: %v\r\n", time.Now().Format(time.RFC1123Z))
+	builder.WriteString("MIME-Version: 1.0\r\n")
+	builder.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	builder.WriteString("\r\n")
+	builder.WriteString(strings.ReplaceAll(message.Body, "\n", "\r\n"))
+	builder.WriteString("\r\n")
+	return []byte(builder.String())
+}
+
+// Strip line breaks so user input cannot inject extra headers
+func headerValue(value string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(value)
+}
@@ -0,0 +1,36 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+)
+
+// Sends emails through an SMTP server
+type SMTPMailer struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+func (mailer *SMTPMailer) Send(ctx context.Context, message Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	// Only authenticate when credentials are configured. Local relays usually do not need them.
+	var auth smtp.Auth
+	if mailer.Username != "" {
+		auth = smtp.PlainAuth("", mailer.Username, mailer.Password, mailer.Host)
+	}
+
+	address := net.JoinHostPort(mailer.Host, mailer.Port)
+	if err := smtp.SendMail(address, auth, mailer.From, []string{message.To}, buildMessage(mailer.From, message)); err != nil {
+		return fmt.Errorf("error sending email %w", err)
+	}
+
+	return nil
+}
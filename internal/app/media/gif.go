@@ -0,0 +1,44 @@
+package media
+
+import (
+	"image"
+	"image/draw"
+	"image/gif"
+	"io"
+	"time"
+)
+
+// Read the frames of a gif. A gif with one frame is a still image.
+func probeGif(r io.Reader) (Info, error) {
+	decoded, decodeErr := gif.DecodeAll(r)
+	if decodeErr != nil || len(decoded.Image) == 0 {
+		return Info{}, ErrMalformed
+	}
+
+	info := Info{
+		Kind:        KindImage,
+		ContentType: "image/gif",
+		Width:       decoded.Config.Width,
+		Height:      decoded.Config.Height,
+	}
+	if len(decoded.Image) == 1 {
+		return info, nil
+	}
+
+	// Delays are in hundredths of a second
+	for _, delay := range decoded.Delay {
+		info.Duration += time.Duration(delay) * 10 * time.Millisecond
+	}
+	info.Kind = KindGif
+	info.Poster = firstGifFrame(decoded)
+
+	return info, nil
+}
+
+// The first frame drawn on the full canvas. A frame can be smaller than the canvas.
+func firstGifFrame(decoded *gif.GIF) image.Image {
+	frame := decoded.Image[0]
+	canvas := image.NewRGBA(image.Rect(0, 0, decoded.Config.Width, decoded.Config.Height))
+	draw.Draw(canvas, frame.Bounds(), frame, frame.Bounds().Min, draw.Over)
+	return canvas
+}
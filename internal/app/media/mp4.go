@@ -0,0 +1,242 @@
+package media
+
+import (
+	"encoding/binary"
+	"io"
+	"time"
+)
+
+// The moov box holds the metadata only, so a bigger one is not a real file
+const maxMoovSize = 64 << 20
+
+// A box of an ISO base media file. data is the content after the header.
+type mp4Box struct {
+	boxType string
+	data    []byte
+}
+
+// Read the duration from the movie header and the dimensions from the first video track
+func probeMP4(r io.ReadSeeker) (Info, error) {
+	moov, findErr := findMoov(r)
+	if findErr != nil {
+		return Info{}, findErr
+	}
+
+	info := Info{Kind: KindVideo, ContentType: "video/mp4"}
+	boxes, parseErr := parseMP4Boxes(moov)
+	if parseErr != nil {
+		return Info{}, parseErr
+	}
+
+	for _, box := range boxes {
+		switch box.boxType {
+		case "mvhd":
+			duration, durationErr := mvhdDuration(box.data)
+			if durationErr != nil {
+				return Info{}, durationErr
+			}
+			info.Duration = duration
+		case "trak":
+			if info.Width != 0 {
+				continue
+			}
+			width, height, isVideo, trakErr := trakDimensions(box.data)
+			if trakErr != nil {
+				return Info{}, trakErr
+			}
+			if isVideo {
+				info.Width = width
+				info.Height = height
+			}
+		}
+	}
+
+	return info, nil
+}
+
+// Skip the top level boxes until moov and read it. moov can come after the media data.
+func findMoov(r io.ReadSeeker) ([]byte, error) {
+	header := make([]byte, 16)
+	for {
+		if _, readErr := io.ReadFull(r, header[:8]); readErr != nil {
+			return nil, ErrMalformed
+		}
+		size := uint64(binary.BigEndian.Uint32(header[:4]))
+		boxType := string(header[4:8])
+		headerSize := uint64(8)
+
+		switch size {
+		case 0:
+			// The box runs to the end of the file, so there is no moov after it
+			if boxType != "moov" {
+				return nil, ErrMalformed
+			}
+			return readAllLimited(r, maxMoovSize)
+		case 1:
+			if _, readErr := io.ReadFull(r, header[8:16]); readErr != nil {
+				return nil, ErrMalformed
+			}
+			size = binary.BigEndian.Uint64(header[8:16])
+			headerSize = 16
+		}
+		if size < headerSize {
+			return nil, ErrMalformed
+		}
+
+		if boxType == "moov" {
+			if size-headerSize > maxMoovSize {
+				return nil, ErrMalformed
+			}
+			moov := make([]byte, size-headerSize)
+			if _, readErr := io.ReadFull(r, moov); readErr != nil {
+				return nil, ErrMalformed
+			}
+			return moov, nil
+		}
+
+		if _, seekErr := r.Seek(int64(size-headerSize), io.SeekCurrent); seekErr != nil {
+			return nil, ErrMalformed
+		}
+	}
+}
+
+// Split data into the boxes it is made of
+func parseMP4Boxes(data []byte) ([]mp4Box, error) {
+	boxes := []mp4Box{}
+	for len(data) > 0 {
+		if len(data) < 8 {
+			return nil, ErrMalformed
+		}
+		size := uint64(binary.BigEndian.Uint32(data[:4]))
+		boxType := string(data[4:8])
+		headerSize := uint64(8)
+
+		switch size {
+		case 0:
+			size = uint64(len(data))
+		case 1:
+			if len(data) < 16 {
+				return nil, ErrMalformed
+			}
+			size = binary.BigEndian.Uint64(data[8:16])
+			headerSize = 16
+		}
+		if size < headerSize || size > uint64(len(data)) {
+			return nil, ErrMalformed
+		}
+
+		boxes = append(boxes, mp4Box{boxType: boxType, data: data[headerSize:size]})
+		data = data[size:]
+	}
+	return boxes, nil
+}
+
+// Duration of the movie header box
+func mvhdDuration(data []byte) (time.Duration, error) {
+	if len(data) < 4 {
+		return 0, ErrMalformed
+	}
+
+	var timescale, duration uint64
+	switch data[0] {
+	case 0:
+		// version, flags, creation time, modification time, timescale, duration
+		if len(data) < 20 {
+			return 0, ErrMalformed
+		}
+		timescale = uint64(binary.BigEndian.Uint32(data[12:16]))
+		duration = uint64(binary.BigEndian.Uint32(data[16:20]))
+	case 1:
+		// The times and the duration are 64 bit
+		if len(data) < 32 {
+			return 0, ErrMalformed
+		}
+		timescale = uint64(binary.BigEndian.Uint32(data[20:24]))
+		duration = binary.BigEndian.Uint64(data[24:32])
+	default:
+		return 0, ErrMalformed
+	}
+
+	if timescale == 0 {
+		return 0, ErrMalformed
+	}
+	return durationFromUnits(duration, timescale), nil
+}
+
+// Dimensions of a track from its header, and whether the handler says it is a video track
+func trakDimensions(data []byte) (int, int, bool, error) {
+	boxes, parseErr := parseMP4Boxes(data)
+	if parseErr != nil {
+		return 0, 0, false, parseErr
+	}
+
+	width, height := 0, 0
+	isVideo := false
+	for _, box := range boxes {
+		switch box.boxType {
+		case "tkhd":
+			var tkhdErr error
+			width, height, tkhdErr = tkhdDimensions(box.data)
+			if tkhdErr != nil {
+				return 0, 0, false, tkhdErr
+			}
+		case "mdia":
+			handler, handlerErr := mdiaHandler(box.data)
+			if handlerErr != nil {
+				return 0, 0, false, handlerErr
+			}
+			isVideo = handler == "vide"
+		}
+	}
+
+	return width, height, isVideo, nil
+}
+
+// Width and height of the track header box. They are 16.16 fixed point numbers at the end of the box.
+func tkhdDimensions(data []byte) (int, int, error) {
+	if len(data) < 8 {
+		return 0, 0, ErrMalformed
+	}
+	end := len(data)
+	width := binary.BigEndian.Uint32(data[end-8 : end-4])
+	height := binary.BigEndian.Uint32(data[end-4:])
+	return int(width >> 16), int(height >> 16), nil
+}
+
+// Handler type of a media box, "vide" for video tracks
+func mdiaHandler(data []byte) (string, error) {
+	boxes, parseErr := parseMP4Boxes(data)
+	if parseErr != nil {
+		return "", parseErr
+	}
+	for _, box := range boxes {
+		if box.boxType != "hdlr" {
+			continue
+		}
+		// version, flags, pre defined, handler type
+		if len(box.data) < 12 {
+			return "", ErrMalformed
+		}
+		return string(box.data[8:12]), nil
+	}
+	return "", nil
+}
+
+// Read everything left in r, failing if there is more than limit
+func readAllLimited(r io.Reader, limit int64) ([]byte, error) {
+	data, readErr := io.ReadAll(io.LimitReader(r, limit+1))
+	if readErr != nil {
+		return nil, readErr
+	}
+	if int64(len(data)) > limit {
+		return nil, ErrMalformed
+	}
+	return data, nil
+}
+
+// Convert a duration counted in units of 1/timescale seconds
+func durationFromUnits(units uint64, timescale uint64) time.Duration {
+	seconds := units / timescale
+	remainder := units % timescale
+	return time.Duration(seconds)*time.Second + time.Duration(remainder*uint64(time.Second)/timescale)
+}
@@ -0,0 +1,111 @@
+package media
+
+import (
+	"errors"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Kind of a post attachment
+type Kind string
+
+const (
+	KindImage Kind = "image"
+	KindGif   Kind = "gif"
+	KindVideo Kind = "video"
+)
+
+// Returned for files that are not one of the supported images or videos
+var ErrUnsupportedFormat = errors.New("unsupported media format")
+
+// Returned for files that look like a supported format but cannot be parsed
+var ErrMalformed = errors.New("malformed media file")
+
+// What Probe found out about a media file. Width, Height and Duration are zero when they are unknown.
+type Info struct {
+	Kind Kind
+	// Sniffed from the content, not taken from the upload
+	ContentType string
+	Width       int
+	Height      int
+	Duration    time.Duration
+	// First frame of an animated gif. Nil for other kinds, videos cannot be decoded in pure Go.
+	Poster image.Image
+}
+
+// Find out the content type of a media file from its first bytes, without parsing the rest.
+// Returns ErrUnsupportedFormat for anything Probe cannot handle. r is rewound to the start.
+func Sniff(r io.ReadSeeker) (string, error) {
+	// DetectContentType looks at no more than 512 bytes
+	head := make([]byte, 512)
+	n, readErr := io.ReadFull(r, head)
+	if readErr != nil && !errors.Is(readErr, io.ErrUnexpectedEOF) {
+		if errors.Is(readErr, io.EOF) {
+			return "", ErrUnsupportedFormat
+		}
+		return "", readErr
+	}
+	if _, seekErr := r.Seek(0, io.SeekStart); seekErr != nil {
+		return "", seekErr
+	}
+
+	contentType := http.DetectContentType(head[:n])
+	switch contentType {
+	case "video/mp4", "video/webm", "image/gif", "image/jpeg", "image/png", "image/webp":
+		return contentType, nil
+	default:
+		return "", ErrUnsupportedFormat
+	}
+}
+
+// File extension for a content type returned by Sniff, so stored files are named by what they are
+func Extension(contentType string) string {
+	switch contentType {
+	case "video/mp4":
+		return ".mp4"
+	case "video/webm":
+		return ".webm"
+	case "image/gif":
+		return ".gif"
+	case "image/jpeg":
+		return ".jpg"
+	case "image/png":
+		return ".png"
+	case "image/webp":
+		return ".webp"
+	default:
+		return ""
+	}
+}
+
+// Find out the format of a media file by its content and read its dimensions and duration.
+// Still images are jpeg, png, webp or single frame gifs. Animated gifs are KindGif. Videos are mp4 or webm.
+// Gifs are decoded completely, so check the size of the file first.
+func Probe(r io.ReadSeeker) (Info, error) {
+	contentType, sniffErr := Sniff(r)
+	if sniffErr != nil {
+		return Info{}, sniffErr
+	}
+
+	switch contentType {
+	case "video/mp4":
+		return probeMP4(r)
+	case "video/webm":
+		return probeWebM(r)
+	case "image/gif":
+		return probeGif(r)
+	case "image/webp":
+		// There is no webp decoder in the standard library, so the dimensions are unknown
+		return Info{Kind: KindImage, ContentType: contentType}, nil
+	default:
+		config, _, decodeErr := image.DecodeConfig(r)
+		if decodeErr != nil {
+			return Info{}, ErrMalformed
+		}
+		return Info{Kind: KindImage, ContentType: contentType, Width: config.Width, Height: config.Height}, nil
+	}
+}
@@ -0,0 +1,275 @@
+package media
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+	"time"
+)
+
+// Matroska element ids used to find the duration and the dimensions
+const (
+	ebmlIdHeader         = 0x1A45DFA3
+	ebmlIdSegment        = 0x18538067
+	ebmlIdInfo           = 0x1549A966
+	ebmlIdTimestampScale = 0x2AD7B1
+	ebmlIdDuration       = 0x4489
+	ebmlIdTracks         = 0x1654AE6B
+	ebmlIdTrackEntry     = 0xAE
+	ebmlIdTrackType      = 0x83
+	ebmlIdVideo          = 0xE0
+	ebmlIdPixelWidth     = 0xB0
+	ebmlIdPixelHeight    = 0xBA
+	ebmlIdCluster        = 0x1F43B675
+)
+
+// Track type of video tracks
+const matroskaTrackTypeVideo = 1
+
+// The default timestamp scale, one millisecond in nanoseconds
+const defaultTimestampScale = 1000000
+
+// Info and Tracks hold the metadata only, so a bigger one is not a real file
+const maxWebMMetadataSize = 16 << 20
+
+// An element size with every bit set means the size is unknown
+const ebmlUnknownSize = math.MaxUint64
+
+// An element of a Matroska file. data is the content after the header.
+type ebmlElement struct {
+	id   uint64
+	data []byte
+}
+
+// Read the duration from the segment info and the dimensions from the first video track.
+// The metadata comes before the first cluster, so the media data is not read.
+func probeWebM(r io.ReadSeeker) (Info, error) {
+	// The EBML header says which kind of document follows, which DetectContentType already checked
+	id, size, headerErr := readEbmlHeader(r)
+	if headerErr != nil || id != ebmlIdHeader || size == ebmlUnknownSize {
+		return Info{}, ErrMalformed
+	}
+	if _, seekErr := r.Seek(int64(size), io.SeekCurrent); seekErr != nil {
+		return Info{}, ErrMalformed
+	}
+
+	id, _, segmentErr := readEbmlHeader(r)
+	if segmentErr != nil || id != ebmlIdSegment {
+		return Info{}, ErrMalformed
+	}
+
+	info := Info{Kind: KindVideo, ContentType: "video/webm"}
+	foundInfo, foundTracks := false, false
+	for !foundInfo || !foundTracks {
+		id, size, childErr := readEbmlHeader(r)
+		if childErr != nil || id == ebmlIdCluster {
+			// The metadata that was found is all there is
+			break
+		}
+		if size == ebmlUnknownSize {
+			return Info{}, ErrMalformed
+		}
+
+		switch id {
+		case ebmlIdInfo, ebmlIdTracks:
+			if size > maxWebMMetadataSize {
+				return Info{}, ErrMalformed
+			}
+			data := make([]byte, size)
+			if _, readErr := io.ReadFull(r, data); readErr != nil {
+				return Info{}, ErrMalformed
+			}
+
+			if id == ebmlIdInfo {
+				duration, infoErr := webMDuration(data)
+				if infoErr != nil {
+					return Info{}, infoErr
+				}
+				info.Duration = duration
+				foundInfo = true
+			} else {
+				width, height, tracksErr := webMDimensions(data)
+				if tracksErr != nil {
+					return Info{}, tracksErr
+				}
+				info.Width = width
+				info.Height = height
+				foundTracks = true
+			}
+		default:
+			if _, seekErr := r.Seek(int64(size), io.SeekCurrent); seekErr != nil {
+				return Info{}, ErrMalformed
+			}
+		}
+	}
+
+	return info, nil
+}
+
+// Duration of the segment info. It is a float counted in units of the timestamp scale.
+func webMDuration(data []byte) (time.Duration, error) {
+	elements, parseErr := parseEbmlElements(data)
+	if parseErr != nil {
+		return 0, parseErr
+	}
+
+	timestampScale := uint64(defaultTimestampScale)
+	duration := 0.0
+	for _, element := range elements {
+		switch element.id {
+		case ebmlIdTimestampScale:
+			timestampScale = ebmlUint(element.data)
+		case ebmlIdDuration:
+			switch len(element.data) {
+			case 4:
+				duration = float64(math.Float32frombits(binary.BigEndian.Uint32(element.data)))
+			case 8:
+				duration = math.Float64frombits(binary.BigEndian.Uint64(element.data))
+			default:
+				return 0, ErrMalformed
+			}
+		}
+	}
+
+	return time.Duration(duration * float64(timestampScale)), nil
+}
+
+// Dimensions of the first video track
+func webMDimensions(data []byte) (int, int, error) {
+	tracks, parseErr := parseEbmlElements(data)
+	if parseErr != nil {
+		return 0, 0, parseErr
+	}
+
+	for _, track := range tracks {
+		if track.id != ebmlIdTrackEntry {
+			continue
+		}
+		fields, fieldsErr := parseEbmlElements(track.data)
+		if fieldsErr != nil {
+			return 0, 0, fieldsErr
+		}
+
+		isVideo := false
+		var video []byte
+		for _, field := range fields {
+			switch field.id {
+			case ebmlIdTrackType:
+				isVideo = ebmlUint(field.data) == matroskaTrackTypeVideo
+			case ebmlIdVideo:
+				video = field.data
+			}
+		}
+		if !isVideo || video == nil {
+			continue
+		}
+
+		videoFields, videoErr := parseEbmlElements(video)
+		if videoErr != nil {
+			return 0, 0, videoErr
+		}
+		width, height := 0, 0
+		for _, field := range videoFields {
+			switch field.id {
+			case ebmlIdPixelWidth:
+				width = int(ebmlUint(field.data))
+			case ebmlIdPixelHeight:
+				height = int(ebmlUint(field.data))
+			}
+		}
+		return width, height, nil
+	}
+
+	return 0, 0, nil
+}
+
+// Read the id and the size of the next element
+func readEbmlHeader(r io.Reader) (uint64, uint64, error) {
+	id, idErr := readVint(r, true)
+	if idErr != nil {
+		return 0, 0, idErr
+	}
+	size, sizeErr := readVint(r, false)
+	if sizeErr != nil {
+		return 0, 0, sizeErr
+	}
+	return id, size, nil
+}
+
+// Split data into the elements it is made of
+func parseEbmlElements(data []byte) ([]ebmlElement, error) {
+	elements := []ebmlElement{}
+	for len(data) > 0 {
+		reader := &sliceReader{data: data}
+		id, size, headerErr := readEbmlHeader(reader)
+		if headerErr != nil || size == ebmlUnknownSize || size > uint64(len(data)-reader.offset) {
+			return nil, ErrMalformed
+		}
+
+		end := reader.offset + int(size)
+		elements = append(elements, ebmlElement{id: id, data: data[reader.offset:end]})
+		data = data[end:]
+	}
+	return elements, nil
+}
+
+// Read a variable length integer. The length is the number of leading zero bits of the first byte plus one.
+// Ids keep the length marker bit, sizes do not.
+func readVint(r io.Reader, keepMarker bool) (uint64, error) {
+	first := make([]byte, 1)
+	if _, readErr := io.ReadFull(r, first); readErr != nil {
+		return 0, readErr
+	}
+
+	length := 1
+	for mask := byte(0x80); length <= 8 && first[0]&mask == 0; mask >>= 1 {
+		length++
+	}
+	if length > 8 {
+		return 0, ErrMalformed
+	}
+
+	value := uint64(first[0])
+	if !keepMarker {
+		value &= uint64(0xFF >> length)
+	}
+	allOnes := value == uint64(0xFF>>length)
+
+	rest := make([]byte, length-1)
+	if _, readErr := io.ReadFull(r, rest); readErr != nil {
+		return 0, ErrMalformed
+	}
+	for _, b := range rest {
+		value = value<<8 | uint64(b)
+		allOnes = allOnes && b == 0xFF
+	}
+
+	if !keepMarker && allOnes {
+		return ebmlUnknownSize, nil
+	}
+	return value, nil
+}
+
+// An unsigned integer element, big endian in up to 8 bytes
+func ebmlUint(data []byte) uint64 {
+	value := uint64(0)
+	for _, b := range data {
+		value = value<<8 | uint64(b)
+	}
+	return value
+}
+
+// Reads a byte slice and remembers how far it got
+type sliceReader struct {
+	data   []byte
+	offset int
+}
+
+func (reader *sliceReader) Read(p []byte) (int, error) {
+	if reader.offset >= len(reader.data) {
+		return 0, io.EOF
+	}
+	n := copy(p, reader.data[reader.offset:])
+	reader.offset += n
+	return n, nil
+}
@@ -0,0 +1,144 @@
+package ranking
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// How much each signal counts towards the score of a post
+type Weights struct {
+	// Added for every interest the post shares with the viewer
+	Interest float64
+	// Multiplied with log(1 + likes)
+	Like float64
+	// Multiplied with log(1 + comments)
+	Comment float64
+	// Age at which the score of a post is halved
+	HalfLife time.Duration
+}
+
+func DefaultWeights() Weights {
+	return Weights{
+		Interest: 3,
+		Like:     1,
+		Comment:  1.5,
+		HalfLife: 24 * time.Hour,
+	}
+}
+
+// Parse weights from strings, the way they come from the environment. Empty values keep the default.
+func ParseWeights(interest, like, comment, halfLife string) (Weights, error) {
+	weights := DefaultWeights()
+
+	floats := []struct {
+		value  string
+		weight *float64
+	}{
+		{interest, &weights.Interest},
+		{like, &weights.Like},
+		{comment, &weights.Comment},
+	}
+	for _, f := range floats {
+		if f.value == "" {
+			continue
+		}
+		parsed, err := strconv.ParseFloat(f.value, 64)
+		if err != nil || parsed < 0 {
+			return Weights{}, fmt.Errorf("invalid weight %v", f.value)
+		}
+		*f.weight = parsed
+	}
+
+	if halfLife != "" {
+		parsed, err := time.ParseDuration(halfLife)
+		if err != nil || parsed <= 0 {
+			return Weights{}, fmt.Errorf("invalid half life %v", halfLife)
+		}
+		weights.HalfLife = parsed
+	}
+
+	return weights, nil
+}
+
+// A post that can be ranked
+type Candidate struct {
+	ID           int64
+	CreatedAt    time.Time
+	LikeCount    int64
+	CommentCount int64
+	InterestIDs  []int64
+}
+
+// Score a post for a viewer.
+// Interest overlap and engagement add to a base of 1, and the total decays by half every HalfLife.
+func Score(candidate Candidate, viewerInterests map[int64]bool, now time.Time, weights Weights) float64 {
+	overlap := 0
+	for _, interestId := range candidate.InterestIDs {
+		if viewerInterests[interestId] {
+			overlap++
+		}
+	}
+
+	score := 1 +
+		weights.Interest*float64(overlap) +
+		weights.Like*math.Log1p(float64(candidate.LikeCount)) +
+		weights.Comment*math.Log1p(float64(candidate.CommentCount))
+
+	age := max(now.Sub(candidate.CreatedAt), 0)
+	decay := math.Pow(0.5, age.Hours()/weights.HalfLife.Hours())
+
+	return score * decay
+}
+
+// Sort the candidates by score, highest first. Equal scores are ordered newest first, then by id, so the order is always the same.
+func Rank(candidates []Candidate, viewerInterestIds []int64, now time.Time, weights Weights) []Candidate {
+	viewerInterests := map[int64]bool{}
+	for _, interestId := range viewerInterestIds {
+		viewerInterests[interestId] = true
+	}
+
+	scores := map[int64]float64{}
+	for _, candidate := range candidates {
+		scores[candidate.ID] = Score(candidate, viewerInterests, now, weights)
+	}
+
+	ranked := append([]Candidate{}, candidates...)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		a, b := ranked[i], ranked[j]
+		if scores[a.ID] != scores[b.ID] {
+			return scores[a.ID] > scores[b.ID]
+		}
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.After(b.CreatedAt)
+		}
+		return a.ID > b.ID
+	})
+	return ranked
+}
+
+var wordRegex = regexp.MustCompile(`[\p{L}\p{N}]+`)
+
+// Get the interests whose names appear in the content, sorted by id.
+// Names are matched case insensitively on whole words, so "art" does not match "start".
+func InferInterests(content string, interestNames map[int64]string) []int64 {
+	words := " " + strings.Join(wordRegex.FindAllString(strings.ToLower(content), -1), " ") + " "
+
+	interestIds := []int64{}
+	for interestId, name := range interestNames {
+		nameWords := wordRegex.FindAllString(strings.ToLower(name), -1)
+		if len(nameWords) == 0 {
+			continue
+		}
+		if strings.Contains(words, " "+strings.Join(nameWords, " ")+" ") {
+			interestIds = append(interestIds, interestId)
+		}
+	}
+
+	sort.Slice(interestIds, func(i, j int) bool { return interestIds[i] < interestIds[j] })
+	return interestIds
+}
@@ -0,0 +1,121 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Stores files in a directory on disk and serves them with ServeFile.
+// Used for local dev and tests so they run without S3.
+type LocalStorage struct {
+	Dir string
+	// Start of the urls ServeFile is reached under, ending with a slash, like http://localhost:8080/files/
+	BaseURL string
+}
+
+// Returned for keys that would point outside of the directory
+var ErrInvalidKey = errors.New("invalid storage key")
+
+func (storage *LocalStorage) Put(ctx context.Context, key string, body io.Reader, contentType string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	path, pathErr := storage.path(key)
+	if pathErr != nil {
+		return pathErr
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("error creating storage directory %w", err)
+	}
+
+	// Write next to the file and rename, so a half written file is never served
+	tmpFile, tmpErr := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if tmpErr != nil {
+		return fmt.Errorf("error creating %v %w", key, tmpErr)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if _, copyErr := io.Copy(tmpFile, body); copyErr != nil {
+		tmpFile.Close()
+		return fmt.Errorf("error writing %v %w", key, copyErr)
+	}
+	if closeErr := tmpFile.Close(); closeErr != nil {
+		return fmt.Errorf("error writing %v %w", key, closeErr)
+	}
+	if renameErr := os.Rename(tmpFile.Name(), path); renameErr != nil {
+		return fmt.Errorf("error writing %v %w", key, renameErr)
+	}
+
+	return nil
+}
+
+func (storage *LocalStorage) Delete(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	path, pathErr := storage.path(key)
+	if pathErr != nil {
+		return pathErr
+	}
+	if removeErr := os.Remove(path); removeErr != nil && !errors.Is(removeErr, fs.ErrNotExist) {
+		return fmt.Errorf("error deleting %v %w", key, removeErr)
+	}
+	return nil
+}
+
+func (storage *LocalStorage) URL(key string) string {
+	return storage.BaseURL + key
+}
+
+// Local files are always public, so the url does not expire
+func (storage *LocalStorage) Presign(ctx context.Context, key string, expires time.Duration) (string, error) {
+	if _, pathErr := storage.path(key); pathErr != nil {
+		return "", pathErr
+	}
+	return storage.URL(key), nil
+}
+
+// Serve a stored file. The route must have a {key...} wildcard, like GET /files/{key...}.
+// Directories are not listed.
+func (storage *LocalStorage) ServeFile(writer http.ResponseWriter, request *http.Request) {
+	path, pathErr := storage.path(request.PathValue("key"))
+	if pathErr != nil {
+		http.NotFound(writer, request)
+		return
+	}
+	info, statErr := os.Stat(path)
+	if statErr != nil || !info.Mode().IsRegular() {
+		http.NotFound(writer, request)
+		return
+	}
+
+	// The files come from users and are served from the api's own origin, so they must never run as a page
+	writer.Header().Set("X-Content-Type-Options", "nosniff")
+	writer.Header().Set("Content-Security-Policy", "sandbox")
+
+	file, openErr := os.Open(path)
+	if openErr != nil {
+		http.NotFound(writer, request)
+		return
+	}
+	defer file.Close()
+	http.ServeContent(writer, request, info.Name(), info.ModTime(), file)
+}
+
+// Path of the file of key inside Dir
+func (storage *LocalStorage) path(key string) (string, error) {
+	if key == "" || strings.Contains(key, `\`) || !filepath.IsLocal(filepath.FromSlash(key)) {
+		return "", ErrInvalidKey
+	}
+	return filepath.Join(storage.Dir, filepath.FromSlash(key)), nil
+}
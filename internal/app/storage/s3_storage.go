@@ -0,0 +1,87 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// Stores files in an S3 bucket, or a bucket of an S3 compatible server like MinIO
+type S3Storage struct {
+	Client *s3.Client
+	Bucket string
+	// Start of the public urls of the files, ending with a slash
+	BaseURL string
+}
+
+// Storage in an AWS S3 bucket in the region of awsCfg
+func NewS3Storage(awsCfg aws.Config, bucket string) *S3Storage {
+	return &S3Storage{
+		Client:  s3.NewFromConfig(awsCfg),
+		Bucket:  bucket,
+		BaseURL: fmt.Sprintf("https://%v.s3.%v.amazonaws.com/", bucket, awsCfg.Region),
+	}
+}
+
+// Storage in a bucket of an S3 compatible server at endpoint, like http://localhost:9000 for MinIO.
+// Buckets are addressed by path, since such servers usually do not have a domain per bucket.
+// publicUrl is where clients reach the server when it differs from endpoint, like behind a proxy. Empty uses endpoint.
+func NewS3CompatibleStorage(awsCfg aws.Config, bucket string, endpoint string, publicUrl string) *S3Storage {
+	client := s3.NewFromConfig(awsCfg, func(options *s3.Options) {
+		options.BaseEndpoint = aws.String(endpoint)
+		options.UsePathStyle = true
+	})
+
+	if publicUrl == "" {
+		publicUrl = endpoint
+	}
+	return &S3Storage{
+		Client:  client,
+		Bucket:  bucket,
+		BaseURL: fmt.Sprintf("%v/%v/", strings.TrimSuffix(publicUrl, "/"), bucket),
+	}
+}
+
+func (storage *S3Storage) Put(ctx context.Context, key string, body io.Reader, contentType string) error {
+	_, putErr := storage.Client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(storage.Bucket),
+		Key:         aws.String(key),
+		Body:        body,
+		ContentType: aws.String(contentType),
+	})
+	if putErr != nil {
+		return fmt.Errorf("error uploading %v to s3 %w", key, putErr)
+	}
+	return nil
+}
+
+func (storage *S3Storage) Delete(ctx context.Context, key string) error {
+	_, deleteErr := storage.Client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(storage.Bucket),
+		Key:    aws.String(key),
+	})
+	if deleteErr != nil {
+		return fmt.Errorf("error deleting %v from s3 %w", key, deleteErr)
+	}
+	return nil
+}
+
+func (storage *S3Storage) URL(key string) string {
+	return storage.BaseURL + key
+}
+
+func (storage *S3Storage) Presign(ctx context.Context, key string, expires time.Duration) (string, error) {
+	presigned, presignErr := s3.NewPresignClient(storage.Client).PresignGetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(storage.Bucket),
+		Key:    aws.String(key),
+	}, s3.WithPresignExpires(expires))
+	if presignErr != nil {
+		return "", fmt.Errorf("error presigning %v %w", key, presignErr)
+	}
+	return presigned.URL, nil
+}
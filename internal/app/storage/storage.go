@@ -0,0 +1,31 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"strings"
+	"time"
+)
+
+// Stores uploaded files under keys like "images/<name>.jpg".
+// Use S3Storage in production, S3Storage with a custom endpoint for MinIO and LocalStorage for dev and tests.
+type Storage interface {
+	// Save the file under key, replacing what was there
+	Put(ctx context.Context, key string, body io.Reader, contentType string) error
+	// Remove the file. Deleting a file that does not exist is not an error.
+	Delete(ctx context.Context, key string) error
+	// Public download url of the file. URL("") is the prefix every url of this storage starts with.
+	URL(key string) string
+	// Download url of the file that stops working after expires, for files that are not public
+	Presign(ctx context.Context, key string, expires time.Duration) (string, error)
+}
+
+// The key of a url made by the URL method of the storage.
+// false for urls of other storages or other sites, whose files are not ours to delete.
+func KeyFromURL(storage Storage, downloadUrl string) (string, bool) {
+	key, found := strings.CutPrefix(downloadUrl, storage.URL(""))
+	if !found || key == "" {
+		return "", false
+	}
+	return key, true
+}
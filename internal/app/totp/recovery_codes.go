@@ -0,0 +1,29 @@
+package totp
+
+import (
+	"crypto/rand"
+	"fmt"
+	"strings"
+)
+
+// Generate single use recovery codes in the format XXXX-XXXX-XXXX-XXXX (80 bits each)
+func GenerateRecoveryCodes(count int) ([]string, error) {
+	codes := []string{}
+	for i := 0; i < count; i++ {
+		randomBytes := make([]byte, 10)
+		if _, err := rand.Read(randomBytes); err != nil {
+			return nil, fmt.Errorf("error generating recovery code %w", err)
+		}
+
+		encoded := base32NoPadding.EncodeToString(randomBytes)
+		codes = append(codes, fmt.Sprintf("%v-%v-%v-%v", encoded[0:4], encoded[4:8], encoded[8:12], encoded[12:16]))
+	}
+
+	return codes, nil
+}
+
+// Normalize a recovery code typed by the user before hashing it.
+// Dashes, spaces and case do not matter.
+func NormalizeRecoveryCode(code string) string {
+	return strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(code))
+}
@@ -4,11 +4,22 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"net/mail"
 	"strconv"
 
 	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/database"
 )
 
+// Validate email format. Display names like "Name <email>" are not accepted.
+func ValidateEmail(email string) error {
+	address, err := mail.ParseAddress(email)
+	if err != nil || address.Address != email {
+		return errors.New("email is not valid")
+	}
+
+	return nil
+}
+
 // Validate update user request
 func ValidateUpdateUserRequest(request *http.Request, db *database.Queries) (int, error) {
 	fullName := request.FormValue("full_name")
@@ -23,6 +34,10 @@ func ValidateUpdateUserRequest(request *http.Request, db *database.Queries) (int
 		return http.StatusBadRequest, errors.New("user name is requred")
 	}
 
+	if userNameErr := ValidateUserName(userName); userNameErr != nil {
+		return http.StatusBadRequest, userNameErr
+	}
+
 	if dob == "" {
 		return http.StatusBadRequest, errors.New("dob is requred")
 	}
@@ -0,0 +1,59 @@
+package validators
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/database"
+)
+
+// Longest interest name admins can give
+const maxInterestNameLength = 50
+
+// Check that every interest id exists and is not deleted. Returns the ids without duplicates, in the order they were sent.
+func ValidateInterestIds(ctx context.Context, ids []int64, db *database.Queries) ([]int64, int, error) {
+	interestIds := []int64{}
+	seen := map[int64]bool{}
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			interestIds = append(interestIds, id)
+		}
+	}
+	if len(interestIds) == 0 {
+		return interestIds, 0, nil
+	}
+
+	existingInterestIds, getExistingInterestIdsErr := db.GetExistingInterestIds(ctx, interestIds)
+	if getExistingInterestIdsErr != nil {
+		return nil, http.StatusInternalServerError, getExistingInterestIdsErr
+	}
+	if len(existingInterestIds) != len(interestIds) {
+		existingIdsMap := map[int64]bool{}
+		for _, existingId := range existingInterestIds {
+			existingIdsMap[existingId] = true
+		}
+		for _, interestId := range interestIds {
+			if !existingIdsMap[interestId] {
+				return nil, http.StatusNotFound, fmt.Errorf("interest id does not exist : %v", interestId)
+			}
+		}
+	}
+
+	return interestIds, 0, nil
+}
+
+// Validate the name of an interest. Returns the name without surrounding spaces.
+func ValidateInterestName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", errors.New("name is required")
+	}
+	if len([]rune(name)) > maxInterestNameLength {
+		return "", fmt.Errorf("name cannot be longer than %v characters", maxInterestNameLength)
+	}
+	return name, nil
+}
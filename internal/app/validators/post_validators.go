@@ -0,0 +1,28 @@
+package validators
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/database"
+)
+
+// Validate the interest_ids the author tagged a post with. Returns the ids without duplicates.
+// The multipart form must be parsed before calling this.
+func ValidatePostInterestIds(request *http.Request, db *database.Queries) ([]int64, int, error) {
+	if request.MultipartForm == nil {
+		return []int64{}, 0, nil
+	}
+
+	interestIds := []int64{}
+	for _, interestId := range request.MultipartForm.Value["interest_ids"] {
+		parsedId, parseErr := strconv.ParseInt(interestId, 10, 64)
+		if parseErr != nil {
+			return nil, http.StatusBadRequest, fmt.Errorf("interest id must be a number : %v", interestId)
+		}
+		interestIds = append(interestIds, parsedId)
+	}
+
+	return ValidateInterestIds(request.Context(), interestIds, db)
+}
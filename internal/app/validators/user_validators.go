@@ -0,0 +1,132 @@
+package validators
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const minUserNameLength = 3
+const maxUserNameLength = 30
+
+// Letters, digits, underscores and periods. Must start with a letter or a digit.
+var userNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.]*$`)
+
+// User names that could be mistaken for the app or for a route. Compared ignoring case.
+var reservedUserNames = map[string]bool{
+	"about":         true,
+	"admin":         true,
+	"administrator": true,
+	"api":           true,
+	"feed":          true,
+	"help":          true,
+	"login":         true,
+	"logout":        true,
+	"me":            true,
+	"moderator":     true,
+	"null":          true,
+	"register":      true,
+	"root":          true,
+	"sanctuary":     true,
+	"settings":      true,
+	"staff":         true,
+	"support":       true,
+	"system":        true,
+	"undefined":     true,
+}
+
+// Fields of a PATCH /api/me request. A nil field was not sent and stays as it is.
+type ProfilePatch struct {
+	FullName *string
+	UserName *string
+	Dob      *time.Time
+	// The dob was sent as null and should be removed
+	ClearDob bool
+}
+
+// Parse a JSON merge patch for the profile. Only full_name, user_name and dob can be patched.
+// full_name and user_name cannot be removed. dob is removed by sending null.
+func ParseProfilePatch(body []byte, dobLayout string, now time.Time) (ProfilePatch, error) {
+	patch := ProfilePatch{}
+
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return patch, errors.New("the body must be a json object")
+	}
+
+	for name, value := range fields {
+		isNull := bytes.Equal(bytes.TrimSpace(value), []byte("null"))
+
+		switch name {
+		case "full_name", "user_name":
+			if isNull {
+				return patch, fmt.Errorf("%v cannot be removed", name)
+			}
+			text := ""
+			if err := json.Unmarshal(value, &text); err != nil {
+				return patch, fmt.Errorf("%v must be a string", name)
+			}
+			text = strings.TrimSpace(text)
+			if text == "" {
+				return patch, fmt.Errorf("%v cannot be empty", name)
+			}
+			if name == "full_name" {
+				patch.FullName = &text
+			} else {
+				if userNameErr := ValidateUserName(text); userNameErr != nil {
+					return patch, userNameErr
+				}
+				patch.UserName = &text
+			}
+		case "dob":
+			if isNull {
+				patch.ClearDob = true
+				continue
+			}
+			dobString := ""
+			if err := json.Unmarshal(value, &dobString); err != nil {
+				return patch, errors.New("dob must be a string")
+			}
+			dob, parseErr := time.Parse(dobLayout, dobString)
+			if parseErr != nil {
+				return patch, fmt.Errorf("dob must be in the format %v", dobLayout)
+			}
+			if !dob.Before(now) {
+				return patch, errors.New("dob must be in the past")
+			}
+			patch.Dob = &dob
+		default:
+			return patch, fmt.Errorf("%v cannot be updated", name)
+		}
+	}
+
+	return patch, nil
+}
+
+// Whether the patch changes anything
+func (patch ProfilePatch) IsEmpty() bool {
+	return patch.FullName == nil && patch.UserName == nil && patch.Dob == nil && !patch.ClearDob
+}
+
+// Validate a user name against the handle rules: 3 to 30 letters, digits, underscores or periods,
+// starting with a letter or a digit, no period at the end or twice in a row, and not a reserved word.
+func ValidateUserName(userName string) error {
+	length := len(userName)
+	if length < minUserNameLength || length > maxUserNameLength {
+		return fmt.Errorf("user name must be %v to %v characters long", minUserNameLength, maxUserNameLength)
+	}
+	if !userNamePattern.MatchString(userName) {
+		return errors.New("user name can only have letters, digits, underscores and periods, and must start with a letter or a digit")
+	}
+	if strings.HasSuffix(userName, ".") || strings.Contains(userName, "..") {
+		return errors.New("user name cannot end with a period or have two periods in a row")
+	}
+	if reservedUserNames[strings.ToLower(userName)] {
+		return errors.New("user name is reserved")
+	}
+	return nil
+}
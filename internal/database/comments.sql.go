@@ -53,38 +53,55 @@ SELECT
     c.user_id,
     c.post_id,
     u.id AS author_id, 
-    u.email AS author_email, 
     u.user_name AS author_user_name,
     u.full_name AS author_full_name,
     u.profile_image_url AS author_profile_image_url,
-    u.dob AS author_dob,
-    u.created_at AS author_created_at, 
-    u.updated_at AS author_updated_at
+    u.profile_image_feed_url AS author_profile_image_feed_url,
+    u.profile_image_thumbnail_url AS author_profile_image_thumbnail_url,
+    u.created_at AS author_created_at,
+    (
+        SELECT EXISTS(
+            SELECT 1 FROM follows af WHERE af.follower_id = $1 AND af.followee_id = u.id
+        )
+    ) AS author_is_following,
+    (
+        SELECT EXISTS(
+            SELECT 1 FROM follows ay WHERE ay.follower_id = u.id AND ay.followee_id = $1
+        )
+    ) AS author_follows_you
 FROM comments c 
 INNER JOIN users u ON c.user_id = u.id
-WHERE c.post_id = $1
+WHERE c.post_id = $2 AND u.deleted_at IS NULL AND u.deactivated_at IS NULL
+    AND NOT EXISTS(SELECT 1 FROM user_blocks vb WHERE vb.blocker_id = $1 AND vb.blocked_id = c.user_id)
+    AND NOT EXISTS(SELECT 1 FROM user_mutes vm WHERE vm.muter_id = $1 AND vm.muted_id = c.user_id)
 ORDER BY c.created_at ASC
 `
 
+type GetCommentsForPostParams struct {
+	ViewerID int64
+	PostID   int64
+}
+
 type GetCommentsForPostRow struct {
-	ID                    int64
-	Content               string
-	CreatedAt             pgtype.Timestamp
-	UpdatedAt             pgtype.Timestamp
-	UserID                int64
-	PostID                int64
-	AuthorID              int64
-	AuthorEmail           string
-	AuthorUserName        string
-	AuthorFullName        string
-	AuthorProfileImageUrl pgtype.Text
-	AuthorDob             pgtype.Date
-	AuthorCreatedAt       pgtype.Timestamp
-	AuthorUpdatedAt       pgtype.Timestamp
+	ID                             int64
+	Content                        string
+	CreatedAt                      pgtype.Timestamp
+	UpdatedAt                      pgtype.Timestamp
+	UserID                         int64
+	PostID                         int64
+	AuthorID                       int64
+	AuthorUserName                 string
+	AuthorFullName                 string
+	AuthorProfileImageUrl          pgtype.Text
+	AuthorProfileImageFeedUrl      pgtype.Text
+	AuthorProfileImageThumbnailUrl pgtype.Text
+	AuthorCreatedAt                pgtype.Timestamp
+	AuthorIsFollowing              bool
+	AuthorFollowsYou               bool
 }
 
-func (q *Queries) GetCommentsForPost(ctx context.Context, postID int64) ([]GetCommentsForPostRow, error) {
-	rows, err := q.db.Query(ctx, getCommentsForPost, postID)
+func (q *Queries) GetCommentsForPost(ctx context.Context, arg GetCommentsForPostParams) ([]GetCommentsForPostRow, error) {
+	rows, err := q.db.Query(ctx, getCommentsForPost, arg.ViewerID, arg.PostID)
 	if err != nil {
 		return nil, err
 	}
@@ -100,13 +117,222 @@ func (q *Queries) GetCommentsForPost(ctx context.Context, postID int64) ([]GetCo
 			&i.UserID,
 			&i.PostID,
 			&i.AuthorID,
-			&i.AuthorEmail,
 			&i.AuthorUserName,
 			&i.AuthorFullName,
 			&i.AuthorProfileImageUrl,
-			&i.AuthorDob,
+			&i.AuthorProfileImageFeedUrl,
+			&i.AuthorProfileImageThumbnailUrl,
+			&i.AuthorCreatedAt,
+			&i.AuthorIsFollowing,
+			&i.AuthorFollowsYou,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getCommentsForPostAfter = `-- name: GetCommentsForPostAfter :many
+SELECT
+    c.id,
+    c.content,
+    c.created_at,
+    c.updated_at,
+    c.user_id,
+    c.post_id,
+    u.id AS author_id, 
+    u.user_name AS author_user_name,
+    u.full_name AS author_full_name,
+    u.profile_image_url AS author_profile_image_url,
+    u.profile_image_feed_url AS author_profile_image_feed_url,
+    u.profile_image_thumbnail_url AS author_profile_image_thumbnail_url,
+    u.created_at AS author_created_at,
+    (
+        SELECT EXISTS(
+            SELECT 1 FROM follows af WHERE af.follower_id = $1 AND af.followee_id = u.id
+        )
+    ) AS author_is_following,
+    (
+        SELECT EXISTS(
+            SELECT 1 FROM follows ay WHERE ay.follower_id = u.id AND ay.followee_id = $1
+        )
+    ) AS author_follows_you
+FROM comments c 
+INNER JOIN users u ON c.user_id = u.id
+WHERE c.post_id = $2
+    AND (c.created_at, c.id) > ($3::timestamp, $4::bigint)
+    AND u.deleted_at IS NULL AND u.deactivated_at IS NULL
+    AND NOT EXISTS(SELECT 1 FROM user_blocks vb WHERE vb.blocker_id = $1 AND vb.blocked_id = c.user_id)
+    AND NOT EXISTS(SELECT 1 FROM user_mutes vm WHERE vm.muter_id = $1 AND vm.muted_id = c.user_id)
+ORDER BY c.created_at ASC, c.id ASC
+LIMIT $5
+`
+
+type GetCommentsForPostAfterParams struct {
+	ViewerID        int64
+	PostID          int64
+	CursorCreatedAt pgtype.Timestamp
+	CursorID        int64
+	PageLimit       int32
+}
+
+type GetCommentsForPostAfterRow struct {
+	ID                             int64
+	Content                        string
+	CreatedAt                      pgtype.Timestamp
+	UpdatedAt                      pgtype.Timestamp
+	UserID                         int64
+	PostID                         int64
+	AuthorID                       int64
+	AuthorUserName                 string
+	AuthorFullName                 string
+	AuthorProfileImageUrl          pgtype.Text
+	AuthorProfileImageFeedUrl      pgtype.Text
+	AuthorProfileImageThumbnailUrl pgtype.Text
+	AuthorCreatedAt                pgtype.Timestamp
+	AuthorIsFollowing              bool
+	AuthorFollowsYou               bool
+}
+
+func (q *Queries) GetCommentsForPostAfter(ctx context.Context, arg GetCommentsForPostAfterParams) ([]GetCommentsForPostAfterRow, error) {
+	rows, err := q.db.Query(ctx, getCommentsForPostAfter,
+		arg.ViewerID,
+		arg.PostID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetCommentsForPostAfterRow
+	for rows.Next() {
+		var i GetCommentsForPostAfterRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Content,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.UserID,
+			&i.PostID,
+			&i.AuthorID,
+			&i.AuthorUserName,
+			&i.AuthorFullName,
+			&i.AuthorProfileImageUrl,
+			&i.AuthorProfileImageFeedUrl,
+			&i.AuthorProfileImageThumbnailUrl,
+			&i.AuthorCreatedAt,
+			&i.AuthorIsFollowing,
+			&i.AuthorFollowsYou,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getCommentsForPostBefore = `-- name: GetCommentsForPostBefore :many
+SELECT
+    c.id,
+    c.content,
+    c.created_at,
+    c.updated_at,
+    c.user_id,
+    c.post_id,
+    u.id AS author_id, 
+    u.user_name AS author_user_name,
+    u.full_name AS author_full_name,
+    u.profile_image_url AS author_profile_image_url,
+    u.profile_image_feed_url AS author_profile_image_feed_url,
+    u.profile_image_thumbnail_url AS author_profile_image_thumbnail_url,
+    u.created_at AS author_created_at,
+    (
+        SELECT EXISTS(
+            SELECT 1 FROM follows af WHERE af.follower_id = $1 AND af.followee_id = u.id
+        )
+    ) AS author_is_following,
+    (
+        SELECT EXISTS(
+            SELECT 1 FROM follows ay WHERE ay.follower_id = u.id AND ay.followee_id = $1
+        )
+    ) AS author_follows_you
+FROM comments c 
+INNER JOIN users u ON c.user_id = u.id
+WHERE c.post_id = $2
+    AND (c.created_at, c.id) < ($3::timestamp, $4::bigint)
+    AND u.deleted_at IS NULL AND u.deactivated_at IS NULL
+    AND NOT EXISTS(SELECT 1 FROM user_blocks vb WHERE vb.blocker_id = $1 AND vb.blocked_id = c.user_id)
+    AND NOT EXISTS(SELECT 1 FROM user_mutes vm WHERE vm.muter_id = $1 AND vm.muted_id = c.user_id)
+ORDER BY c.created_at DESC, c.id DESC
+LIMIT $5
+`
+
+type GetCommentsForPostBeforeParams struct {
+	ViewerID        int64
+	PostID          int64
+	CursorCreatedAt pgtype.Timestamp
+	CursorID        int64
+	PageLimit       int32
+}
+
+type GetCommentsForPostBeforeRow struct {
+	ID                             int64
+	Content                        string
+	CreatedAt                      pgtype.Timestamp
+	UpdatedAt                      pgtype.Timestamp
+	UserID                         int64
+	PostID                         int64
+	AuthorID                       int64
+	AuthorUserName                 string
+	AuthorFullName                 string
+	AuthorProfileImageUrl          pgtype.Text
+	AuthorProfileImageFeedUrl      pgtype.Text
+	AuthorProfileImageThumbnailUrl pgtype.Text
+	AuthorCreatedAt                pgtype.Timestamp
+	AuthorIsFollowing              bool
+	AuthorFollowsYou               bool
+}
+
+func (q *Queries) GetCommentsForPostBefore(ctx context.Context, arg GetCommentsForPostBeforeParams) ([]GetCommentsForPostBeforeRow, error) {
+	rows, err := q.db.Query(ctx, getCommentsForPostBefore,
+		arg.ViewerID,
+		arg.PostID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetCommentsForPostBeforeRow
+	for rows.Next() {
+		var i GetCommentsForPostBeforeRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Content,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.UserID,
+			&i.PostID,
+			&i.AuthorID,
+			&i.AuthorUserName,
+			&i.AuthorFullName,
+			&i.AuthorProfileImageUrl,
+			&i.AuthorProfileImageFeedUrl,
+			&i.AuthorProfileImageThumbnailUrl,
 			&i.AuthorCreatedAt,
-			&i.AuthorUpdatedAt,
+			&i.AuthorIsFollowing,
+			&i.AuthorFollowsYou,
 		); err != nil {
 			return nil, err
 		}
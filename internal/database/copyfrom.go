@@ -9,6 +9,75 @@ import (
 	"context"
 )
 
+// iteratorForCreatePostInterests implements pgx.CopyFromSource.
+type iteratorForCreatePostInterests struct {
+	rows                 []CreatePostInterestsParams
+	skippedFirstNextCall bool
+}
+
+func (r *iteratorForCreatePostInterests) Next() bool {
+	if len(r.rows) == 0 {
+		return false
+	}
+	if !r.skippedFirstNextCall {
+		r.skippedFirstNextCall = true
+		return true
+	}
+	r.rows = r.rows[1:]
+	return len(r.rows) > 0
+}
+
+func (r iteratorForCreatePostInterests) Values() ([]interface{}, error) {
+	return []interface{}{
+		r.rows[0].PostID,
+		r.rows[0].InterestID,
+		r.rows[0].Inferred,
+		r.rows[0].CreatedAt,
+	}, nil
+}
+
+func (r iteratorForCreatePostInterests) Err() error {
+	return nil
+}
+
+func (q *Queries) CreatePostInterests(ctx context.Context, arg []CreatePostInterestsParams) (int64, error) {
+	return q.db.CopyFrom(ctx, []string{"post_interests"}, []string{"post_id", "interest_id", "inferred", "created_at"}, &iteratorForCreatePostInterests{rows: arg})
+}
+
+// iteratorForCreateRecoveryCodes implements pgx.CopyFromSource.
+type iteratorForCreateRecoveryCodes struct {
+	rows                 []CreateRecoveryCodesParams
+	skippedFirstNextCall bool
+}
+
+func (r *iteratorForCreateRecoveryCodes) Next() bool {
+	if len(r.rows) == 0 {
+		return false
+	}
+	if !r.skippedFirstNextCall {
+		r.skippedFirstNextCall = true
+		return true
+	}
+	r.rows = r.rows[1:]
+	return len(r.rows) > 0
+}
+
+func (r iteratorForCreateRecoveryCodes) Values() ([]interface{}, error) {
+	return []interface{}{
+		r.rows[0].UserID,
+		r.rows[0].CodeHash,
+		r.rows[0].CreatedAt,
+	}, nil
+}
+
+func (r iteratorForCreateRecoveryCodes) Err() error {
+	return nil
+}
+
+func (q *Queries) CreateRecoveryCodes(ctx context.Context, arg []CreateRecoveryCodesParams) (int64, error) {
+	return q.db.CopyFrom(ctx, []string{"recovery_codes"}, []string{"user_id", "code_hash", "created_at"}, &iteratorForCreateRecoveryCodes{rows: arg})
+}
+
 // iteratorForCreateUsersHasInterests implements pgx.CopyFromSource.
 type iteratorForCreateUsersHasInterests struct {
 	rows                 []CreateUsersHasInterestsParams
@@ -9,8 +9,32 @@ import (
 	"context"
 )
 
+const createInterest = `-- name: CreateInterest :one
+INSERT INTO interests(name, created_at, updated_at)
+VALUES(
+    $1,
+    NOW(),
+    NOW()
+)
+RETURNING id, name, created_at, updated_at, deleted_at
+`
+
+func (q *Queries) CreateInterest(ctx context.Context, name string) (Interest, error) {
+	row := q.db.QueryRow(ctx, createInterest, name)
+	var i Interest
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
 const getAllInterests = `-- name: GetAllInterests :many
 SELECT id, name, created_at, updated_at, deleted_at FROM interests
+WHERE deleted_at IS NULL
 `
 
 func (q *Queries) GetAllInterests(ctx context.Context) ([]Interest, error) {
@@ -41,7 +65,7 @@ func (q *Queries) GetAllInterests(ctx context.Context) ([]Interest, error) {
 
 const getExistingInterestIds = `-- name: GetExistingInterestIds :many
 SELECT id FROM interests
-WHERE id = ANY($1::bigint[])
+WHERE id = ANY($1::bigint[]) AND deleted_at IS NULL
 `
 
 func (q *Queries) GetExistingInterestIds(ctx context.Context, dollar_1 []int64) ([]int64, error) {
@@ -64,11 +88,29 @@ func (q *Queries) GetExistingInterestIds(ctx context.Context, dollar_1 []int64)
 	return items, nil
 }
 
+const getInterestById = `-- name: GetInterestById :one
+SELECT id, name, created_at, updated_at, deleted_at FROM interests
+WHERE id = $1 AND deleted_at IS NULL
+`
+
+func (q *Queries) GetInterestById(ctx context.Context, id int64) (Interest, error) {
+	row := q.db.QueryRow(ctx, getInterestById, id)
+	var i Interest
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
 const getInterestsForUser = `-- name: GetInterestsForUser :many
 SELECT interests.id, interests.name, interests.created_at, interests.updated_at, interests.deleted_at FROM interests
 INNER JOIN users_has_interests 
 ON interests.id = users_has_interests.interest_id
-WHERE users_has_interests.user_id = $1
+WHERE users_has_interests.user_id = $1 AND interests.deleted_at IS NULL
 `
 
 func (q *Queries) GetInterestsForUser(ctx context.Context, userID int64) ([]Interest, error) {
@@ -96,3 +138,40 @@ func (q *Queries) GetInterestsForUser(ctx context.Context, userID int64) ([]Inte
 	}
 	return items, nil
 }
+
+const renameInterest = `-- name: RenameInterest :one
+UPDATE interests SET name = $2, updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING id, name, created_at, updated_at, deleted_at
+`
+
+type RenameInterestParams struct {
+	ID   int64
+	Name string
+}
+
+func (q *Queries) RenameInterest(ctx context.Context, arg RenameInterestParams) (Interest, error) {
+	row := q.db.QueryRow(ctx, renameInterest, arg.ID, arg.Name)
+	var i Interest
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const softDeleteInterest = `-- name: SoftDeleteInterest :execrows
+UPDATE interests SET deleted_at = NOW(), updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL
+`
+
+func (q *Queries) SoftDeleteInterest(ctx context.Context, id int64) (int64, error) {
+	result, err := q.db.Exec(ctx, softDeleteInterest, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
@@ -8,6 +8,17 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type AccountLockout struct {
+	ID             int64
+	UserID         pgtype.Int8
+	Email          string
+	IpAddress      string
+	Reason         string
+	FailedAttempts int64
+	LockedUntil    pgtype.Timestamp
+	CreatedAt      pgtype.Timestamp
+}
+
 type Comment struct {
 	ID        int64
 	Content   string
@@ -18,6 +29,30 @@ type Comment struct {
 	PostID    int64
 }
 
+type EmailChangeRequest struct {
+	ID        int64
+	UserID    int64
+	NewEmail  string
+	TokenHash string
+	ExpiresAt pgtype.Timestamp
+	UsedAt    pgtype.Timestamp
+	CreatedAt pgtype.Timestamp
+}
+
+type Follow struct {
+	ID         int64
+	FollowerID int64
+	FolloweeID int64
+	CreatedAt  pgtype.Timestamp
+}
+
+type FollowRequest struct {
+	ID          int64
+	RequesterID int64
+	TargetID    int64
+	CreatedAt   pgtype.Timestamp
+}
+
 type Interest struct {
 	ID        int64
 	Name      string
@@ -26,6 +61,23 @@ type Interest struct {
 	DeletedAt pgtype.Timestamp
 }
 
+type LoginAttempt struct {
+	ID        int64
+	Email     string
+	IpAddress string
+	Succeeded bool
+	CreatedAt pgtype.Timestamp
+}
+
+type PasswordResetToken struct {
+	ID        int64
+	UserID    int64
+	TokenHash string
+	ExpiresAt pgtype.Timestamp
+	UsedAt    pgtype.Timestamp
+	CreatedAt pgtype.Timestamp
+}
+
 type Post struct {
 	ID        int64
 	Content   string
@@ -35,6 +87,13 @@ type Post struct {
 	UserID    int64
 }
 
+type PostInterest struct {
+	PostID     int64
+	InterestID int64
+	Inferred   bool
+	CreatedAt  pgtype.Timestamp
+}
+
 type PostLike struct {
 	ID        int64
 	UserID    int64
@@ -44,26 +103,102 @@ type PostLike struct {
 }
 
 type PostMedium struct {
-	ID         int64
-	MediaUrl   string
-	OrderIndex int32
-	CreatedAt  pgtype.Timestamp
-	UpdatedAt  pgtype.Timestamp
-	DeletedAt  pgtype.Timestamp
-	PostID     int64
+	ID           int64
+	MediaUrl     string
+	OrderIndex   int32
+	CreatedAt    pgtype.Timestamp
+	UpdatedAt    pgtype.Timestamp
+	DeletedAt    pgtype.Timestamp
+	PostID       int64
+	Width        pgtype.Int4
+	Height       pgtype.Int4
+	MediaType    string
+	DurationMs   pgtype.Int4
+	PosterUrl    pgtype.Text
+	ThumbnailUrl pgtype.Text
+	FeedUrl      pgtype.Text
+}
+
+type PostRevision struct {
+	ID        int64
+	PostID    int64
+	Content   string
+	CreatedAt pgtype.Timestamp
+}
+
+type RecoveryCode struct {
+	ID        int64
+	UserID    int64
+	CodeHash  string
+	UsedAt    pgtype.Timestamp
+	CreatedAt pgtype.Timestamp
+}
+
+type RevokedToken struct {
+	ID        int64
+	Jti       string
+	UserID    int64
+	ExpiresAt pgtype.Timestamp
+	CreatedAt pgtype.Timestamp
+}
+
+type Session struct {
+	ID        int64
+	UserID    int64
+	FamilyID  string
+	TokenHash string
+	ExpiresAt pgtype.Timestamp
+	UsedAt    pgtype.Timestamp
+	RevokedAt pgtype.Timestamp
+	CreatedAt pgtype.Timestamp
+	UpdatedAt pgtype.Timestamp
 }
 
 type User struct {
-	ID              int64
-	Email           string
-	UserName        string
-	FullName        string
-	ProfileImageUrl pgtype.Text
-	Dob             pgtype.Date
-	HashedPassword  string
-	CreatedAt       pgtype.Timestamp
-	UpdatedAt       pgtype.Timestamp
-	DeletedAt       pgtype.Timestamp
+	ID                       int64
+	Email                    string
+	UserName                 string
+	FullName                 string
+	ProfileImageUrl          pgtype.Text
+	Dob                      pgtype.Date
+	HashedPassword           string
+	CreatedAt                pgtype.Timestamp
+	UpdatedAt                pgtype.Timestamp
+	DeletedAt                pgtype.Timestamp
+	TokenVersion             int32
+	EmailVerifiedAt          pgtype.Timestamp
+	VerificationSentAt       pgtype.Timestamp
+	TotpSecret               pgtype.Text
+	TotpEnabledAt            pgtype.Timestamp
+	TotpLastStep             pgtype.Int8
+	DeactivatedAt            pgtype.Timestamp
+	IsPrivate                bool
+	IsAdmin                  bool
+	UserNameChangedAt        pgtype.Timestamp
+	ProfileImageThumbnailUrl pgtype.Text
+	ProfileImageFeedUrl      pgtype.Text
+}
+
+type UserBlock struct {
+	ID        int64
+	BlockerID int64
+	BlockedID int64
+	CreatedAt pgtype.Timestamp
+}
+
+type UserMute struct {
+	ID        int64
+	MuterID   int64
+	MutedID   int64
+	CreatedAt pgtype.Timestamp
+}
+
+type UsernameHistory struct {
+	ID            int64
+	UserID        int64
+	UserName      string
+	ChangedAt     pgtype.Timestamp
+	ReservedUntil pgtype.Timestamp
 }
 
 type UsersHasInterest struct {
@@ -73,3 +208,24 @@ type UsersHasInterest struct {
 	CreatedAt  pgtype.Timestamp
 	UpdatedAt  pgtype.Timestamp
 }
+
+type VisiblePost struct {
+	ID                             int64
+	Content                        string
+	CreatedAt                      pgtype.Timestamp
+	UpdatedAt                      pgtype.Timestamp
+	UserID                         int64
+	AuthorID                       int64
+	AuthorUserName                 string
+	AuthorFullName                 string
+	AuthorProfileImageUrl          pgtype.Text
+	AuthorProfileImageFeedUrl      pgtype.Text
+	AuthorProfileImageThumbnailUrl pgtype.Text
+	AuthorCreatedAt                pgtype.Timestamp
+	LikeCount                      int64
+	CommentCount                   int64
+	LikedByUser                    bool
+	AuthorIsFollowing              bool
+	AuthorFollowsYou               bool
+	ViewerID                       int64
+}
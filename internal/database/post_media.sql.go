@@ -7,28 +7,55 @@ package database
 
 import (
 	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 const createPostMedia = `-- name: CreatePostMedia :one
-INSERT INTO post_media(media_url, order_index, created_at, updated_at, post_id)
+INSERT INTO post_media(media_url, order_index, width, height, media_type, duration_ms, poster_url, thumbnail_url, feed_url, created_at, updated_at, post_id)
 VALUES(
     $1,
     $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7,
+    $8,
+    $9,
     NOW(),
     NOW(),
-    $3
+    $10
 )
-RETURNING id, media_url, order_index, created_at, updated_at, deleted_at, post_id
+RETURNING *
 `
 
 type CreatePostMediaParams struct {
-	MediaUrl   string
-	OrderIndex int32
-	PostID     int64
+	MediaUrl     string
+	OrderIndex   int32
+	Width        pgtype.Int4
+	Height       pgtype.Int4
+	MediaType    string
+	DurationMs   pgtype.Int4
+	PosterUrl    pgtype.Text
+	ThumbnailUrl pgtype.Text
+	FeedUrl      pgtype.Text
+	PostID       int64
 }
 
 func (q *Queries) CreatePostMedia(ctx context.Context, arg CreatePostMediaParams) (PostMedium, error) {
-	row := q.db.QueryRow(ctx, createPostMedia, arg.MediaUrl, arg.OrderIndex, arg.PostID)
+	row := q.db.QueryRow(ctx, createPostMedia,
+		arg.MediaUrl,
+		arg.OrderIndex,
+		arg.Width,
+		arg.Height,
+		arg.MediaType,
+		arg.DurationMs,
+		arg.PosterUrl,
+		arg.ThumbnailUrl,
+		arg.FeedUrl,
+		arg.PostID,
+	)
 	var i PostMedium
 	err := row.Scan(
 		&i.ID,
@@ -38,6 +65,159 @@ func (q *Queries) CreatePostMedia(ctx context.Context, arg CreatePostMediaParams
 		&i.UpdatedAt,
 		&i.DeletedAt,
 		&i.PostID,
+		&i.Width,
+		&i.Height,
+		&i.MediaType,
+		&i.DurationMs,
+		&i.PosterUrl,
+		&i.ThumbnailUrl,
+		&i.FeedUrl,
 	)
 	return i, err
 }
+
+const deletePostMedia = `-- name: DeletePostMedia :exec
+DELETE FROM post_media
+WHERE id = $1
+`
+
+func (q *Queries) DeletePostMedia(ctx context.Context, id int64) error {
+	_, err := q.db.Exec(ctx, deletePostMedia, id)
+	return err
+}
+
+const getDeletedPostMedia = `-- name: GetDeletedPostMedia :many
+SELECT id, media_url, poster_url, thumbnail_url, feed_url FROM post_media
+WHERE deleted_at < $1
+ORDER BY deleted_at ASC
+LIMIT $2
+`
+
+type GetDeletedPostMediaParams struct {
+	DeletedAt pgtype.Timestamp
+	Limit     int32
+}
+
+type GetDeletedPostMediaRow struct {
+	ID           int64
+	MediaUrl     string
+	PosterUrl    pgtype.Text
+	ThumbnailUrl pgtype.Text
+	FeedUrl      pgtype.Text
+}
+
+func (q *Queries) GetDeletedPostMedia(ctx context.Context, arg GetDeletedPostMediaParams) ([]GetDeletedPostMediaRow, error) {
+	rows, err := q.db.Query(ctx, getDeletedPostMedia, arg.DeletedAt, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetDeletedPostMediaRow
+	for rows.Next() {
+		var i GetDeletedPostMediaRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.MediaUrl,
+			&i.PosterUrl,
+			&i.ThumbnailUrl,
+			&i.FeedUrl,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getMediaForPosts = `-- name: GetMediaForPosts :many
+SELECT id, media_url, order_index, created_at, updated_at, deleted_at, post_id, width, height, media_type, duration_ms, poster_url, thumbnail_url, feed_url FROM post_media
+WHERE post_id = ANY($1::bigint[]) AND deleted_at IS NULL
+ORDER BY post_id, order_index, id
+`
+
+func (q *Queries) GetMediaForPosts(ctx context.Context, dollar_1 []int64) ([]PostMedium, error) {
+	rows, err := q.db.Query(ctx, getMediaForPosts, dollar_1)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []PostMedium
+	for rows.Next() {
+		var i PostMedium
+		if err := rows.Scan(
+			&i.ID,
+			&i.MediaUrl,
+			&i.OrderIndex,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.PostID,
+			&i.Width,
+			&i.Height,
+			&i.MediaType,
+			&i.DurationMs,
+			&i.PosterUrl,
+			&i.ThumbnailUrl,
+			&i.FeedUrl,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getMediaUrlsForUser = `-- name: GetMediaUrlsForUser :many
+SELECT pm.media_url FROM post_media pm
+INNER JOIN posts p ON pm.post_id = p.id
+WHERE p.user_id = $1
+UNION ALL
+SELECT pm.poster_url FROM post_media pm
+INNER JOIN posts p ON pm.post_id = p.id
+WHERE p.user_id = $1 AND pm.poster_url IS NOT NULL
+UNION ALL
+SELECT pm.thumbnail_url FROM post_media pm
+INNER JOIN posts p ON pm.post_id = p.id
+WHERE p.user_id = $1 AND pm.thumbnail_url IS NOT NULL
+UNION ALL
+SELECT pm.feed_url FROM post_media pm
+INNER JOIN posts p ON pm.post_id = p.id
+WHERE p.user_id = $1 AND pm.feed_url IS NOT NULL
+`
+
+func (q *Queries) GetMediaUrlsForUser(ctx context.Context, userID int64) ([]string, error) {
+	rows, err := q.db.Query(ctx, getMediaUrlsForUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var media_url string
+		if err := rows.Scan(&media_url); err != nil {
+			return nil, err
+		}
+		items = append(items, media_url)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const softDeletePostMedia = `-- name: SoftDeletePostMedia :exec
+UPDATE post_media
+SET deleted_at = NOW(), updated_at = NOW()
+WHERE post_id = $1 AND deleted_at IS NULL
+`
+
+func (q *Queries) SoftDeletePostMedia(ctx context.Context, postID int64) error {
+	_, err := q.db.Exec(ctx, softDeletePostMedia, postID)
+	return err
+}
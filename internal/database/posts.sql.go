@@ -19,7 +19,7 @@ VALUES(
     NOW(),
     $2
 )
-RETURNING id, content, created_at, updated_at, deleted_at, user_id
+RETURNING *
 `
 
 type CreatePostParams struct {
@@ -50,87 +50,106 @@ func (q *Queries) DeleteAllPosts(ctx context.Context) error {
 	return err
 }
 
+const editPost = `-- name: EditPost :one
+UPDATE posts
+SET content = $2, updated_at = NOW()
+WHERE id = $1
+RETURNING *
+`
+
+type EditPostParams struct {
+	ID      int64
+	Content string
+}
+
+func (q *Queries) EditPost(ctx context.Context, arg EditPostParams) (Post, error) {
+	row := q.db.QueryRow(ctx, editPost, arg.ID, arg.Content)
+	var i Post
+	err := row.Scan(
+		&i.ID,
+		&i.Content,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.UserID,
+	)
+	return i, err
+}
+
 const getAllPosts = `-- name: GetAllPosts :many
-SELECT
-    p.id,
-    p.content,
-    p.created_at,
-    p.updated_at,
-    p.user_id,
-    u.id AS author_id, 
-    u.email AS author_email, 
-    u.user_name AS author_user_name,
-    u.full_name AS author_full_name,
-    u.profile_image_url AS author_profile_image_url,
-    u.dob AS author_dob,
-    u.created_at AS author_created_at, 
-    u.updated_at AS author_updated_at, 
-    (SELECT COUNT(*) FROM post_likes pl WHERE pl.post_id = p.id) AS like_count,
-    (SELECT COUNT(*) FROM comments c WHERE c.post_id = p.id) AS comment_count,
-    (
-        SELECT EXISTS(
-            SELECT 1 FROM post_likes upl WHERE upl.post_id = p.id AND upl.user_id = $1
-        )
-    ) AS liked_by_user,
-    CAST(COALESCE(ARRAY_AGG(pm.media_url ORDER BY pm.id) FILTER (WHERE pm.media_url IS NOT NULL), '{}'::text[]) AS text[]) AS media_urls_array
-
-FROM posts p
-INNER JOIN users u ON p.user_id = u.id
-LEFT JOIN post_media pm ON p.id = pm.post_id
-WHERE p.deleted_at IS NULL 
-GROUP BY
-    p.id,               
-    p.content,
-    p.created_at,
-    p.updated_at,
-    p.user_id,
-    u.id,               
-    u.email,
-    u.user_name,
-    u.full_name,
-    u.profile_image_url,
-    u.dob,
-    u.created_at,
-    u.updated_at
-ORDER BY p.created_at DESC
+SELECT id, content, created_at, updated_at, user_id, author_id, author_user_name, author_full_name, author_profile_image_url, author_profile_image_feed_url, author_profile_image_thumbnail_url, author_created_at, like_count, comment_count, liked_by_user, author_is_following, author_follows_you, viewer_id FROM visible_posts
+WHERE viewer_id = $1
+ORDER BY created_at DESC
 LIMIT $2 OFFSET $3
 `
 
 type GetAllPostsParams struct {
-	UserID int64
-	Limit  int32
-	Offset int32
+	ViewerID int64
+	Limit    int32
+	Offset   int32
+}
+
+func (q *Queries) GetAllPosts(ctx context.Context, arg GetAllPostsParams) ([]VisiblePost, error) {
+	rows, err := q.db.Query(ctx, getAllPosts, arg.ViewerID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []VisiblePost
+	for rows.Next() {
+		var i VisiblePost
+		if err := rows.Scan(
+			&i.ID,
+			&i.Content,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.UserID,
+			&i.AuthorID,
+			&i.AuthorUserName,
+			&i.AuthorFullName,
+			&i.AuthorProfileImageUrl,
+			&i.AuthorProfileImageFeedUrl,
+			&i.AuthorProfileImageThumbnailUrl,
+			&i.AuthorCreatedAt,
+			&i.LikeCount,
+			&i.CommentCount,
+			&i.LikedByUser,
+			&i.AuthorIsFollowing,
+			&i.AuthorFollowsYou,
+			&i.ViewerID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
-type GetAllPostsRow struct {
-	ID                    int64
-	Content               string
-	CreatedAt             pgtype.Timestamp
-	UpdatedAt             pgtype.Timestamp
-	UserID                int64
-	AuthorID              int64
-	AuthorEmail           string
-	AuthorUserName        string
-	AuthorFullName        string
-	AuthorProfileImageUrl pgtype.Text
-	AuthorDob             pgtype.Date
-	AuthorCreatedAt       pgtype.Timestamp
-	AuthorUpdatedAt       pgtype.Timestamp
-	LikeCount             int64
-	CommentCount          int64
-	LikedByUser           bool
-	MediaUrlsArray        []string
+const getForYouCandidates = `-- name: GetForYouCandidates :many
+SELECT id, content, created_at, updated_at, user_id, author_id, author_user_name, author_full_name, author_profile_image_url, author_profile_image_feed_url, author_profile_image_thumbnail_url, author_created_at, like_count, comment_count, liked_by_user, author_is_following, author_follows_you, viewer_id FROM visible_posts
+WHERE viewer_id = $1 AND created_at > $2::timestamp
+ORDER BY created_at DESC, id DESC
+LIMIT $3
+`
+
+type GetForYouCandidatesParams struct {
+	ViewerID       int64
+	Since          pgtype.Timestamp
+	CandidateLimit int32
 }
 
-func (q *Queries) GetAllPosts(ctx context.Context, arg GetAllPostsParams) ([]GetAllPostsRow, error) {
-	rows, err := q.db.Query(ctx, getAllPosts, arg.UserID, arg.Limit, arg.Offset)
+func (q *Queries) GetForYouCandidates(ctx context.Context, arg GetForYouCandidatesParams) ([]VisiblePost, error) {
+	rows, err := q.db.Query(ctx, getForYouCandidates, arg.ViewerID, arg.Since, arg.CandidateLimit)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []GetAllPostsRow
+	var items []VisiblePost
 	for rows.Next() {
-		var i GetAllPostsRow
+		var i VisiblePost
 		if err := rows.Scan(
 			&i.ID,
 			&i.Content,
@@ -138,17 +157,18 @@ func (q *Queries) GetAllPosts(ctx context.Context, arg GetAllPostsParams) ([]Get
 			&i.UpdatedAt,
 			&i.UserID,
 			&i.AuthorID,
-			&i.AuthorEmail,
 			&i.AuthorUserName,
 			&i.AuthorFullName,
 			&i.AuthorProfileImageUrl,
-			&i.AuthorDob,
+			&i.AuthorProfileImageFeedUrl,
+			&i.AuthorProfileImageThumbnailUrl,
 			&i.AuthorCreatedAt,
-			&i.AuthorUpdatedAt,
 			&i.LikeCount,
 			&i.CommentCount,
 			&i.LikedByUser,
-			&i.MediaUrlsArray,
+			&i.AuthorIsFollowing,
+			&i.AuthorFollowsYou,
+			&i.ViewerID,
 		); err != nil {
 			return nil, err
 		}
@@ -160,78 +180,199 @@ func (q *Queries) GetAllPosts(ctx context.Context, arg GetAllPostsParams) ([]Get
 	return items, nil
 }
 
-const getPostById = `-- name: GetPostById :one
-SELECT
-    p.id,
-    p.content,
-    p.created_at,
-    p.updated_at,
-    p.user_id,
-    u.id AS author_id, 
-    u.email AS author_email, 
-    u.user_name AS author_user_name,
-    u.full_name AS author_full_name,
-    u.profile_image_url AS author_profile_image_url,
-    u.dob AS author_dob,
-    u.created_at AS author_created_at, 
-    u.updated_at AS author_updated_at, 
-    (SELECT COUNT(*) FROM post_likes pl WHERE pl.post_id = p.id) AS like_count,
-    (SELECT COUNT(*) FROM comments c WHERE c.post_id = p.id) AS comment_count,
-    (
-        SELECT EXISTS(
-            SELECT 1 FROM post_likes upl WHERE upl.post_id = p.id AND upl.user_id = $1
-        )
-    ) AS liked_by_user,
-    CAST(COALESCE(ARRAY_AGG(pm.media_url ORDER BY pm.id) FILTER (WHERE pm.media_url IS NOT NULL), '{}'::text[]) AS text[]) AS media_urls_array
-
-FROM posts p
-INNER JOIN users u ON p.user_id = u.id
-LEFT JOIN post_media pm ON p.id = pm.post_id
-WHERE p.id = $2 AND p.deleted_at IS NULL 
-GROUP BY
-    p.id,               
-    p.content,
-    p.created_at,
-    p.updated_at,
-    p.user_id,
-    u.id,               
-    u.email,
-    u.user_name,
-    u.full_name,
-    u.profile_image_url,
-    u.dob,
-    u.created_at,
-    u.updated_at
+const getHomeFeed = `-- name: GetHomeFeed :many
+SELECT id, content, created_at, updated_at, user_id, author_id, author_user_name, author_full_name, author_profile_image_url, author_profile_image_feed_url, author_profile_image_thumbnail_url, author_created_at, like_count, comment_count, liked_by_user, author_is_following, author_follows_you, viewer_id FROM visible_posts
+WHERE viewer_id = $1 AND user_id IN (
+    SELECT hf.followee_id FROM follows hf WHERE hf.follower_id = $1
+    UNION ALL
+    SELECT $1
+)
+ORDER BY created_at DESC, id DESC
+LIMIT $2 OFFSET $3
 `
 
-type GetPostByIdParams struct {
-	UserID int64
-	ID     int64
+type GetHomeFeedParams struct {
+	ViewerID int64
+	Limit    int32
+	Offset   int32
+}
+
+func (q *Queries) GetHomeFeed(ctx context.Context, arg GetHomeFeedParams) ([]VisiblePost, error) {
+	rows, err := q.db.Query(ctx, getHomeFeed, arg.ViewerID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []VisiblePost
+	for rows.Next() {
+		var i VisiblePost
+		if err := rows.Scan(
+			&i.ID,
+			&i.Content,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.UserID,
+			&i.AuthorID,
+			&i.AuthorUserName,
+			&i.AuthorFullName,
+			&i.AuthorProfileImageUrl,
+			&i.AuthorProfileImageFeedUrl,
+			&i.AuthorProfileImageThumbnailUrl,
+			&i.AuthorCreatedAt,
+			&i.LikeCount,
+			&i.CommentCount,
+			&i.LikedByUser,
+			&i.AuthorIsFollowing,
+			&i.AuthorFollowsYou,
+			&i.ViewerID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getHomeFeedAfter = `-- name: GetHomeFeedAfter :many
+SELECT id, content, created_at, updated_at, user_id, author_id, author_user_name, author_full_name, author_profile_image_url, author_profile_image_feed_url, author_profile_image_thumbnail_url, author_created_at, like_count, comment_count, liked_by_user, author_is_following, author_follows_you, viewer_id FROM visible_posts
+WHERE viewer_id = $1 AND user_id IN (
+    SELECT hf.followee_id FROM follows hf WHERE hf.follower_id = $1
+    UNION ALL
+    SELECT $1
+) AND (created_at, id) > ($2::timestamp, $3::bigint)
+ORDER BY created_at ASC, id ASC
+LIMIT $4
+`
+
+type GetHomeFeedAfterParams struct {
+	ViewerID        int64
+	CursorCreatedAt pgtype.Timestamp
+	CursorID        int64
+	PageLimit       int32
+}
+
+func (q *Queries) GetHomeFeedAfter(ctx context.Context, arg GetHomeFeedAfterParams) ([]VisiblePost, error) {
+	rows, err := q.db.Query(ctx, getHomeFeedAfter,
+		arg.ViewerID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []VisiblePost
+	for rows.Next() {
+		var i VisiblePost
+		if err := rows.Scan(
+			&i.ID,
+			&i.Content,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.UserID,
+			&i.AuthorID,
+			&i.AuthorUserName,
+			&i.AuthorFullName,
+			&i.AuthorProfileImageUrl,
+			&i.AuthorProfileImageFeedUrl,
+			&i.AuthorProfileImageThumbnailUrl,
+			&i.AuthorCreatedAt,
+			&i.LikeCount,
+			&i.CommentCount,
+			&i.LikedByUser,
+			&i.AuthorIsFollowing,
+			&i.AuthorFollowsYou,
+			&i.ViewerID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getHomeFeedBefore = `-- name: GetHomeFeedBefore :many
+SELECT id, content, created_at, updated_at, user_id, author_id, author_user_name, author_full_name, author_profile_image_url, author_profile_image_feed_url, author_profile_image_thumbnail_url, author_created_at, like_count, comment_count, liked_by_user, author_is_following, author_follows_you, viewer_id FROM visible_posts
+WHERE viewer_id = $1 AND user_id IN (
+    SELECT hf.followee_id FROM follows hf WHERE hf.follower_id = $1
+    UNION ALL
+    SELECT $1
+) AND (created_at, id) < ($2::timestamp, $3::bigint)
+ORDER BY created_at DESC, id DESC
+LIMIT $4
+`
+
+type GetHomeFeedBeforeParams struct {
+	ViewerID        int64
+	CursorCreatedAt pgtype.Timestamp
+	CursorID        int64
+	PageLimit       int32
+}
+
+func (q *Queries) GetHomeFeedBefore(ctx context.Context, arg GetHomeFeedBeforeParams) ([]VisiblePost, error) {
+	rows, err := q.db.Query(ctx, getHomeFeedBefore,
+		arg.ViewerID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []VisiblePost
+	for rows.Next() {
+		var i VisiblePost
+		if err := rows.Scan(
+			&i.ID,
+			&i.Content,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.UserID,
+			&i.AuthorID,
+			&i.AuthorUserName,
+			&i.AuthorFullName,
+			&i.AuthorProfileImageUrl,
+			&i.AuthorProfileImageFeedUrl,
+			&i.AuthorProfileImageThumbnailUrl,
+			&i.AuthorCreatedAt,
+			&i.LikeCount,
+			&i.CommentCount,
+			&i.LikedByUser,
+			&i.AuthorIsFollowing,
+			&i.AuthorFollowsYou,
+			&i.ViewerID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
-type GetPostByIdRow struct {
-	ID                    int64
-	Content               string
-	CreatedAt             pgtype.Timestamp
-	UpdatedAt             pgtype.Timestamp
-	UserID                int64
-	AuthorID              int64
-	AuthorEmail           string
-	AuthorUserName        string
-	AuthorFullName        string
-	AuthorProfileImageUrl pgtype.Text
-	AuthorDob             pgtype.Date
-	AuthorCreatedAt       pgtype.Timestamp
-	AuthorUpdatedAt       pgtype.Timestamp
-	LikeCount             int64
-	CommentCount          int64
-	LikedByUser           bool
-	MediaUrlsArray        []string
+const getPostById = `-- name: GetPostById :one
+SELECT id, content, created_at, updated_at, user_id, author_id, author_user_name, author_full_name, author_profile_image_url, author_profile_image_feed_url, author_profile_image_thumbnail_url, author_created_at, like_count, comment_count, liked_by_user, author_is_following, author_follows_you, viewer_id FROM visible_posts
+WHERE viewer_id = $1 AND id = $2
+`
+
+type GetPostByIdParams struct {
+	ViewerID int64
+	ID       int64
 }
 
-func (q *Queries) GetPostById(ctx context.Context, arg GetPostByIdParams) (GetPostByIdRow, error) {
-	row := q.db.QueryRow(ctx, getPostById, arg.UserID, arg.ID)
-	var i GetPostByIdRow
+func (q *Queries) GetPostById(ctx context.Context, arg GetPostByIdParams) (VisiblePost, error) {
+	row := q.db.QueryRow(ctx, getPostById, arg.ViewerID, arg.ID)
+	var i VisiblePost
 	err := row.Scan(
 		&i.ID,
 		&i.Content,
@@ -239,28 +380,186 @@ func (q *Queries) GetPostById(ctx context.Context, arg GetPostByIdParams) (GetPo
 		&i.UpdatedAt,
 		&i.UserID,
 		&i.AuthorID,
-		&i.AuthorEmail,
 		&i.AuthorUserName,
 		&i.AuthorFullName,
 		&i.AuthorProfileImageUrl,
-		&i.AuthorDob,
+		&i.AuthorProfileImageFeedUrl,
+		&i.AuthorProfileImageThumbnailUrl,
 		&i.AuthorCreatedAt,
-		&i.AuthorUpdatedAt,
 		&i.LikeCount,
 		&i.CommentCount,
 		&i.LikedByUser,
-		&i.MediaUrlsArray,
+		&i.AuthorIsFollowing,
+		&i.AuthorFollowsYou,
+		&i.ViewerID,
+	)
+	return i, err
+}
+
+const getPostForUpdate = `-- name: GetPostForUpdate :one
+SELECT * FROM posts
+WHERE id = $1 AND deleted_at IS NULL
+FOR UPDATE
+`
+
+func (q *Queries) GetPostForUpdate(ctx context.Context, id int64) (Post, error) {
+	row := q.db.QueryRow(ctx, getPostForUpdate, id)
+	var i Post
+	err := row.Scan(
+		&i.ID,
+		&i.Content,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.UserID,
 	)
 	return i, err
 }
 
-const getPostsCount = `-- name: GetPostsCount :one
-SELECT COUNT(*) FROM posts WHERE deleted_at is NULL
+const getPostsAfter = `-- name: GetPostsAfter :many
+SELECT id, content, created_at, updated_at, user_id, author_id, author_user_name, author_full_name, author_profile_image_url, author_profile_image_feed_url, author_profile_image_thumbnail_url, author_created_at, like_count, comment_count, liked_by_user, author_is_following, author_follows_you, viewer_id FROM visible_posts
+WHERE viewer_id = $1 AND (created_at, id) > ($2::timestamp, $3::bigint)
+ORDER BY created_at ASC, id ASC
+LIMIT $4
+`
+
+type GetPostsAfterParams struct {
+	ViewerID        int64
+	CursorCreatedAt pgtype.Timestamp
+	CursorID        int64
+	PageLimit       int32
+}
+
+func (q *Queries) GetPostsAfter(ctx context.Context, arg GetPostsAfterParams) ([]VisiblePost, error) {
+	rows, err := q.db.Query(ctx, getPostsAfter,
+		arg.ViewerID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []VisiblePost
+	for rows.Next() {
+		var i VisiblePost
+		if err := rows.Scan(
+			&i.ID,
+			&i.Content,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.UserID,
+			&i.AuthorID,
+			&i.AuthorUserName,
+			&i.AuthorFullName,
+			&i.AuthorProfileImageUrl,
+			&i.AuthorProfileImageFeedUrl,
+			&i.AuthorProfileImageThumbnailUrl,
+			&i.AuthorCreatedAt,
+			&i.LikeCount,
+			&i.CommentCount,
+			&i.LikedByUser,
+			&i.AuthorIsFollowing,
+			&i.AuthorFollowsYou,
+			&i.ViewerID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPostsBefore = `-- name: GetPostsBefore :many
+SELECT id, content, created_at, updated_at, user_id, author_id, author_user_name, author_full_name, author_profile_image_url, author_profile_image_feed_url, author_profile_image_thumbnail_url, author_created_at, like_count, comment_count, liked_by_user, author_is_following, author_follows_you, viewer_id FROM visible_posts
+WHERE viewer_id = $1 AND (created_at, id) < ($2::timestamp, $3::bigint)
+ORDER BY created_at DESC, id DESC
+LIMIT $4
+`
+
+type GetPostsBeforeParams struct {
+	ViewerID        int64
+	CursorCreatedAt pgtype.Timestamp
+	CursorID        int64
+	PageLimit       int32
+}
+
+func (q *Queries) GetPostsBefore(ctx context.Context, arg GetPostsBeforeParams) ([]VisiblePost, error) {
+	rows, err := q.db.Query(ctx, getPostsBefore,
+		arg.ViewerID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []VisiblePost
+	for rows.Next() {
+		var i VisiblePost
+		if err := rows.Scan(
+			&i.ID,
+			&i.Content,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.UserID,
+			&i.AuthorID,
+			&i.AuthorUserName,
+			&i.AuthorFullName,
+			&i.AuthorProfileImageUrl,
+			&i.AuthorProfileImageFeedUrl,
+			&i.AuthorProfileImageThumbnailUrl,
+			&i.AuthorCreatedAt,
+			&i.LikeCount,
+			&i.CommentCount,
+			&i.LikedByUser,
+			&i.AuthorIsFollowing,
+			&i.AuthorFollowsYou,
+			&i.ViewerID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const isPostVisible = `-- name: IsPostVisible :one
+SELECT EXISTS(
+    SELECT 1 FROM posts p
+    INNER JOIN users u ON p.user_id = u.id
+    WHERE p.id = $1 AND p.deleted_at IS NULL AND u.deleted_at IS NULL AND u.deactivated_at IS NULL
+        AND (u.is_private = FALSE OR u.id = $2 OR EXISTS(SELECT 1 FROM follows pf WHERE pf.follower_id = $2 AND pf.followee_id = u.id))
+) AS visible
 `
 
-func (q *Queries) GetPostsCount(ctx context.Context) (int64, error) {
-	row := q.db.QueryRow(ctx, getPostsCount)
-	var count int64
-	err := row.Scan(&count)
-	return count, err
+type IsPostVisibleParams struct {
+	PostID   int64
+	ViewerID int64
+}
+
+func (q *Queries) IsPostVisible(ctx context.Context, arg IsPostVisibleParams) (bool, error) {
+	row := q.db.QueryRow(ctx, isPostVisible, arg.PostID, arg.ViewerID)
+	var visible bool
+	err := row.Scan(&visible)
+	return visible, err
+}
+
+const softDeletePost = `-- name: SoftDeletePost :exec
+UPDATE posts
+SET deleted_at = NOW()
+WHERE id = $1
+`
+
+func (q *Queries) SoftDeletePost(ctx context.Context, id int64) error {
+	_, err := q.db.Exec(ctx, softDeletePost, id)
+	return err
 }
@@ -11,6 +11,47 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const changeUserName = `-- name: ChangeUserName :one
+UPDATE users SET user_name = $2, user_name_changed_at = NOW(), updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL AND deactivated_at IS NULL
+RETURNING id, email, user_name, full_name, profile_image_url, dob, hashed_password, created_at, updated_at, deleted_at, token_version, email_verified_at, verification_sent_at, totp_secret, totp_enabled_at, totp_last_step, deactivated_at, is_private, is_admin, user_name_changed_at, profile_image_thumbnail_url, profile_image_feed_url
+`
+
+type ChangeUserNameParams struct {
+	ID       int64
+	UserName string
+}
+
+func (q *Queries) ChangeUserName(ctx context.Context, arg ChangeUserNameParams) (User, error) {
+	row := q.db.QueryRow(ctx, changeUserName, arg.ID, arg.UserName)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.UserName,
+		&i.FullName,
+		&i.ProfileImageUrl,
+		&i.Dob,
+		&i.HashedPassword,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.TokenVersion,
+		&i.EmailVerifiedAt,
+		&i.VerificationSentAt,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastStep,
+		&i.DeactivatedAt,
+		&i.IsPrivate,
+		&i.IsAdmin,
+		&i.UserNameChangedAt,
+		&i.ProfileImageThumbnailUrl,
+		&i.ProfileImageFeedUrl,
+	)
+	return i, err
+}
+
 const createUser = `-- name: CreateUser :one
 INSERT INTO users(email, hashed_password, user_name, full_name, created_at, updated_at)
 VALUES(
@@ -21,7 +62,7 @@ VALUES(
     NOW(),
     NOW()
 )
-RETURNING id, email, user_name, full_name, profile_image_url, dob, hashed_password, created_at, updated_at, deleted_at
+RETURNING id, email, user_name, full_name, profile_image_url, dob, hashed_password, created_at, updated_at, deleted_at, token_version, email_verified_at, verification_sent_at, totp_secret, totp_enabled_at, totp_last_step, deactivated_at, is_private, is_admin, user_name_changed_at, profile_image_thumbnail_url, profile_image_feed_url
 `
 
 type CreateUserParams struct {
@@ -50,10 +91,36 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.DeletedAt,
+		&i.TokenVersion,
+		&i.EmailVerifiedAt,
+		&i.VerificationSentAt,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastStep,
+		&i.DeactivatedAt,
+		&i.IsPrivate,
+		&i.IsAdmin,
+		&i.UserNameChangedAt,
+		&i.ProfileImageThumbnailUrl,
+		&i.ProfileImageFeedUrl,
 	)
 	return i, err
 }
 
+const deactivateUser = `-- name: DeactivateUser :exec
+UPDATE users
+SET
+    deactivated_at = NOW(),
+    updated_at = NOW()
+WHERE
+    id = $1 AND deactivated_at IS NULL
+`
+
+func (q *Queries) DeactivateUser(ctx context.Context, id int64) error {
+	_, err := q.db.Exec(ctx, deactivateUser, id)
+	return err
+}
+
 const deleteAllUsers = `-- name: DeleteAllUsers :exec
 DELETE FROM users
 `
@@ -63,9 +130,39 @@ func (q *Queries) DeleteAllUsers(ctx context.Context) error {
 	return err
 }
 
+const disableUserTotp = `-- name: DisableUserTotp :exec
+UPDATE users
+SET
+    totp_secret = NULL,
+    totp_enabled_at = NULL,
+    totp_last_step = NULL,
+    updated_at = NOW()
+WHERE
+    id = $1
+`
+
+func (q *Queries) DisableUserTotp(ctx context.Context, id int64) error {
+	_, err := q.db.Exec(ctx, disableUserTotp, id)
+	return err
+}
+
+const enableUserTotp = `-- name: EnableUserTotp :exec
+UPDATE users
+SET
+    totp_enabled_at = NOW(),
+    updated_at = NOW()
+WHERE
+    id = $1
+`
+
+func (q *Queries) EnableUserTotp(ctx context.Context, id int64) error {
+	_, err := q.db.Exec(ctx, enableUserTotp, id)
+	return err
+}
+
 const getUserByEmail = `-- name: GetUserByEmail :one
-SELECT id, email, user_name, full_name, profile_image_url, dob, hashed_password, created_at, updated_at, deleted_at FROM users
-WHERE email = $1
+SELECT id, email, user_name, full_name, profile_image_url, dob, hashed_password, created_at, updated_at, deleted_at, token_version, email_verified_at, verification_sent_at, totp_secret, totp_enabled_at, totp_last_step, deactivated_at, is_private, is_admin, user_name_changed_at, profile_image_thumbnail_url, profile_image_feed_url FROM users
+WHERE email = $1 AND deleted_at IS NULL AND deactivated_at IS NULL
 `
 
 func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error) {
@@ -82,13 +179,60 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.DeletedAt,
+		&i.TokenVersion,
+		&i.EmailVerifiedAt,
+		&i.VerificationSentAt,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastStep,
+		&i.DeactivatedAt,
+		&i.IsPrivate,
+		&i.IsAdmin,
+		&i.UserNameChangedAt,
+		&i.ProfileImageThumbnailUrl,
+		&i.ProfileImageFeedUrl,
+	)
+	return i, err
+}
+
+const getUserByEmailIncludingInactive = `-- name: GetUserByEmailIncludingInactive :one
+SELECT id, email, user_name, full_name, profile_image_url, dob, hashed_password, created_at, updated_at, deleted_at, token_version, email_verified_at, verification_sent_at, totp_secret, totp_enabled_at, totp_last_step, deactivated_at, is_private, is_admin, user_name_changed_at, profile_image_thumbnail_url, profile_image_feed_url FROM users
+WHERE email = $1
+`
+
+func (q *Queries) GetUserByEmailIncludingInactive(ctx context.Context, email string) (User, error) {
+	row := q.db.QueryRow(ctx, getUserByEmailIncludingInactive, email)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.UserName,
+		&i.FullName,
+		&i.ProfileImageUrl,
+		&i.Dob,
+		&i.HashedPassword,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.TokenVersion,
+		&i.EmailVerifiedAt,
+		&i.VerificationSentAt,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastStep,
+		&i.DeactivatedAt,
+		&i.IsPrivate,
+		&i.IsAdmin,
+		&i.UserNameChangedAt,
+		&i.ProfileImageThumbnailUrl,
+		&i.ProfileImageFeedUrl,
 	)
 	return i, err
 }
 
 const getUserById = `-- name: GetUserById :one
-SELECT id, email, user_name, full_name, profile_image_url, dob, hashed_password, created_at, updated_at, deleted_at FROM users
-WHERE id = $1
+SELECT id, email, user_name, full_name, profile_image_url, dob, hashed_password, created_at, updated_at, deleted_at, token_version, email_verified_at, verification_sent_at, totp_secret, totp_enabled_at, totp_last_step, deactivated_at, is_private, is_admin, user_name_changed_at, profile_image_thumbnail_url, profile_image_feed_url FROM users
+WHERE id = $1 AND deleted_at IS NULL AND deactivated_at IS NULL
 `
 
 func (q *Queries) GetUserById(ctx context.Context, id int64) (User, error) {
@@ -105,38 +249,538 @@ func (q *Queries) GetUserById(ctx context.Context, id int64) (User, error) {
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.DeletedAt,
+		&i.TokenVersion,
+		&i.EmailVerifiedAt,
+		&i.VerificationSentAt,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastStep,
+		&i.DeactivatedAt,
+		&i.IsPrivate,
+		&i.IsAdmin,
+		&i.UserNameChangedAt,
+		&i.ProfileImageThumbnailUrl,
+		&i.ProfileImageFeedUrl,
 	)
 	return i, err
 }
 
-const updateUserProfile = `-- name: UpdateUserProfile :one
+const getUserByIdIncludingInactive = `-- name: GetUserByIdIncludingInactive :one
+SELECT id, email, user_name, full_name, profile_image_url, dob, hashed_password, created_at, updated_at, deleted_at, token_version, email_verified_at, verification_sent_at, totp_secret, totp_enabled_at, totp_last_step, deactivated_at, is_private, is_admin, user_name_changed_at, profile_image_thumbnail_url, profile_image_feed_url FROM users
+WHERE id = $1
+`
+
+func (q *Queries) GetUserByIdIncludingInactive(ctx context.Context, id int64) (User, error) {
+	row := q.db.QueryRow(ctx, getUserByIdIncludingInactive, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.UserName,
+		&i.FullName,
+		&i.ProfileImageUrl,
+		&i.Dob,
+		&i.HashedPassword,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.TokenVersion,
+		&i.EmailVerifiedAt,
+		&i.VerificationSentAt,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastStep,
+		&i.DeactivatedAt,
+		&i.IsPrivate,
+		&i.IsAdmin,
+		&i.UserNameChangedAt,
+		&i.ProfileImageThumbnailUrl,
+		&i.ProfileImageFeedUrl,
+	)
+	return i, err
+}
+
+const getUserByUserName = `-- name: GetUserByUserName :one
+SELECT id, email, user_name, full_name, profile_image_url, dob, hashed_password, created_at, updated_at, deleted_at, token_version, email_verified_at, verification_sent_at, totp_secret, totp_enabled_at, totp_last_step, deactivated_at, is_private, is_admin, user_name_changed_at, profile_image_thumbnail_url, profile_image_feed_url FROM users
+WHERE LOWER(user_name) = LOWER($1) AND deleted_at IS NULL AND deactivated_at IS NULL
+`
+
+func (q *Queries) GetUserByUserName(ctx context.Context, lower string) (User, error) {
+	row := q.db.QueryRow(ctx, getUserByUserName, lower)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.UserName,
+		&i.FullName,
+		&i.ProfileImageUrl,
+		&i.Dob,
+		&i.HashedPassword,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.TokenVersion,
+		&i.EmailVerifiedAt,
+		&i.VerificationSentAt,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastStep,
+		&i.DeactivatedAt,
+		&i.IsPrivate,
+		&i.IsAdmin,
+		&i.UserNameChangedAt,
+		&i.ProfileImageThumbnailUrl,
+		&i.ProfileImageFeedUrl,
+	)
+	return i, err
+}
+
+const getUserProfileStats = `-- name: GetUserProfileStats :one
+SELECT
+    (
+        SELECT COUNT(*) FROM posts p
+        WHERE p.user_id = $1 AND p.deleted_at IS NULL
+    ) AS post_count,
+    (
+        SELECT COUNT(*) FROM post_likes pl
+        INNER JOIN posts lp ON pl.post_id = lp.id
+        INNER JOIN users lu ON pl.user_id = lu.id
+        WHERE lp.user_id = $1 AND lp.deleted_at IS NULL AND lu.deleted_at IS NULL AND lu.deactivated_at IS NULL
+    ) AS like_count,
+    (
+        SELECT COUNT(*) FROM follows f
+        INNER JOIN users fu ON f.follower_id = fu.id
+        WHERE f.followee_id = $1 AND fu.deleted_at IS NULL AND fu.deactivated_at IS NULL
+    ) AS follower_count,
+    (
+        SELECT COUNT(*) FROM follows f
+        INNER JOIN users fu ON f.followee_id = fu.id
+        WHERE f.follower_id = $1 AND fu.deleted_at IS NULL AND fu.deactivated_at IS NULL
+    ) AS following_count,
+    (
+        SELECT COUNT(*) FROM follow_requests fr
+        INNER JOIN users ru ON fr.requester_id = ru.id
+        WHERE fr.target_id = $1 AND ru.deleted_at IS NULL AND ru.deactivated_at IS NULL
+    ) AS pending_request_count
+`
+
+type GetUserProfileStatsRow struct {
+	PostCount           int64
+	LikeCount           int64
+	FollowerCount       int64
+	FollowingCount      int64
+	PendingRequestCount int64
+}
+
+func (q *Queries) GetUserProfileStats(ctx context.Context, userID int64) (GetUserProfileStatsRow, error) {
+	row := q.db.QueryRow(ctx, getUserProfileStats, userID)
+	var i GetUserProfileStatsRow
+	err := row.Scan(
+		&i.PostCount,
+		&i.LikeCount,
+		&i.FollowerCount,
+		&i.FollowingCount,
+		&i.PendingRequestCount,
+	)
+	return i, err
+}
+
+const getUserTokenVersion = `-- name: GetUserTokenVersion :one
+SELECT token_version FROM users
+WHERE id = $1 AND deleted_at IS NULL AND deactivated_at IS NULL
+`
+
+func (q *Queries) GetUserTokenVersion(ctx context.Context, id int64) (int32, error) {
+	row := q.db.QueryRow(ctx, getUserTokenVersion, id)
+	var token_version int32
+	err := row.Scan(&token_version)
+	return token_version, err
+}
+
+const getUsersToPurge = `-- name: GetUsersToPurge :many
+SELECT id, profile_image_url, profile_image_thumbnail_url, profile_image_feed_url FROM users
+WHERE deleted_at < $1
+ORDER BY deleted_at ASC
+LIMIT $2
+`
+
+type GetUsersToPurgeParams struct {
+	DeletedAt pgtype.Timestamp
+	Limit     int32
+}
+
+type GetUsersToPurgeRow struct {
+	ID                       int64
+	ProfileImageUrl          pgtype.Text
+	ProfileImageThumbnailUrl pgtype.Text
+	ProfileImageFeedUrl      pgtype.Text
+}
+
+func (q *Queries) GetUsersToPurge(ctx context.Context, arg GetUsersToPurgeParams) ([]GetUsersToPurgeRow, error) {
+	rows, err := q.db.Query(ctx, getUsersToPurge, arg.DeletedAt, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetUsersToPurgeRow
+	for rows.Next() {
+		var i GetUsersToPurgeRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProfileImageUrl,
+			&i.ProfileImageThumbnailUrl,
+			&i.ProfileImageFeedUrl,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const incrementUserTokenVersion = `-- name: IncrementUserTokenVersion :one
 UPDATE users
 SET
-    full_name = $2,
-    user_name = $3,
-    profile_image_url = $4,
-    dob = $5,
+    token_version = token_version + 1,
     updated_at = NOW()
 WHERE
     id = $1
-RETURNING id, email, user_name, full_name, profile_image_url, dob, hashed_password, created_at, updated_at, deleted_at
+RETURNING token_version
+`
+
+func (q *Queries) IncrementUserTokenVersion(ctx context.Context, id int64) (int32, error) {
+	row := q.db.QueryRow(ctx, incrementUserTokenVersion, id)
+	var token_version int32
+	err := row.Scan(&token_version)
+	return token_version, err
+}
+
+const isUserNameTaken = `-- name: IsUserNameTaken :one
+SELECT (
+    EXISTS(
+        SELECT 1 FROM users
+        WHERE LOWER(user_name) = LOWER($1) AND id <> $2
+    ) OR EXISTS(
+        SELECT 1 FROM username_history
+        WHERE LOWER(user_name) = LOWER($1) AND user_id <> $2 AND reserved_until > NOW()
+    )
+)::boolean AS taken
+`
+
+type IsUserNameTakenParams struct {
+	UserName string
+	UserID   int64
+}
+
+func (q *Queries) IsUserNameTaken(ctx context.Context, arg IsUserNameTakenParams) (bool, error) {
+	row := q.db.QueryRow(ctx, isUserNameTaken, arg.UserName, arg.UserID)
+	var taken bool
+	err := row.Scan(&taken)
+	return taken, err
+}
+
+const markUserEmailVerified = `-- name: MarkUserEmailVerified :execrows
+UPDATE users
+SET
+    email_verified_at = NOW(),
+    updated_at = NOW()
+WHERE
+    id = $1 AND email = $2
+`
+
+type MarkUserEmailVerifiedParams struct {
+	ID    int64
+	Email string
+}
+
+func (q *Queries) MarkUserEmailVerified(ctx context.Context, arg MarkUserEmailVerifiedParams) (int64, error) {
+	result, err := q.db.Exec(ctx, markUserEmailVerified, arg.ID, arg.Email)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const purgeUser = `-- name: PurgeUser :execrows
+DELETE FROM users
+WHERE id = $1 AND deleted_at < $2
+`
+
+type PurgeUserParams struct {
+	ID        int64
+	DeletedAt pgtype.Timestamp
+}
+
+func (q *Queries) PurgeUser(ctx context.Context, arg PurgeUserParams) (int64, error) {
+	result, err := q.db.Exec(ctx, purgeUser, arg.ID, arg.DeletedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const restoreUser = `-- name: RestoreUser :one
+UPDATE users
+SET
+    deleted_at = NULL,
+    deactivated_at = NULL,
+    updated_at = NOW()
+WHERE
+    id = $1
+RETURNING id, email, user_name, full_name, profile_image_url, dob, hashed_password, created_at, updated_at, deleted_at, token_version, email_verified_at, verification_sent_at, totp_secret, totp_enabled_at, totp_last_step, deactivated_at, is_private, is_admin, user_name_changed_at, profile_image_thumbnail_url, profile_image_feed_url
+`
+
+func (q *Queries) RestoreUser(ctx context.Context, id int64) (User, error) {
+	row := q.db.QueryRow(ctx, restoreUser, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.UserName,
+		&i.FullName,
+		&i.ProfileImageUrl,
+		&i.Dob,
+		&i.HashedPassword,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.TokenVersion,
+		&i.EmailVerifiedAt,
+		&i.VerificationSentAt,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastStep,
+		&i.DeactivatedAt,
+		&i.IsPrivate,
+		&i.IsAdmin,
+		&i.UserNameChangedAt,
+		&i.ProfileImageThumbnailUrl,
+		&i.ProfileImageFeedUrl,
+	)
+	return i, err
+}
+
+const setUserPrivate = `-- name: SetUserPrivate :one
+UPDATE users SET is_private = $2, updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL AND deactivated_at IS NULL
+RETURNING id, email, user_name, full_name, profile_image_url, dob, hashed_password, created_at, updated_at, deleted_at, token_version, email_verified_at, verification_sent_at, totp_secret, totp_enabled_at, totp_last_step, deactivated_at, is_private, is_admin, user_name_changed_at, profile_image_thumbnail_url, profile_image_feed_url
+`
+
+type SetUserPrivateParams struct {
+	ID        int64
+	IsPrivate bool
+}
+
+func (q *Queries) SetUserPrivate(ctx context.Context, arg SetUserPrivateParams) (User, error) {
+	row := q.db.QueryRow(ctx, setUserPrivate, arg.ID, arg.IsPrivate)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.UserName,
+		&i.FullName,
+		&i.ProfileImageUrl,
+		&i.Dob,
+		&i.HashedPassword,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.TokenVersion,
+		&i.EmailVerifiedAt,
+		&i.VerificationSentAt,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastStep,
+		&i.DeactivatedAt,
+		&i.IsPrivate,
+		&i.IsAdmin,
+		&i.UserNameChangedAt,
+		&i.ProfileImageThumbnailUrl,
+		&i.ProfileImageFeedUrl,
+	)
+	return i, err
+}
+
+const setUserProfileImage = `-- name: SetUserProfileImage :one
+UPDATE users
+SET
+    profile_image_url = $2,
+    profile_image_thumbnail_url = $3,
+    profile_image_feed_url = $4,
+    updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL AND deactivated_at IS NULL
+RETURNING id, email, user_name, full_name, profile_image_url, dob, hashed_password, created_at, updated_at, deleted_at, token_version, email_verified_at, verification_sent_at, totp_secret, totp_enabled_at, totp_last_step, deactivated_at, is_private, is_admin, user_name_changed_at, profile_image_thumbnail_url, profile_image_feed_url
+`
+
+type SetUserProfileImageParams struct {
+	ID                       int64
+	ProfileImageUrl          pgtype.Text
+	ProfileImageThumbnailUrl pgtype.Text
+	ProfileImageFeedUrl      pgtype.Text
+}
+
+func (q *Queries) SetUserProfileImage(ctx context.Context, arg SetUserProfileImageParams) (User, error) {
+	row := q.db.QueryRow(ctx, setUserProfileImage,
+		arg.ID,
+		arg.ProfileImageUrl,
+		arg.ProfileImageThumbnailUrl,
+		arg.ProfileImageFeedUrl,
+	)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.UserName,
+		&i.FullName,
+		&i.ProfileImageUrl,
+		&i.Dob,
+		&i.HashedPassword,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.TokenVersion,
+		&i.EmailVerifiedAt,
+		&i.VerificationSentAt,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastStep,
+		&i.DeactivatedAt,
+		&i.IsPrivate,
+		&i.IsAdmin,
+		&i.UserNameChangedAt,
+		&i.ProfileImageThumbnailUrl,
+		&i.ProfileImageFeedUrl,
+	)
+	return i, err
+}
+
+const setUserTotpSecret = `-- name: SetUserTotpSecret :exec
+UPDATE users
+SET
+    totp_secret = $2,
+    totp_enabled_at = NULL,
+    totp_last_step = NULL,
+    updated_at = NOW()
+WHERE
+    id = $1
+`
+
+type SetUserTotpSecretParams struct {
+	ID         int64
+	TotpSecret pgtype.Text
+}
+
+func (q *Queries) SetUserTotpSecret(ctx context.Context, arg SetUserTotpSecretParams) error {
+	_, err := q.db.Exec(ctx, setUserTotpSecret, arg.ID, arg.TotpSecret)
+	return err
+}
+
+const softDeleteUser = `-- name: SoftDeleteUser :exec
+UPDATE users
+SET
+    deleted_at = NOW(),
+    updated_at = NOW()
+WHERE
+    id = $1 AND deleted_at IS NULL
+`
+
+func (q *Queries) SoftDeleteUser(ctx context.Context, id int64) error {
+	_, err := q.db.Exec(ctx, softDeleteUser, id)
+	return err
+}
+
+const updateUserEmail = `-- name: UpdateUserEmail :one
+UPDATE users
+SET
+    email = $2,
+    email_verified_at = NOW(),
+    updated_at = NOW()
+WHERE
+    id = $1
+RETURNING id, email, user_name, full_name, profile_image_url, dob, hashed_password, created_at, updated_at, deleted_at, token_version, email_verified_at, verification_sent_at, totp_secret, totp_enabled_at, totp_last_step, deactivated_at, is_private, is_admin, user_name_changed_at, profile_image_thumbnail_url, profile_image_feed_url
+`
+
+type UpdateUserEmailParams struct {
+	ID    int64
+	Email string
+}
+
+func (q *Queries) UpdateUserEmail(ctx context.Context, arg UpdateUserEmailParams) (User, error) {
+	row := q.db.QueryRow(ctx, updateUserEmail, arg.ID, arg.Email)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.UserName,
+		&i.FullName,
+		&i.ProfileImageUrl,
+		&i.Dob,
+		&i.HashedPassword,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.TokenVersion,
+		&i.EmailVerifiedAt,
+		&i.VerificationSentAt,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastStep,
+		&i.DeactivatedAt,
+		&i.IsPrivate,
+		&i.IsAdmin,
+		&i.UserNameChangedAt,
+		&i.ProfileImageThumbnailUrl,
+		&i.ProfileImageFeedUrl,
+	)
+	return i, err
+}
+
+const updateUserPassword = `-- name: UpdateUserPassword :exec
+UPDATE users
+SET
+    hashed_password = $2,
+    updated_at = NOW()
+WHERE
+    id = $1
+`
+
+type UpdateUserPasswordParams struct {
+	ID             int64
+	HashedPassword string
+}
+
+func (q *Queries) UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) error {
+	_, err := q.db.Exec(ctx, updateUserPassword, arg.ID, arg.HashedPassword)
+	return err
+}
+
+const updateUserProfile = `-- name: UpdateUserProfile :one
+UPDATE users
+SET
+    full_name = COALESCE($1, full_name),
+    dob = CASE WHEN $2::boolean THEN NULL ELSE COALESCE($3, dob) END,
+    updated_at = NOW()
+WHERE
+    id = $4 AND deleted_at IS NULL AND deactivated_at IS NULL
+RETURNING id, email, user_name, full_name, profile_image_url, dob, hashed_password, created_at, updated_at, deleted_at, token_version, email_verified_at, verification_sent_at, totp_secret, totp_enabled_at, totp_last_step, deactivated_at, is_private, is_admin, user_name_changed_at, profile_image_thumbnail_url, profile_image_feed_url
 `
 
 type UpdateUserProfileParams struct {
-	ID              int64
-	FullName        string
-	UserName        string
-	ProfileImageUrl pgtype.Text
-	Dob             pgtype.Date
+	FullName pgtype.Text
+	ClearDob bool
+	Dob      pgtype.Date
+	ID       int64
 }
 
 func (q *Queries) UpdateUserProfile(ctx context.Context, arg UpdateUserProfileParams) (User, error) {
 	row := q.db.QueryRow(ctx, updateUserProfile,
-		arg.ID,
 		arg.FullName,
-		arg.UserName,
-		arg.ProfileImageUrl,
+		arg.ClearDob,
 		arg.Dob,
+		arg.ID,
 	)
 	var i User
 	err := row.Scan(
@@ -150,6 +794,60 @@ func (q *Queries) UpdateUserProfile(ctx context.Context, arg UpdateUserProfilePa
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.DeletedAt,
+		&i.TokenVersion,
+		&i.EmailVerifiedAt,
+		&i.VerificationSentAt,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastStep,
+		&i.DeactivatedAt,
+		&i.IsPrivate,
+		&i.IsAdmin,
+		&i.UserNameChangedAt,
+		&i.ProfileImageThumbnailUrl,
+		&i.ProfileImageFeedUrl,
 	)
 	return i, err
 }
+
+const updateUserTotpLastStep = `-- name: UpdateUserTotpLastStep :execrows
+UPDATE users
+SET
+    totp_last_step = $2
+WHERE
+    id = $1 AND (totp_last_step IS NULL OR totp_last_step < $2)
+`
+
+type UpdateUserTotpLastStepParams struct {
+	ID           int64
+	TotpLastStep pgtype.Int8
+}
+
+func (q *Queries) UpdateUserTotpLastStep(ctx context.Context, arg UpdateUserTotpLastStepParams) (int64, error) {
+	result, err := q.db.Exec(ctx, updateUserTotpLastStep, arg.ID, arg.TotpLastStep)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const updateUserVerificationSentAt = `-- name: UpdateUserVerificationSentAt :execrows
+UPDATE users
+SET
+    verification_sent_at = NOW()
+WHERE
+    id = $1 AND (verification_sent_at IS NULL OR verification_sent_at < $2)
+`
+
+type UpdateUserVerificationSentAtParams struct {
+	ID                 int64
+	VerificationSentAt pgtype.Timestamp
+}
+
+func (q *Queries) UpdateUserVerificationSentAt(ctx context.Context, arg UpdateUserVerificationSentAtParams) (int64, error) {
+	result, err := q.db.Exec(ctx, updateUserVerificationSentAt, arg.ID, arg.VerificationSentAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
@@ -27,6 +27,41 @@ func (q *Queries) DeleteAllUsersHasInterests(ctx context.Context) error {
 	return err
 }
 
+const deleteInterestForUser = `-- name: DeleteInterestForUser :execrows
+DELETE FROM users_has_interests WHERE user_id = $1 AND interest_id = $2
+`
+
+type DeleteInterestForUserParams struct {
+	UserID     int64
+	InterestID int64
+}
+
+func (q *Queries) DeleteInterestForUser(ctx context.Context, arg DeleteInterestForUserParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteInterestForUser, arg.UserID, arg.InterestID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteInterestsForUser = `-- name: DeleteInterestsForUser :exec
+DELETE FROM users_has_interests WHERE user_id = $1
+`
+
+func (q *Queries) DeleteInterestsForUser(ctx context.Context, userID int64) error {
+	_, err := q.db.Exec(ctx, deleteInterestsForUser, userID)
+	return err
+}
+
+const deleteUsersHasInterestsForInterest = `-- name: DeleteUsersHasInterestsForInterest :exec
+DELETE FROM users_has_interests WHERE interest_id = $1
+`
+
+func (q *Queries) DeleteUsersHasInterestsForInterest(ctx context.Context, interestID int64) error {
+	_, err := q.db.Exec(ctx, deleteUsersHasInterestsForInterest, interestID)
+	return err
+}
+
 const getDuplicateInterestIds = `-- name: GetDuplicateInterestIds :many
 SELECT interest_id 
 FROM users_has_interests
@@ -57,3 +92,21 @@ func (q *Queries) GetDuplicateInterestIds(ctx context.Context, arg GetDuplicateI
 	}
 	return items, nil
 }
+
+const mergeUsersHasInterests = `-- name: MergeUsersHasInterests :exec
+INSERT INTO users_has_interests(user_id, interest_id, created_at, updated_at)
+SELECT user_id, $1, created_at, NOW()
+FROM users_has_interests
+WHERE interest_id = $2
+ON CONFLICT (user_id, interest_id) DO NOTHING
+`
+
+type MergeUsersHasInterestsParams struct {
+	IntoID int64
+	FromID int64
+}
+
+func (q *Queries) MergeUsersHasInterests(ctx context.Context, arg MergeUsersHasInterestsParams) error {
+	_, err := q.db.Exec(ctx, mergeUsersHasInterests, arg.IntoID, arg.FromID)
+	return err
+}
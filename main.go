@@ -4,14 +4,20 @@ import (
 	"context"
 	"log"
 	"net/http"
+	"net/url"
 	"os"
+	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/config"
-	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/joho/godotenv"
 	_ "github.com/lib/pq"
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/app"
 	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/app/handlers"
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/app/jwtkeys"
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/app/mailer"
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/app/ranking"
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/app/storage"
 	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/database"
 	"go.uber.org/zap"
 )
@@ -35,20 +41,108 @@ func main() {
 	// 	log.Fatal("Error loading .env file: %w", err)
 	// }
 
-	// AWS Set up
-	s3Bucket := os.Getenv("S3_BUCKET")
-	if s3Bucket == "" {
-		log.Fatal("S3_BUCKET environment variable is not set")
+	// File storage. STORAGE_DRIVER is s3 (the default), s3-compatible for MinIO and other S3 compatible servers,
+	// or local to keep the files on disk and serve them under /files/ without any cloud account.
+	var fileStorage storage.Storage
+	var localStorage *storage.LocalStorage
+	var localFilesPath string
+	switch storageDriver := os.Getenv("STORAGE_DRIVER"); storageDriver {
+	case "local":
+		storageDir := os.Getenv("STORAGE_DIR")
+		if storageDir == "" {
+			storageDir = "uploads"
+		}
+		storageBaseUrl := os.Getenv("STORAGE_BASE_URL")
+		if storageBaseUrl == "" {
+			storageBaseUrl = "http://localhost:8080/files/"
+		}
+		// The files are served under the path of the base url
+		parsedBaseUrl, parseErr := url.Parse(storageBaseUrl)
+		if parseErr != nil || !strings.HasSuffix(parsedBaseUrl.Path, "/") {
+			log.Fatal("STORAGE_BASE_URL must be a url ending with a slash")
+		}
+		localFilesPath = parsedBaseUrl.Path
+		localStorage = &storage.LocalStorage{
+			Dir:     storageDir,
+			BaseURL: storageBaseUrl,
+		}
+		fileStorage = localStorage
+	case "", "s3", "s3-compatible":
+		s3Bucket := os.Getenv("S3_BUCKET")
+		if s3Bucket == "" {
+			log.Fatal("S3_BUCKET environment variable is not set")
+		}
+
+		// S3 compatible servers usually ignore the region, but requests still have to be signed with one
+		s3Region := os.Getenv("S3_REGION")
+		if s3Region == "" && storageDriver == "s3-compatible" {
+			s3Region = "us-east-1"
+		}
+		if s3Region == "" {
+			log.Fatal("S3_REGION environment variable is not set")
+		}
+
+		awsCfg, err := config.LoadDefaultConfig(context.Background(), config.WithRegion(s3Region))
+		if err != nil {
+			log.Fatal("error configuring aws s3")
+		}
+
+		if storageDriver == "s3-compatible" {
+			s3Endpoint := os.Getenv("S3_ENDPOINT")
+			if s3Endpoint == "" {
+				log.Fatal("S3_ENDPOINT environment variable is not set")
+			}
+			fileStorage = storage.NewS3CompatibleStorage(awsCfg, s3Bucket, s3Endpoint, os.Getenv("S3_PUBLIC_URL"))
+		} else {
+			fileStorage = storage.NewS3Storage(awsCfg, s3Bucket)
+		}
+	default:
+		log.Fatalf("unknown STORAGE_DRIVER %v", storageDriver)
 	}
 
-	s3Region := os.Getenv("S3_REGION")
-	if s3Region == "" {
-		log.Fatal("S3_REGION environment variable is not set")
+	// Mailer. Writes mails to disk unless smtp is configured.
+	var appMailer mailer.Mailer
+	if os.Getenv("MAIL_DRIVER") == "smtp" {
+		appMailer = &mailer.SMTPMailer{
+			Host:     os.Getenv("SMTP_HOST"),
+			Port:     os.Getenv("SMTP_PORT"),
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     os.Getenv("MAIL_FROM"),
+		}
+	} else {
+		mailDir := os.Getenv("MAIL_DIR")
+		if mailDir == "" {
+			mailDir = "mails"
+		}
+		appMailer = &mailer.FileMailer{
+			Dir:  mailDir,
+			From: os.Getenv("MAIL_FROM"),
+		}
 	}
 
-	awsCfg, err := config.LoadDefaultConfig(context.Background(), config.WithRegion(s3Region))
-	if err != nil {
-		log.Fatal("error configuring aws s3")
+	// JWT keys. Signs with the RS256/EdDSA keys in JWT_KEYS_DIR if it is set, otherwise with the HS256 TOKEN_SECRET.
+	// TOKEN_SECRET still verifies the old HS256 tokens while moving to the keys.
+	var jwtKeys *jwtkeys.KeySet
+	if keysDir := os.Getenv("JWT_KEYS_DIR"); keysDir != "" {
+		loadedKeys, loadKeysErr := jwtkeys.LoadKeySet(keysDir, os.Getenv("JWT_SIGNING_KID"), os.Getenv("TOKEN_SECRET"))
+		if loadKeysErr != nil {
+			log.Fatalf("error loading jwt keys %v", loadKeysErr)
+		}
+		jwtKeys = loadedKeys
+	} else {
+		jwtKeys = jwtkeys.NewHMACKeySet(os.Getenv("TOKEN_SECRET"))
+	}
+
+	// Weights of the for you feed. Unset values keep the defaults.
+	feedWeights, feedWeightsErr := ranking.ParseWeights(
+		os.Getenv("FEED_INTEREST_WEIGHT"),
+		os.Getenv("FEED_LIKE_WEIGHT"),
+		os.Getenv("FEED_COMMENT_WEIGHT"),
+		os.Getenv("FEED_HALF_LIFE"),
+	)
+	if feedWeightsErr != nil {
+		log.Fatalf("error loading feed weights %v", feedWeightsErr)
 	}
 
 	// Logger
@@ -58,31 +152,100 @@ func main() {
 	}
 
 	// Config
+	db := database.New(pool)
 	apiCfg := handlers.ApiConfig{
-		Db:          database.New(pool),
-		TokenSecret: os.Getenv("TOKEN_SECRET"),
+		Db:          db,
+		Pool:        pool,
+		Keys:        jwtKeys,
 		Platform:    os.Getenv("PLATFORM"),
-		S3Bucket:    s3Bucket,
-		S3Region:    s3Region,
-		S3Client:    s3.NewFromConfig(awsCfg),
+		Storage:     fileStorage,
 		Logger:      logger,
+		Revocations: handlers.NewTokenRevocationCache(db, app.REVOCATION_CACHE_TTL),
+		Mailer:      appMailer,
+		FeedWeights: &feedWeights,
 	}
 
+	// Hard delete accounts once their grace period is over
+	go apiCfg.StartAccountPurger(context.Background(), app.ACCOUNT_PURGE_INTERVAL)
+
+	// Remove the media of deleted posts from storage
+	go apiCfg.StartPostMediaPurger(context.Background(), app.POST_MEDIA_PURGE_INTERVAL)
+
 	// New http server mux
 	mux := http.NewServeMux()
 
-	// Add Handlers
+	// Uploaded files, when they are kept on disk
+	if localStorage != nil {
+		mux.HandleFunc("GET "+localFilesPath+"{key...}", localStorage.ServeFile)
+	}
+
+	// Public routes
+	mux.HandleFunc("GET /.well-known/jwks.json", apiCfg.JWKSHandler)
 	mux.HandleFunc("POST /api/register", apiCfg.RegisterHandler)
 	mux.HandleFunc("POST /api/reset", apiCfg.ResetHandler)
 	mux.HandleFunc("POST /api/login", apiCfg.LoginHandler)
-	mux.HandleFunc("POST /api/updateUser", apiCfg.UpdateUserHandler)
+	mux.HandleFunc("POST /api/login/2fa", apiCfg.LoginTwoFactorHandler)
+	mux.HandleFunc("POST /api/token/refresh", apiCfg.RefreshTokenHandler)
+	mux.HandleFunc("POST /api/password/forgot", apiCfg.ForgotPasswordHandler)
+	mux.HandleFunc("POST /api/password/reset", apiCfg.ResetPasswordHandler)
 	mux.HandleFunc("GET /api/interests", apiCfg.GetAllInterests)
-	mux.HandleFunc("POST /api/posts", apiCfg.CreatePostHandler)
-	mux.HandleFunc("GET /api/posts", apiCfg.GetAllPostsHandler)
-	mux.HandleFunc("GET /api/posts/{post_id}", apiCfg.GetPostById)
-	mux.HandleFunc("POST /api/post_like", apiCfg.PostLikeHandler)
-	mux.HandleFunc("POST /api/comments", apiCfg.CreateCommentHandler)
-	mux.HandleFunc("GET /api/comments", apiCfg.GetAllCommentsHandler)
+	mux.HandleFunc("GET /api/verify-email", apiCfg.VerifyEmailHandler)
+	mux.HandleFunc("GET /api/me/email/confirm", apiCfg.ConfirmEmailChangeHandler)
+
+	// Authenticated routes
+	mux.HandleFunc("POST /api/logout", apiCfg.Authenticated(apiCfg.LogoutHandler))
+	mux.HandleFunc("POST /api/logout/all", apiCfg.Authenticated(apiCfg.LogoutAllHandler))
+	mux.HandleFunc("POST /api/me/password", apiCfg.AuthenticatedWithUser(apiCfg.ChangePasswordHandler))
+	mux.HandleFunc("POST /api/me/email", apiCfg.AuthenticatedWithUser(apiCfg.ChangeEmailHandler))
+	mux.HandleFunc("DELETE /api/me", apiCfg.AuthenticatedWithUser(apiCfg.DeleteAccountHandler))
+	mux.HandleFunc("PATCH /api/me", apiCfg.AuthenticatedWithUser(apiCfg.PatchMeHandler))
+	mux.HandleFunc("PUT /api/me/avatar", apiCfg.AuthenticatedWithUser(apiCfg.PutAvatarHandler))
+	mux.HandleFunc("DELETE /api/me/avatar", apiCfg.AuthenticatedWithUser(apiCfg.DeleteAvatarHandler))
+	mux.HandleFunc("PUT /api/me/interests", apiCfg.AuthenticatedWithUser(apiCfg.ReplaceMyInterestsHandler))
+	mux.HandleFunc("DELETE /api/me/interests/{interest_id}", apiCfg.Authenticated(apiCfg.RemoveMyInterestHandler))
+	mux.HandleFunc("POST /api/me/deactivate", apiCfg.AuthenticatedWithUser(apiCfg.DeactivateAccountHandler))
+	mux.HandleFunc("POST /api/2fa/enroll", apiCfg.AuthenticatedWithUser(apiCfg.EnrollTwoFactorHandler))
+	mux.HandleFunc("POST /api/2fa/confirm", apiCfg.AuthenticatedWithUser(apiCfg.ConfirmTwoFactorHandler))
+	mux.HandleFunc("POST /api/2fa/disable", apiCfg.AuthenticatedWithUser(apiCfg.DisableTwoFactorHandler))
+	mux.HandleFunc("POST /api/verify-email/resend", apiCfg.AuthenticatedWithUser(apiCfg.ResendVerificationEmailHandler))
+	mux.HandleFunc("POST /api/updateUser", apiCfg.AuthenticatedWithUser(apiCfg.UpdateUserHandler))
+	mux.HandleFunc("POST /api/posts", apiCfg.AuthenticatedWithUser(apiCfg.RequireVerifiedEmail(apiCfg.CreatePostHandler)))
+	mux.HandleFunc("GET /api/posts", apiCfg.Authenticated(apiCfg.GetAllPostsHandler))
+	mux.HandleFunc("GET /api/feed/home", apiCfg.Authenticated(apiCfg.HomeFeedHandler))
+	mux.HandleFunc("GET /api/feed/for-you", apiCfg.Authenticated(apiCfg.ForYouFeedHandler))
+	mux.HandleFunc("GET /api/posts/{post_id}", apiCfg.Authenticated(apiCfg.GetPostById))
+	mux.HandleFunc("PATCH /api/posts/{post_id}", apiCfg.Authenticated(apiCfg.EditPostHandler))
+	mux.HandleFunc("DELETE /api/posts/{post_id}", apiCfg.Authenticated(apiCfg.DeletePostHandler))
+	mux.HandleFunc("GET /api/posts/{post_id}/comments", apiCfg.Authenticated(apiCfg.GetPostCommentsHandler))
+	mux.HandleFunc("GET /api/posts/{post_id}/revisions", apiCfg.Authenticated(apiCfg.GetPostRevisionsHandler))
+	mux.HandleFunc("POST /api/post_like", apiCfg.Authenticated(apiCfg.PostLikeHandler))
+	mux.HandleFunc("POST /api/comments", apiCfg.AuthenticatedWithUser(apiCfg.RequireVerifiedEmail(apiCfg.CreateCommentHandler)))
+	mux.HandleFunc("GET /api/comments", apiCfg.Authenticated(apiCfg.GetAllCommentsHandler))
+	mux.HandleFunc("GET /api/users/check-username", apiCfg.Authenticated(apiCfg.CheckUserNameHandler))
+	// Under its own prefix, /api/users/by-handle/{handle} would conflict with /api/users/{user_id}/followers
+	mux.HandleFunc("GET /api/handles/{handle}", apiCfg.Authenticated(apiCfg.GetUserByHandleHandler))
+	mux.HandleFunc("GET /api/users/{user_id}", apiCfg.Authenticated(apiCfg.GetUserProfileHandler))
+	mux.HandleFunc("GET /api/users/{user_id}/followers", apiCfg.Authenticated(apiCfg.GetFollowersHandler))
+	mux.HandleFunc("GET /api/users/{user_id}/following", apiCfg.Authenticated(apiCfg.GetFollowingHandler))
+	mux.HandleFunc("POST /api/users/{user_id}/follow", apiCfg.Authenticated(apiCfg.FollowUserHandler))
+	mux.HandleFunc("DELETE /api/users/{user_id}/follow", apiCfg.Authenticated(apiCfg.UnfollowUserHandler))
+	mux.HandleFunc("POST /api/users/{user_id}/block", apiCfg.Authenticated(apiCfg.BlockUserHandler))
+	mux.HandleFunc("DELETE /api/users/{user_id}/block", apiCfg.Authenticated(apiCfg.UnblockUserHandler))
+	mux.HandleFunc("POST /api/users/{user_id}/mute", apiCfg.Authenticated(apiCfg.MuteUserHandler))
+	mux.HandleFunc("DELETE /api/users/{user_id}/mute", apiCfg.Authenticated(apiCfg.UnmuteUserHandler))
+	mux.HandleFunc("GET /api/me/blocks", apiCfg.Authenticated(apiCfg.GetBlockedUsersHandler))
+	mux.HandleFunc("GET /api/me/mutes", apiCfg.Authenticated(apiCfg.GetMutedUsersHandler))
+	mux.HandleFunc("DELETE /api/users/{user_id}/follow-request", apiCfg.Authenticated(apiCfg.CancelFollowRequestHandler))
+	mux.HandleFunc("POST /api/me/privacy", apiCfg.AuthenticatedWithUser(apiCfg.UpdatePrivacyHandler))
+	mux.HandleFunc("GET /api/me/follow-requests", apiCfg.Authenticated(apiCfg.GetFollowRequestsHandler))
+	mux.HandleFunc("POST /api/me/follow-requests/{user_id}/approve", apiCfg.Authenticated(apiCfg.ApproveFollowRequestHandler))
+	mux.HandleFunc("POST /api/me/follow-requests/{user_id}/deny", apiCfg.Authenticated(apiCfg.DenyFollowRequestHandler))
+
+	// Admin routes
+	mux.HandleFunc("POST /api/admin/interests", apiCfg.AuthenticatedWithUser(apiCfg.RequireAdmin(apiCfg.CreateInterestHandler)))
+	mux.HandleFunc("PATCH /api/admin/interests/{interest_id}", apiCfg.AuthenticatedWithUser(apiCfg.RequireAdmin(apiCfg.RenameInterestHandler)))
+	mux.HandleFunc("DELETE /api/admin/interests/{interest_id}", apiCfg.AuthenticatedWithUser(apiCfg.RequireAdmin(apiCfg.DeleteInterestHandler)))
+	mux.HandleFunc("POST /api/admin/interests/{interest_id}/merge", apiCfg.AuthenticatedWithUser(apiCfg.RequireAdmin(apiCfg.MergeInterestHandler)))
 
 	// New http server
 	server := http.Server{
@@ -18,17 +18,95 @@ SELECT
     c.user_id,
     c.post_id,
     u.id AS author_id, 
-    u.email AS author_email, 
     u.user_name AS author_user_name,
     u.full_name AS author_full_name,
     u.profile_image_url AS author_profile_image_url,
-    u.dob AS author_dob,
-    u.created_at AS author_created_at, 
-    u.updated_at AS author_updated_at
+    u.profile_image_feed_url AS author_profile_image_feed_url,
+    u.profile_image_thumbnail_url AS author_profile_image_thumbnail_url,
+    u.created_at AS author_created_at,
+    (
+        SELECT EXISTS(
+            SELECT 1 FROM follows af WHERE af.follower_id = sqlc.arg(viewer_id) AND af.followee_id = u.id
+        )
+    ) AS author_is_following,
+    (
+        SELECT EXISTS(
+            SELECT 1 FROM follows ay WHERE ay.follower_id = u.id AND ay.followee_id = sqlc.arg(viewer_id)
+        )
+    ) AS author_follows_you
 FROM comments c 
 INNER JOIN users u ON c.user_id = u.id
-WHERE c.post_id = $1
+WHERE c.post_id = sqlc.arg(post_id) AND u.deleted_at IS NULL AND u.deactivated_at IS NULL
+    AND NOT EXISTS(SELECT 1 FROM user_blocks vb WHERE vb.blocker_id = sqlc.arg(viewer_id) AND vb.blocked_id = c.user_id)
+    AND NOT EXISTS(SELECT 1 FROM user_mutes vm WHERE vm.muter_id = sqlc.arg(viewer_id) AND vm.muted_id = c.user_id)
 ORDER BY c.created_at ASC;
-    
 
+-- name: GetCommentsForPostAfter :many
+SELECT
+    c.id,
+    c.content,
+    c.created_at,
+    c.updated_at,
+    c.user_id,
+    c.post_id,
+    u.id AS author_id, 
+    u.user_name AS author_user_name,
+    u.full_name AS author_full_name,
+    u.profile_image_url AS author_profile_image_url,
+    u.profile_image_feed_url AS author_profile_image_feed_url,
+    u.profile_image_thumbnail_url AS author_profile_image_thumbnail_url,
+    u.created_at AS author_created_at,
+    (
+        SELECT EXISTS(
+            SELECT 1 FROM follows af WHERE af.follower_id = sqlc.arg(viewer_id) AND af.followee_id = u.id
+        )
+    ) AS author_is_following,
+    (
+        SELECT EXISTS(
+            SELECT 1 FROM follows ay WHERE ay.follower_id = u.id AND ay.followee_id = sqlc.arg(viewer_id)
+        )
+    ) AS author_follows_you
+FROM comments c 
+INNER JOIN users u ON c.user_id = u.id
+WHERE c.post_id = sqlc.arg(post_id)
+    AND (c.created_at, c.id) > (sqlc.arg(cursor_created_at)::timestamp, sqlc.arg(cursor_id)::bigint)
+    AND u.deleted_at IS NULL AND u.deactivated_at IS NULL
+    AND NOT EXISTS(SELECT 1 FROM user_blocks vb WHERE vb.blocker_id = sqlc.arg(viewer_id) AND vb.blocked_id = c.user_id)
+    AND NOT EXISTS(SELECT 1 FROM user_mutes vm WHERE vm.muter_id = sqlc.arg(viewer_id) AND vm.muted_id = c.user_id)
+ORDER BY c.created_at ASC, c.id ASC
+LIMIT sqlc.arg(page_limit);
 
+-- name: GetCommentsForPostBefore :many
+SELECT
+    c.id,
+    c.content,
+    c.created_at,
+    c.updated_at,
+    c.user_id,
+    c.post_id,
+    u.id AS author_id, 
+    u.user_name AS author_user_name,
+    u.full_name AS author_full_name,
+    u.profile_image_url AS author_profile_image_url,
+    u.profile_image_feed_url AS author_profile_image_feed_url,
+    u.profile_image_thumbnail_url AS author_profile_image_thumbnail_url,
+    u.created_at AS author_created_at,
+    (
+        SELECT EXISTS(
+            SELECT 1 FROM follows af WHERE af.follower_id = sqlc.arg(viewer_id) AND af.followee_id = u.id
+        )
+    ) AS author_is_following,
+    (
+        SELECT EXISTS(
+            SELECT 1 FROM follows ay WHERE ay.follower_id = u.id AND ay.followee_id = sqlc.arg(viewer_id)
+        )
+    ) AS author_follows_you
+FROM comments c 
+INNER JOIN users u ON c.user_id = u.id
+WHERE c.post_id = sqlc.arg(post_id)
+    AND (c.created_at, c.id) < (sqlc.arg(cursor_created_at)::timestamp, sqlc.arg(cursor_id)::bigint)
+    AND u.deleted_at IS NULL AND u.deactivated_at IS NULL
+    AND NOT EXISTS(SELECT 1 FROM user_blocks vb WHERE vb.blocker_id = sqlc.arg(viewer_id) AND vb.blocked_id = c.user_id)
+    AND NOT EXISTS(SELECT 1 FROM user_mutes vm WHERE vm.muter_id = sqlc.arg(viewer_id) AND vm.muted_id = c.user_id)
+ORDER BY c.created_at DESC, c.id DESC
+LIMIT sqlc.arg(page_limit);
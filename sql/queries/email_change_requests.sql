@@ -0,0 +1,28 @@
+-- name: CreateEmailChangeRequest :one
+INSERT INTO email_change_requests(user_id, new_email, token_hash, expires_at, created_at)
+VALUES(
+    $1,
+    $2,
+    $3,
+    $4,
+    NOW()
+)
+RETURNING *;
+
+-- name: GetEmailChangeRequestByHash :one
+SELECT * FROM email_change_requests
+WHERE token_hash = $1;
+
+-- name: MarkEmailChangeRequestUsed :execrows
+UPDATE email_change_requests
+SET
+    used_at = NOW()
+WHERE
+    id = $1 AND used_at IS NULL AND expires_at > NOW();
+
+-- name: InvalidateEmailChangeRequestsForUser :exec
+UPDATE email_change_requests
+SET
+    used_at = NOW()
+WHERE
+    user_id = $1 AND used_at IS NULL;
@@ -0,0 +1,52 @@
+-- name: CreateFollowRequest :execrows
+INSERT INTO follow_requests(requester_id, target_id, created_at)
+VALUES(
+    $1,
+    $2,
+    NOW()
+)
+ON CONFLICT (requester_id, target_id) DO NOTHING;
+
+-- name: DeleteFollowRequest :execrows
+DELETE FROM follow_requests WHERE requester_id = $1 AND target_id = $2;
+
+-- name: DeleteFollowRequestsBetween :exec
+DELETE FROM follow_requests
+WHERE (requester_id = sqlc.arg(user_id) AND target_id = sqlc.arg(other_user_id))
+    OR (requester_id = sqlc.arg(other_user_id) AND target_id = sqlc.arg(user_id));
+
+-- name: ApproveFollowRequest :execrows
+WITH approved AS (
+    DELETE FROM follow_requests
+    WHERE requester_id = $1 AND target_id = $2
+    RETURNING requester_id, target_id
+)
+INSERT INTO follows(follower_id, followee_id, created_at)
+SELECT requester_id, target_id, NOW() FROM approved
+ON CONFLICT (follower_id, followee_id) DO NOTHING;
+
+-- name: ApproveAllFollowRequests :exec
+WITH approved AS (
+    DELETE FROM follow_requests
+    WHERE target_id = $1
+    RETURNING requester_id, target_id
+)
+INSERT INTO follows(follower_id, followee_id, created_at)
+SELECT requester_id, target_id, NOW() FROM approved
+ON CONFLICT (follower_id, followee_id) DO NOTHING;
+
+-- name: GetFollowRequests :many
+SELECT
+    u.id,
+    u.user_name,
+    u.full_name,
+    u.profile_image_url,
+    u.profile_image_feed_url,
+    u.profile_image_thumbnail_url,
+    u.created_at,
+    fr.created_at AS requested_at
+FROM follow_requests fr
+INNER JOIN users u ON fr.requester_id = u.id
+WHERE fr.target_id = sqlc.arg(user_id) AND u.deleted_at IS NULL AND u.deactivated_at IS NULL
+ORDER BY fr.created_at DESC, fr.id DESC
+LIMIT sqlc.arg(page_limit) OFFSET sqlc.arg(page_offset);
@@ -0,0 +1,195 @@
+-- name: CreateFollow :execrows
+INSERT INTO follows(follower_id, followee_id, created_at)
+VALUES(
+    $1,
+    $2,
+    NOW()
+)
+ON CONFLICT (follower_id, followee_id) DO NOTHING;
+
+-- name: DeleteFollow :execrows
+DELETE FROM follows WHERE follower_id = $1 AND followee_id = $2;
+
+-- name: GetFollowStatus :one
+SELECT
+    (
+        SELECT EXISTS(
+            SELECT 1 FROM follows f WHERE f.follower_id = $1 AND f.followee_id = $2
+        )
+    ) AS is_following,
+    (
+        SELECT EXISTS(
+            SELECT 1 FROM follows fy WHERE fy.follower_id = $2 AND fy.followee_id = $1
+        )
+    ) AS follows_you,
+    (
+        SELECT EXISTS(
+            SELECT 1 FROM follow_requests fr WHERE fr.requester_id = $1 AND fr.target_id = $2
+        )
+    ) AS follow_requested;
+
+-- name: GetFollowers :many
+SELECT
+    u.id,
+    u.user_name,
+    u.full_name,
+    u.profile_image_url,
+    u.profile_image_feed_url,
+    u.profile_image_thumbnail_url,
+    u.created_at,
+    (
+        SELECT EXISTS(
+            SELECT 1 FROM follows vf WHERE vf.follower_id = sqlc.arg(viewer_id) AND vf.followee_id = u.id
+        )
+    ) AS is_following,
+    (
+        SELECT EXISTS(
+            SELECT 1 FROM follows vy WHERE vy.follower_id = u.id AND vy.followee_id = sqlc.arg(viewer_id)
+        )
+    ) AS follows_you
+FROM follows f
+INNER JOIN users u ON f.follower_id = u.id
+WHERE f.followee_id = sqlc.arg(user_id) AND u.deleted_at IS NULL AND u.deactivated_at IS NULL
+ORDER BY f.created_at DESC, f.id DESC
+LIMIT sqlc.arg(page_limit) OFFSET sqlc.arg(page_offset);
+
+-- name: GetFollowing :many
+SELECT
+    u.id,
+    u.user_name,
+    u.full_name,
+    u.profile_image_url,
+    u.profile_image_feed_url,
+    u.profile_image_thumbnail_url,
+    u.created_at,
+    (
+        SELECT EXISTS(
+            SELECT 1 FROM follows vf WHERE vf.follower_id = sqlc.arg(viewer_id) AND vf.followee_id = u.id
+        )
+    ) AS is_following,
+    (
+        SELECT EXISTS(
+            SELECT 1 FROM follows vy WHERE vy.follower_id = u.id AND vy.followee_id = sqlc.arg(viewer_id)
+        )
+    ) AS follows_you
+FROM follows f
+INNER JOIN users u ON f.followee_id = u.id
+WHERE f.follower_id = sqlc.arg(user_id) AND u.deleted_at IS NULL AND u.deactivated_at IS NULL
+ORDER BY f.created_at DESC, f.id DESC
+LIMIT sqlc.arg(page_limit) OFFSET sqlc.arg(page_offset);
+
+-- name: GetFollowersAfter :many
+SELECT
+    u.id,
+    u.user_name,
+    u.full_name,
+    u.profile_image_url,
+    u.profile_image_feed_url,
+    u.profile_image_thumbnail_url,
+    u.created_at,
+    f.id AS follow_id,
+    f.created_at AS followed_at,
+    (
+        SELECT EXISTS(
+            SELECT 1 FROM follows vf WHERE vf.follower_id = sqlc.arg(viewer_id) AND vf.followee_id = u.id
+        )
+    ) AS is_following,
+    (
+        SELECT EXISTS(
+            SELECT 1 FROM follows vy WHERE vy.follower_id = u.id AND vy.followee_id = sqlc.arg(viewer_id)
+        )
+    ) AS follows_you
+FROM follows f
+INNER JOIN users u ON f.follower_id = u.id
+WHERE f.followee_id = sqlc.arg(user_id)
+    AND (f.created_at, f.id) > (sqlc.arg(cursor_created_at)::timestamp, sqlc.arg(cursor_id)::bigint)
+    AND u.deleted_at IS NULL AND u.deactivated_at IS NULL
+ORDER BY f.created_at ASC, f.id ASC
+LIMIT sqlc.arg(page_limit);
+
+-- name: GetFollowersBefore :many
+SELECT
+    u.id,
+    u.user_name,
+    u.full_name,
+    u.profile_image_url,
+    u.profile_image_feed_url,
+    u.profile_image_thumbnail_url,
+    u.created_at,
+    f.id AS follow_id,
+    f.created_at AS followed_at,
+    (
+        SELECT EXISTS(
+            SELECT 1 FROM follows vf WHERE vf.follower_id = sqlc.arg(viewer_id) AND vf.followee_id = u.id
+        )
+    ) AS is_following,
+    (
+        SELECT EXISTS(
+            SELECT 1 FROM follows vy WHERE vy.follower_id = u.id AND vy.followee_id = sqlc.arg(viewer_id)
+        )
+    ) AS follows_you
+FROM follows f
+INNER JOIN users u ON f.follower_id = u.id
+WHERE f.followee_id = sqlc.arg(user_id)
+    AND (f.created_at, f.id) < (sqlc.arg(cursor_created_at)::timestamp, sqlc.arg(cursor_id)::bigint)
+    AND u.deleted_at IS NULL AND u.deactivated_at IS NULL
+ORDER BY f.created_at DESC, f.id DESC
+LIMIT sqlc.arg(page_limit);
+
+-- name: GetFollowingAfter :many
+SELECT
+    u.id,
+    u.user_name,
+    u.full_name,
+    u.profile_image_url,
+    u.profile_image_feed_url,
+    u.profile_image_thumbnail_url,
+    u.created_at,
+    f.id AS follow_id,
+    f.created_at AS followed_at,
+    (
+        SELECT EXISTS(
+            SELECT 1 FROM follows vf WHERE vf.follower_id = sqlc.arg(viewer_id) AND vf.followee_id = u.id
+        )
+    ) AS is_following,
+    (
+        SELECT EXISTS(
+            SELECT 1 FROM follows vy WHERE vy.follower_id = u.id AND vy.followee_id = sqlc.arg(viewer_id)
+        )
+    ) AS follows_you
+FROM follows f
+INNER JOIN users u ON f.followee_id = u.id
+WHERE f.follower_id = sqlc.arg(user_id)
+    AND (f.created_at, f.id) > (sqlc.arg(cursor_created_at)::timestamp, sqlc.arg(cursor_id)::bigint)
+    AND u.deleted_at IS NULL AND u.deactivated_at IS NULL
+ORDER BY f.created_at ASC, f.id ASC
+LIMIT sqlc.arg(page_limit);
+
+-- name: GetFollowingBefore :many
+SELECT
+    u.id,
+    u.user_name,
+    u.full_name,
+    u.profile_image_url,
+    u.profile_image_feed_url,
+    u.profile_image_thumbnail_url,
+    u.created_at,
+    f.id AS follow_id,
+    f.created_at AS followed_at,
+    (
+        SELECT EXISTS(
+            SELECT 1 FROM follows vf WHERE vf.follower_id = sqlc.arg(viewer_id) AND vf.followee_id = u.id
+        )
+    ) AS is_following,
+    (
+        SELECT EXISTS(
+            SELECT 1 FROM follows vy WHERE vy.follower_id = u.id AND vy.followee_id = sqlc.arg(viewer_id)
+        )
+    ) AS follows_you
+FROM follows f
+INNER JOIN users u ON f.followee_id = u.id
+WHERE f.follower_id = sqlc.arg(user_id)
+    AND (f.created_at, f.id) < (sqlc.arg(cursor_created_at)::timestamp, sqlc.arg(cursor_id)::bigint)
+    AND u.deleted_at IS NULL AND u.deactivated_at IS NULL
+ORDER BY f.created_at DESC, f.id DESC
+LIMIT sqlc.arg(page_limit);
@@ -1,12 +1,35 @@
 -- name: GetAllInterests :many
-SELECT * FROM interests;
+SELECT * FROM interests
+WHERE deleted_at IS NULL;
 
 -- name: GetExistingInterestIds :many
 SELECT id FROM interests
-WHERE id = ANY($1::bigint[]);
+WHERE id = ANY($1::bigint[]) AND deleted_at IS NULL;
 
 -- name: GetInterestsForUser :many
 SELECT interests.* FROM interests
 INNER JOIN users_has_interests 
 ON interests.id = users_has_interests.interest_id
-WHERE users_has_interests.user_id = $1;
\ No newline at end of file
+WHERE users_has_interests.user_id = $1 AND interests.deleted_at IS NULL;
+
+-- name: GetInterestById :one
+SELECT * FROM interests
+WHERE id = $1 AND deleted_at IS NULL;
+
+-- name: CreateInterest :one
+INSERT INTO interests(name, created_at, updated_at)
+VALUES(
+    $1,
+    NOW(),
+    NOW()
+)
+RETURNING *;
+
+-- name: RenameInterest :one
+UPDATE interests SET name = $2, updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING *;
+
+-- name: SoftDeleteInterest :execrows
+UPDATE interests SET deleted_at = NOW(), updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL;
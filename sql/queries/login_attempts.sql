@@ -0,0 +1,39 @@
+-- name: CreateLoginAttempt :exec
+INSERT INTO login_attempts(email, ip_address, succeeded, created_at)
+VALUES(
+    $1,
+    $2,
+    $3,
+    $4
+);
+
+-- name: GetLoginFailuresForEmail :one
+SELECT COUNT(*) AS failed_attempts, MAX(created_at)::timestamp AS last_failed_at
+FROM login_attempts
+WHERE
+    email = $1 AND succeeded = FALSE AND created_at > $2
+    AND created_at > COALESCE(
+        (SELECT MAX(la.created_at) FROM login_attempts la WHERE la.email = $1 AND la.succeeded = TRUE),
+        $2
+    );
+
+-- name: GetLoginFailuresForIp :one
+SELECT COUNT(*) AS failed_attempts, MAX(created_at)::timestamp AS last_failed_at
+FROM login_attempts
+WHERE
+    ip_address = $1 AND succeeded = FALSE AND created_at > $2;
+
+-- name: DeleteOldLoginAttempts :exec
+DELETE FROM login_attempts WHERE created_at < $1;
+
+-- name: CreateAccountLockout :exec
+INSERT INTO account_lockouts(user_id, email, ip_address, reason, failed_attempts, locked_until, created_at)
+VALUES(
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7
+);
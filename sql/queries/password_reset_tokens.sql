@@ -0,0 +1,27 @@
+-- name: CreatePasswordResetToken :one
+INSERT INTO password_reset_tokens(user_id, token_hash, expires_at, created_at)
+VALUES(
+    $1,
+    $2,
+    $3,
+    NOW()
+)
+RETURNING *;
+
+-- name: GetPasswordResetTokenByHash :one
+SELECT * FROM password_reset_tokens
+WHERE token_hash = $1;
+
+-- name: MarkPasswordResetTokenUsed :execrows
+UPDATE password_reset_tokens
+SET
+    used_at = NOW()
+WHERE
+    id = $1 AND used_at IS NULL AND expires_at > NOW();
+
+-- name: InvalidatePasswordResetTokensForUser :exec
+UPDATE password_reset_tokens
+SET
+    used_at = NOW()
+WHERE
+    user_id = $1 AND used_at IS NULL;
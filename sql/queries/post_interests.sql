@@ -0,0 +1,17 @@
+-- name: CreatePostInterests :copyfrom
+INSERT INTO post_interests(post_id, interest_id, inferred, created_at)
+VALUES($1, $2, $3, $4);
+
+-- name: GetInterestIdsForPosts :many
+SELECT post_id, interest_id FROM post_interests
+WHERE post_id = ANY($1::bigint[]);
+
+-- name: MergePostInterests :exec
+INSERT INTO post_interests(post_id, interest_id, inferred, created_at)
+SELECT post_id, sqlc.arg(into_id), inferred, created_at
+FROM post_interests
+WHERE interest_id = sqlc.arg(from_id)
+ON CONFLICT (post_id, interest_id) DO NOTHING;
+
+-- name: DeletePostInterestsForInterest :exec
+DELETE FROM post_interests WHERE interest_id = $1;
@@ -1,10 +1,54 @@
 -- name: CreatePostMedia :one
-INSERT INTO post_media(media_url, order_index, created_at, updated_at, post_id)
+INSERT INTO post_media(media_url, order_index, width, height, media_type, duration_ms, poster_url, thumbnail_url, feed_url, created_at, updated_at, post_id)
 VALUES(
     $1,
     $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7,
+    $8,
+    $9,
     NOW(),
     NOW(),
-    $3
+    $10
 )
-RETURNING *;
\ No newline at end of file
+RETURNING *;
+
+-- name: GetMediaForPosts :many
+SELECT * FROM post_media
+WHERE post_id = ANY($1::bigint[]) AND deleted_at IS NULL
+ORDER BY post_id, order_index, id;
+
+-- name: GetMediaUrlsForUser :many
+SELECT pm.media_url FROM post_media pm
+INNER JOIN posts p ON pm.post_id = p.id
+WHERE p.user_id = $1
+UNION ALL
+SELECT pm.poster_url FROM post_media pm
+INNER JOIN posts p ON pm.post_id = p.id
+WHERE p.user_id = $1 AND pm.poster_url IS NOT NULL
+UNION ALL
+SELECT pm.thumbnail_url FROM post_media pm
+INNER JOIN posts p ON pm.post_id = p.id
+WHERE p.user_id = $1 AND pm.thumbnail_url IS NOT NULL
+UNION ALL
+SELECT pm.feed_url FROM post_media pm
+INNER JOIN posts p ON pm.post_id = p.id
+WHERE p.user_id = $1 AND pm.feed_url IS NOT NULL;
+
+-- name: SoftDeletePostMedia :exec
+UPDATE post_media
+SET deleted_at = NOW(), updated_at = NOW()
+WHERE post_id = $1 AND deleted_at IS NULL;
+
+-- name: GetDeletedPostMedia :many
+SELECT id, media_url, poster_url, thumbnail_url, feed_url FROM post_media
+WHERE deleted_at < $1
+ORDER BY deleted_at ASC
+LIMIT $2;
+
+-- name: DeletePostMedia :exec
+DELETE FROM post_media
+WHERE id = $1;
@@ -0,0 +1,12 @@
+-- name: CreatePostRevision :exec
+INSERT INTO post_revisions(post_id, content, created_at)
+VALUES(
+    $1,
+    $2,
+    $3
+);
+
+-- name: GetPostRevisions :many
+SELECT * FROM post_revisions
+WHERE post_id = $1
+ORDER BY created_at DESC, id DESC;
@@ -11,96 +11,84 @@ RETURNING *;
 -- name: DeleteAllPosts :exec
 DELETE FROM posts;
 
--- name: GetPostsCount :one
-SELECT COUNT(*) FROM posts WHERE deleted_at is NULL;
+-- name: GetPostForUpdate :one
+SELECT * FROM posts
+WHERE id = $1 AND deleted_at IS NULL
+FOR UPDATE;
+
+-- name: EditPost :one
+UPDATE posts
+SET content = $2, updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: SoftDeletePost :exec
+UPDATE posts
+SET deleted_at = NOW()
+WHERE id = $1;
 
 -- name: GetAllPosts :many
-SELECT
-    p.id,
-    p.content,
-    p.created_at,
-    p.updated_at,
-    p.user_id,
-    u.id AS author_id, 
-    u.email AS author_email, 
-    u.user_name AS author_user_name,
-    u.full_name AS author_full_name,
-    u.profile_image_url AS author_profile_image_url,
-    u.dob AS author_dob,
-    u.created_at AS author_created_at, 
-    u.updated_at AS author_updated_at, 
-    (SELECT COUNT(*) FROM post_likes pl WHERE pl.post_id = p.id) AS like_count,
-    (SELECT COUNT(*) FROM comments c WHERE c.post_id = p.id) AS comment_count,
-    (
-        SELECT EXISTS(
-            SELECT 1 FROM post_likes upl WHERE upl.post_id = p.id AND upl.user_id = $1
-        )
-    ) AS liked_by_user,
-    CAST(COALESCE(ARRAY_AGG(pm.media_url ORDER BY pm.id) FILTER (WHERE pm.media_url IS NOT NULL), '{}'::text[]) AS text[]) AS media_urls_array
+SELECT * FROM visible_posts
+WHERE viewer_id = $1
+ORDER BY created_at DESC
+LIMIT $2 OFFSET $3;
 
-FROM posts p
-INNER JOIN users u ON p.user_id = u.id
-LEFT JOIN post_media pm ON p.id = pm.post_id
-WHERE p.deleted_at IS NULL 
-GROUP BY
-    p.id,               
-    p.content,
-    p.created_at,
-    p.updated_at,
-    p.user_id,
-    u.id,               
-    u.email,
-    u.user_name,
-    u.full_name,
-    u.profile_image_url,
-    u.dob,
-    u.created_at,
-    u.updated_at
-ORDER BY p.created_at DESC
-LIMIT $2 OFFSET $3; 
+-- name: GetForYouCandidates :many
+SELECT * FROM visible_posts
+WHERE viewer_id = sqlc.arg(viewer_id) AND created_at > sqlc.arg(since)::timestamp
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg(candidate_limit);
 
--- name: GetPostById :one
-SELECT
-    p.id,
-    p.content,
-    p.created_at,
-    p.updated_at,
-    p.user_id,
-    u.id AS author_id, 
-    u.email AS author_email, 
-    u.user_name AS author_user_name,
-    u.full_name AS author_full_name,
-    u.profile_image_url AS author_profile_image_url,
-    u.dob AS author_dob,
-    u.created_at AS author_created_at, 
-    u.updated_at AS author_updated_at, 
-    (SELECT COUNT(*) FROM post_likes pl WHERE pl.post_id = p.id) AS like_count,
-    (SELECT COUNT(*) FROM comments c WHERE c.post_id = p.id) AS comment_count,
-    (
-        SELECT EXISTS(
-            SELECT 1 FROM post_likes upl WHERE upl.post_id = p.id AND upl.user_id = $1
-        )
-    ) AS liked_by_user,
-    CAST(COALESCE(ARRAY_AGG(pm.media_url ORDER BY pm.id) FILTER (WHERE pm.media_url IS NOT NULL), '{}'::text[]) AS text[]) AS media_urls_array
+-- name: GetHomeFeed :many
+SELECT * FROM visible_posts
+WHERE viewer_id = $1 AND user_id IN (
+    SELECT hf.followee_id FROM follows hf WHERE hf.follower_id = $1
+    UNION ALL
+    SELECT $1
+)
+ORDER BY created_at DESC, id DESC
+LIMIT $2 OFFSET $3;
+
+-- name: GetHomeFeedAfter :many
+SELECT * FROM visible_posts
+WHERE viewer_id = sqlc.arg(viewer_id) AND user_id IN (
+    SELECT hf.followee_id FROM follows hf WHERE hf.follower_id = sqlc.arg(viewer_id)
+    UNION ALL
+    SELECT sqlc.arg(viewer_id)
+) AND (created_at, id) > (sqlc.arg(cursor_created_at)::timestamp, sqlc.arg(cursor_id)::bigint)
+ORDER BY created_at ASC, id ASC
+LIMIT sqlc.arg(page_limit);
 
-FROM posts p
-INNER JOIN users u ON p.user_id = u.id
-LEFT JOIN post_media pm ON p.id = pm.post_id
-WHERE p.id = $2 AND p.deleted_at IS NULL 
-GROUP BY
-    p.id,               
-    p.content,
-    p.created_at,
-    p.updated_at,
-    p.user_id,
-    u.id,               
-    u.email,
-    u.user_name,
-    u.full_name,
-    u.profile_image_url,
-    u.dob,
-    u.created_at,
-    u.updated_at;
+-- name: GetHomeFeedBefore :many
+SELECT * FROM visible_posts
+WHERE viewer_id = sqlc.arg(viewer_id) AND user_id IN (
+    SELECT hf.followee_id FROM follows hf WHERE hf.follower_id = sqlc.arg(viewer_id)
+    UNION ALL
+    SELECT sqlc.arg(viewer_id)
+) AND (created_at, id) < (sqlc.arg(cursor_created_at)::timestamp, sqlc.arg(cursor_id)::bigint)
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg(page_limit);
 
+-- name: GetPostsAfter :many
+SELECT * FROM visible_posts
+WHERE viewer_id = sqlc.arg(viewer_id) AND (created_at, id) > (sqlc.arg(cursor_created_at)::timestamp, sqlc.arg(cursor_id)::bigint)
+ORDER BY created_at ASC, id ASC
+LIMIT sqlc.arg(page_limit);
 
+-- name: GetPostsBefore :many
+SELECT * FROM visible_posts
+WHERE viewer_id = sqlc.arg(viewer_id) AND (created_at, id) < (sqlc.arg(cursor_created_at)::timestamp, sqlc.arg(cursor_id)::bigint)
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg(page_limit);
+
+-- name: GetPostById :one
+SELECT * FROM visible_posts
+WHERE viewer_id = $1 AND id = $2;
 
+-- name: IsPostVisible :one
+SELECT EXISTS(
+    SELECT 1 FROM posts p
+    INNER JOIN users u ON p.user_id = u.id
+    WHERE p.id = sqlc.arg(post_id) AND p.deleted_at IS NULL AND u.deleted_at IS NULL AND u.deactivated_at IS NULL
+        AND (u.is_private = FALSE OR u.id = sqlc.arg(viewer_id) OR EXISTS(SELECT 1 FROM follows pf WHERE pf.follower_id = sqlc.arg(viewer_id) AND pf.followee_id = u.id))
+) AS visible;
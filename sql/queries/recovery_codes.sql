@@ -0,0 +1,13 @@
+-- name: CreateRecoveryCodes :copyfrom
+INSERT INTO recovery_codes(user_id, code_hash, created_at)
+VALUES($1, $2, $3);
+
+-- name: DeleteRecoveryCodesForUser :exec
+DELETE FROM recovery_codes WHERE user_id = $1;
+
+-- name: UseRecoveryCode :execrows
+UPDATE recovery_codes
+SET
+    used_at = NOW()
+WHERE
+    user_id = $1 AND code_hash = $2 AND used_at IS NULL;
@@ -0,0 +1,17 @@
+-- name: CreateRevokedToken :exec
+INSERT INTO revoked_tokens(jti, user_id, expires_at, created_at)
+VALUES(
+    $1,
+    $2,
+    $3,
+    NOW()
+)
+ON CONFLICT (jti) DO NOTHING;
+
+-- name: IsTokenRevoked :one
+SELECT EXISTS(
+    SELECT 1 FROM revoked_tokens WHERE jti = $1
+);
+
+-- name: DeleteExpiredRevokedTokens :exec
+DELETE FROM revoked_tokens WHERE expires_at < NOW();
@@ -0,0 +1,39 @@
+-- name: CreateSession :one
+INSERT INTO sessions(user_id, family_id, token_hash, expires_at, created_at, updated_at)
+VALUES(
+    $1,
+    $2,
+    $3,
+    $4,
+    NOW(),
+    NOW()
+)
+RETURNING *;
+
+-- name: GetSessionByTokenHash :one
+SELECT * FROM sessions
+WHERE token_hash = $1;
+
+-- name: MarkSessionUsed :execrows
+UPDATE sessions
+SET
+    used_at = NOW(),
+    updated_at = NOW()
+WHERE
+    id = $1 AND used_at IS NULL AND revoked_at IS NULL;
+
+-- name: RevokeSessionFamily :exec
+UPDATE sessions
+SET
+    revoked_at = NOW(),
+    updated_at = NOW()
+WHERE
+    family_id = $1 AND revoked_at IS NULL;
+
+-- name: RevokeAllSessionsForUser :exec
+UPDATE sessions
+SET
+    revoked_at = NOW(),
+    updated_at = NOW()
+WHERE
+    user_id = $1 AND revoked_at IS NULL;
@@ -0,0 +1,46 @@
+-- name: CreateUserBlock :execrows
+INSERT INTO user_blocks(blocker_id, blocked_id, created_at)
+VALUES(
+    $1,
+    $2,
+    NOW()
+)
+ON CONFLICT (blocker_id, blocked_id) DO NOTHING;
+
+-- name: DeleteUserBlock :execrows
+DELETE FROM user_blocks WHERE blocker_id = $1 AND blocked_id = $2;
+
+-- name: DeleteFollowsBetween :exec
+DELETE FROM follows
+WHERE (follower_id = sqlc.arg(user_id) AND followee_id = sqlc.arg(other_user_id))
+    OR (follower_id = sqlc.arg(other_user_id) AND followee_id = sqlc.arg(user_id));
+
+-- name: IsBlockedBetween :one
+SELECT EXISTS(
+    SELECT 1 FROM user_blocks
+    WHERE (blocker_id = sqlc.arg(user_id) AND blocked_id = sqlc.arg(other_user_id))
+        OR (blocker_id = sqlc.arg(other_user_id) AND blocked_id = sqlc.arg(user_id))
+) AS blocked;
+
+-- name: IsBlockedByPostAuthor :one
+SELECT EXISTS(
+    SELECT 1 FROM posts p
+    INNER JOIN user_blocks ub ON ub.blocker_id = p.user_id
+    WHERE p.id = sqlc.arg(post_id) AND ub.blocked_id = sqlc.arg(user_id)
+) AS blocked;
+
+-- name: GetBlockedUsers :many
+SELECT
+    u.id,
+    u.user_name,
+    u.full_name,
+    u.profile_image_url,
+    u.profile_image_feed_url,
+    u.profile_image_thumbnail_url,
+    u.created_at,
+    ub.created_at AS blocked_at
+FROM user_blocks ub
+INNER JOIN users u ON ub.blocked_id = u.id
+WHERE ub.blocker_id = sqlc.arg(user_id) AND u.deleted_at IS NULL AND u.deactivated_at IS NULL
+ORDER BY ub.created_at DESC, ub.id DESC
+LIMIT sqlc.arg(page_limit) OFFSET sqlc.arg(page_offset);
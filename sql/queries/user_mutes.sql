@@ -0,0 +1,27 @@
+-- name: CreateUserMute :execrows
+INSERT INTO user_mutes(muter_id, muted_id, created_at)
+VALUES(
+    $1,
+    $2,
+    NOW()
+)
+ON CONFLICT (muter_id, muted_id) DO NOTHING;
+
+-- name: DeleteUserMute :execrows
+DELETE FROM user_mutes WHERE muter_id = $1 AND muted_id = $2;
+
+-- name: GetMutedUsers :many
+SELECT
+    u.id,
+    u.user_name,
+    u.full_name,
+    u.profile_image_url,
+    u.profile_image_feed_url,
+    u.profile_image_thumbnail_url,
+    u.created_at,
+    um.created_at AS muted_at
+FROM user_mutes um
+INNER JOIN users u ON um.muted_id = u.id
+WHERE um.muter_id = sqlc.arg(user_id) AND u.deleted_at IS NULL AND u.deactivated_at IS NULL
+ORDER BY um.created_at DESC, um.id DESC
+LIMIT sqlc.arg(page_limit) OFFSET sqlc.arg(page_offset);
@@ -0,0 +1,16 @@
+-- name: CreateUserNameHistory :exec
+INSERT INTO username_history(user_id, user_name, changed_at, reserved_until)
+VALUES(
+    $1,
+    $2,
+    NOW(),
+    $3
+);
+
+-- name: GetUserIdForReservedUserName :one
+SELECT h.user_id FROM username_history h
+INNER JOIN users u ON h.user_id = u.id
+WHERE LOWER(h.user_name) = LOWER($1) AND h.reserved_until > NOW()
+    AND u.deleted_at IS NULL AND u.deactivated_at IS NULL
+ORDER BY h.changed_at DESC
+LIMIT 1;
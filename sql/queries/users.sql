@@ -12,11 +12,11 @@ RETURNING *;
 
 -- name: GetUserById :one
 SELECT * FROM users
-WHERE id = $1;
+WHERE id = $1 AND deleted_at IS NULL AND deactivated_at IS NULL;
 
 -- name: GetUserByEmail :one
 SELECT * FROM users
-WHERE email = $1;
+WHERE email = $1 AND deleted_at IS NULL AND deactivated_at IS NULL;
 
 -- name: DeleteAllUsers :exec
 DELETE FROM users;
@@ -24,11 +24,197 @@ DELETE FROM users;
 -- name: UpdateUserProfile :one
 UPDATE users
 SET
-    full_name = $2,
-    user_name = $3,
-    profile_image_url = $4,
-    dob = $5,
+    full_name = COALESCE(sqlc.narg(full_name), full_name),
+    dob = CASE WHEN sqlc.arg(clear_dob)::boolean THEN NULL ELSE COALESCE(sqlc.narg(dob), dob) END,
+    updated_at = NOW()
+WHERE
+    id = sqlc.arg(id) AND deleted_at IS NULL AND deactivated_at IS NULL
+RETURNING *;
+
+-- name: SetUserProfileImage :one
+UPDATE users
+SET
+    profile_image_url = $2,
+    profile_image_thumbnail_url = $3,
+    profile_image_feed_url = $4,
+    updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL AND deactivated_at IS NULL
+RETURNING *;
+
+-- name: GetUserTokenVersion :one
+SELECT token_version FROM users
+WHERE id = $1 AND deleted_at IS NULL AND deactivated_at IS NULL;
+
+-- name: IncrementUserTokenVersion :one
+UPDATE users
+SET
+    token_version = token_version + 1,
     updated_at = NOW()
 WHERE
     id = $1
-RETURNING *;
\ No newline at end of file
+RETURNING token_version;
+
+-- name: UpdateUserPassword :exec
+UPDATE users
+SET
+    hashed_password = $2,
+    updated_at = NOW()
+WHERE
+    id = $1;
+
+-- name: MarkUserEmailVerified :execrows
+UPDATE users
+SET
+    email_verified_at = NOW(),
+    updated_at = NOW()
+WHERE
+    id = $1 AND email = $2;
+
+-- name: UpdateUserVerificationSentAt :execrows
+UPDATE users
+SET
+    verification_sent_at = NOW()
+WHERE
+    id = $1 AND (verification_sent_at IS NULL OR verification_sent_at < $2);
+
+-- name: SetUserTotpSecret :exec
+UPDATE users
+SET
+    totp_secret = $2,
+    totp_enabled_at = NULL,
+    totp_last_step = NULL,
+    updated_at = NOW()
+WHERE
+    id = $1;
+
+-- name: EnableUserTotp :exec
+UPDATE users
+SET
+    totp_enabled_at = NOW(),
+    updated_at = NOW()
+WHERE
+    id = $1;
+
+-- name: DisableUserTotp :exec
+UPDATE users
+SET
+    totp_secret = NULL,
+    totp_enabled_at = NULL,
+    totp_last_step = NULL,
+    updated_at = NOW()
+WHERE
+    id = $1;
+
+-- name: UpdateUserTotpLastStep :execrows
+UPDATE users
+SET
+    totp_last_step = $2
+WHERE
+    id = $1 AND (totp_last_step IS NULL OR totp_last_step < $2);
+
+-- name: UpdateUserEmail :one
+UPDATE users
+SET
+    email = $2,
+    email_verified_at = NOW(),
+    updated_at = NOW()
+WHERE
+    id = $1
+RETURNING *;
+
+-- name: GetUserByEmailIncludingInactive :one
+SELECT * FROM users
+WHERE email = $1;
+
+-- name: GetUserByIdIncludingInactive :one
+SELECT * FROM users
+WHERE id = $1;
+
+-- name: SoftDeleteUser :exec
+UPDATE users
+SET
+    deleted_at = NOW(),
+    updated_at = NOW()
+WHERE
+    id = $1 AND deleted_at IS NULL;
+
+-- name: DeactivateUser :exec
+UPDATE users
+SET
+    deactivated_at = NOW(),
+    updated_at = NOW()
+WHERE
+    id = $1 AND deactivated_at IS NULL;
+
+-- name: RestoreUser :one
+UPDATE users
+SET
+    deleted_at = NULL,
+    deactivated_at = NULL,
+    updated_at = NOW()
+WHERE
+    id = $1
+RETURNING *;
+
+-- name: GetUsersToPurge :many
+SELECT id, profile_image_url, profile_image_thumbnail_url, profile_image_feed_url FROM users
+WHERE deleted_at < $1
+ORDER BY deleted_at ASC
+LIMIT $2;
+
+-- name: PurgeUser :execrows
+DELETE FROM users
+WHERE id = $1 AND deleted_at < $2;
+
+-- name: GetUserProfileStats :one
+SELECT
+    (
+        SELECT COUNT(*) FROM posts p
+        WHERE p.user_id = $1 AND p.deleted_at IS NULL
+    ) AS post_count,
+    (
+        SELECT COUNT(*) FROM post_likes pl
+        INNER JOIN posts lp ON pl.post_id = lp.id
+        INNER JOIN users lu ON pl.user_id = lu.id
+        WHERE lp.user_id = $1 AND lp.deleted_at IS NULL AND lu.deleted_at IS NULL AND lu.deactivated_at IS NULL
+    ) AS like_count,
+    (
+        SELECT COUNT(*) FROM follows f
+        INNER JOIN users fu ON f.follower_id = fu.id
+        WHERE f.followee_id = $1 AND fu.deleted_at IS NULL AND fu.deactivated_at IS NULL
+    ) AS follower_count,
+    (
+        SELECT COUNT(*) FROM follows f
+        INNER JOIN users fu ON f.followee_id = fu.id
+        WHERE f.follower_id = $1 AND fu.deleted_at IS NULL AND fu.deactivated_at IS NULL
+    ) AS following_count,
+    (
+        SELECT COUNT(*) FROM follow_requests fr
+        INNER JOIN users ru ON fr.requester_id = ru.id
+        WHERE fr.target_id = $1 AND ru.deleted_at IS NULL AND ru.deactivated_at IS NULL
+    ) AS pending_request_count;
+
+-- name: SetUserPrivate :one
+UPDATE users SET is_private = $2, updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL AND deactivated_at IS NULL
+RETURNING *;
+
+-- name: GetUserByUserName :one
+SELECT * FROM users
+WHERE LOWER(user_name) = LOWER($1) AND deleted_at IS NULL AND deactivated_at IS NULL;
+
+-- name: IsUserNameTaken :one
+SELECT (
+    EXISTS(
+        SELECT 1 FROM users
+        WHERE LOWER(user_name) = LOWER(sqlc.arg(user_name)) AND id <> sqlc.arg(user_id)
+    ) OR EXISTS(
+        SELECT 1 FROM username_history
+        WHERE LOWER(user_name) = LOWER(sqlc.arg(user_name)) AND user_id <> sqlc.arg(user_id) AND reserved_until > NOW()
+    )
+)::boolean AS taken;
+
+-- name: ChangeUserName :one
+UPDATE users SET user_name = $2, user_name_changed_at = NOW(), updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL AND deactivated_at IS NULL
+RETURNING *;
@@ -8,4 +8,20 @@ FROM users_has_interests
 WHERE user_id = $1 AND interest_id = ANY($2::bigint[]);
 
 -- name: DeleteAllUsersHasInterests :exec
-DELETE FROM users_has_interests;
\ No newline at end of file
+DELETE FROM users_has_interests;
+
+-- name: DeleteInterestsForUser :exec
+DELETE FROM users_has_interests WHERE user_id = $1;
+
+-- name: DeleteInterestForUser :execrows
+DELETE FROM users_has_interests WHERE user_id = $1 AND interest_id = $2;
+
+-- name: MergeUsersHasInterests :exec
+INSERT INTO users_has_interests(user_id, interest_id, created_at, updated_at)
+SELECT user_id, sqlc.arg(into_id), created_at, NOW()
+FROM users_has_interests
+WHERE interest_id = sqlc.arg(from_id)
+ON CONFLICT (user_id, interest_id) DO NOTHING;
+
+-- name: DeleteUsersHasInterestsForInterest :exec
+DELETE FROM users_has_interests WHERE interest_id = $1;
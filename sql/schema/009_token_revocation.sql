@@ -0,0 +1,14 @@
+-- +goose Up
+ALTER TABLE users ADD COLUMN token_version INT NOT NULL DEFAULT 0;
+
+CREATE TABLE revoked_tokens(
+    id BIGSERIAL PRIMARY KEY,
+    jti TEXT NOT NULL UNIQUE,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL
+);
+
+-- +goose Down
+DROP TABLE revoked_tokens;
+ALTER TABLE users DROP COLUMN token_version;
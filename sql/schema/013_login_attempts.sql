@@ -0,0 +1,29 @@
+-- +goose Up
+CREATE TABLE login_attempts(
+    id BIGSERIAL PRIMARY KEY,
+    email TEXT NOT NULL,
+    ip_address TEXT NOT NULL,
+    succeeded BOOLEAN NOT NULL,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX login_attempts_email_created_at_idx ON login_attempts(email, created_at);
+CREATE INDEX login_attempts_ip_address_created_at_idx ON login_attempts(ip_address, created_at);
+CREATE INDEX login_attempts_created_at_idx ON login_attempts(created_at);
+
+CREATE TABLE account_lockouts(
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    email TEXT NOT NULL,
+    ip_address TEXT NOT NULL,
+    reason TEXT NOT NULL,
+    failed_attempts BIGINT NOT NULL,
+    locked_until TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX account_lockouts_email_idx ON account_lockouts(email);
+
+-- +goose Down
+DROP TABLE account_lockouts;
+DROP TABLE login_attempts;
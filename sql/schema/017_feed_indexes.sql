@@ -0,0 +1,16 @@
+-- +goose Up
+-- The home feed reads the newest posts of each followed user
+CREATE INDEX posts_user_id_created_at_idx ON posts(user_id, created_at DESC) WHERE deleted_at IS NULL;
+CREATE INDEX posts_created_at_idx ON posts(created_at DESC) WHERE deleted_at IS NULL;
+
+-- Like, comment and media lookups for every post in a feed page
+CREATE INDEX post_likes_post_id_idx ON post_likes(post_id);
+CREATE INDEX comments_post_id_idx ON comments(post_id);
+CREATE INDEX post_media_post_id_idx ON post_media(post_id);
+
+-- +goose Down
+DROP INDEX post_media_post_id_idx;
+DROP INDEX comments_post_id_idx;
+DROP INDEX post_likes_post_id_idx;
+DROP INDEX posts_created_at_idx;
+DROP INDEX posts_user_id_created_at_idx;
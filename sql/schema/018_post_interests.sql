@@ -0,0 +1,13 @@
+-- +goose Up
+CREATE TABLE post_interests(
+    post_id BIGINT NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    interest_id BIGINT NOT NULL REFERENCES interests(id) ON DELETE CASCADE,
+    inferred BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY(post_id, interest_id)
+);
+
+CREATE INDEX post_interests_interest_id_idx ON post_interests(interest_id);
+
+-- +goose Down
+DROP TABLE post_interests;
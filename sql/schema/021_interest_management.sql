@@ -0,0 +1,10 @@
+-- +goose Up
+-- Admins are granted by hand, e.g. UPDATE users SET is_admin = TRUE WHERE email = '...';
+ALTER TABLE users ADD COLUMN is_admin BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Interest names are unique among the interests that are not deleted, ignoring case
+CREATE UNIQUE INDEX interests_name_unique_idx ON interests(LOWER(name)) WHERE deleted_at IS NULL;
+
+-- +goose Down
+DROP INDEX interests_name_unique_idx;
+ALTER TABLE users DROP COLUMN is_admin;
@@ -0,0 +1,50 @@
+-- +goose Up
+-- Users registered without a user name, or with one that breaks the rules, is reserved or is taken, get a generated one
+-- like the ones given at registration. A kept user name can look like a generated one,
+-- so generated names that are taken are drawn again until none is.
+-- +goose StatementBegin
+DO $$
+BEGIN
+    CREATE TEMP TABLE users_to_rename AS
+    SELECT id FROM users
+    WHERE user_name !~ '^[A-Za-z0-9][A-Za-z0-9_.]{2,29}$'
+        OR user_name ~ '\.\.|\.$'
+        OR LOWER(user_name) IN (
+            'about', 'admin', 'administrator', 'api', 'feed', 'help', 'login', 'logout', 'me', 'moderator',
+            'null', 'register', 'root', 'sanctuary', 'settings', 'staff', 'support', 'system', 'undefined'
+        )
+        OR id <> (SELECT MIN(o.id) FROM users o WHERE LOWER(o.user_name) = LOWER(users.user_name));
+
+    UPDATE users SET user_name = 'user_' || SUBSTR(MD5(RANDOM()::TEXT || id), 1, 10)
+    WHERE id IN (SELECT id FROM users_to_rename);
+
+    LOOP
+        UPDATE users SET user_name = 'user_' || SUBSTR(MD5(RANDOM()::TEXT || id), 1, 10)
+        WHERE id IN (SELECT id FROM users_to_rename)
+            AND EXISTS(SELECT 1 FROM users o WHERE LOWER(o.user_name) = LOWER(users.user_name) AND o.id <> users.id);
+        EXIT WHEN NOT FOUND;
+    END LOOP;
+
+    DROP TABLE users_to_rename;
+END $$;
+-- +goose StatementEnd
+
+CREATE UNIQUE INDEX users_user_name_unique_idx ON users(LOWER(user_name));
+
+ALTER TABLE users ADD COLUMN user_name_changed_at TIMESTAMP;
+
+-- Old user names stay reserved for their owner until reserved_until
+CREATE TABLE username_history(
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    user_name TEXT NOT NULL,
+    changed_at TIMESTAMP NOT NULL,
+    reserved_until TIMESTAMP NOT NULL
+);
+
+CREATE INDEX username_history_user_name_idx ON username_history(LOWER(user_name));
+
+-- +goose Down
+DROP TABLE username_history;
+ALTER TABLE users DROP COLUMN user_name_changed_at;
+DROP INDEX users_user_name_unique_idx;
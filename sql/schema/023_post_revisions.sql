@@ -0,0 +1,17 @@
+-- +goose Up
+-- Earlier versions of edited posts. created_at is when that version was written.
+CREATE TABLE post_revisions(
+    id BIGSERIAL PRIMARY KEY,
+    post_id BIGINT NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    content TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX post_revisions_post_id_idx ON post_revisions(post_id, created_at DESC);
+
+-- Media of deleted posts waits here until it is removed from S3
+CREATE INDEX post_media_deleted_at_idx ON post_media(deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- +goose Down
+DROP INDEX post_media_deleted_at_idx;
+DROP TABLE post_revisions;
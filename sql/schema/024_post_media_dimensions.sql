@@ -0,0 +1,12 @@
+-- +goose Up
+-- Unknown for media uploaded before dimensions were recorded
+ALTER TABLE post_media ADD COLUMN width INT;
+ALTER TABLE post_media ADD COLUMN height INT;
+
+-- Media of a post is read in order
+CREATE INDEX post_media_post_id_order_index_idx ON post_media(post_id, order_index);
+
+-- +goose Down
+DROP INDEX post_media_post_id_order_index_idx;
+ALTER TABLE post_media DROP COLUMN height;
+ALTER TABLE post_media DROP COLUMN width;
@@ -0,0 +1,12 @@
+-- +goose Up
+-- Everything uploaded before videos and gifs were allowed is an image
+ALTER TABLE post_media ADD COLUMN media_type TEXT NOT NULL DEFAULT 'image'
+    CHECK (media_type IN ('image', 'gif', 'video'));
+ALTER TABLE post_media ADD COLUMN duration_ms INT;
+-- First frame of animated gifs
+ALTER TABLE post_media ADD COLUMN poster_url TEXT;
+
+-- +goose Down
+ALTER TABLE post_media DROP COLUMN poster_url;
+ALTER TABLE post_media DROP COLUMN duration_ms;
+ALTER TABLE post_media DROP COLUMN media_type;
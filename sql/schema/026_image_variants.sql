@@ -0,0 +1,13 @@
+-- +goose Up
+-- Smaller renditions of uploaded images. media_url and profile_image_url are the full rendition.
+-- Null for videos, animated gifs and images uploaded before they were made.
+ALTER TABLE post_media ADD COLUMN thumbnail_url TEXT;
+ALTER TABLE post_media ADD COLUMN feed_url TEXT;
+ALTER TABLE users ADD COLUMN profile_image_thumbnail_url TEXT;
+ALTER TABLE users ADD COLUMN profile_image_feed_url TEXT;
+
+-- +goose Down
+ALTER TABLE users DROP COLUMN profile_image_feed_url;
+ALTER TABLE users DROP COLUMN profile_image_thumbnail_url;
+ALTER TABLE post_media DROP COLUMN feed_url;
+ALTER TABLE post_media DROP COLUMN thumbnail_url;
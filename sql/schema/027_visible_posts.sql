@@ -0,0 +1,54 @@
+-- +goose Up
+-- Posts as a viewer sees them, with one row per post and viewer it is visible to.
+-- Always filter by viewer_id. Every post list and detail query selects from here, so the visibility rules live in one place:
+-- deleted posts and authors, authors the viewer blocked or muted and private authors the viewer does not follow are left out.
+CREATE VIEW visible_posts AS
+SELECT
+    p.id,
+    p.content,
+    p.created_at,
+    p.updated_at,
+    p.user_id,
+    u.id AS author_id,
+    u.user_name AS author_user_name,
+    u.full_name AS author_full_name,
+    u.profile_image_url AS author_profile_image_url,
+    u.profile_image_feed_url AS author_profile_image_feed_url,
+    u.profile_image_thumbnail_url AS author_profile_image_thumbnail_url,
+    u.created_at AS author_created_at,
+    (
+        SELECT COUNT(*) FROM post_likes pl
+        INNER JOIN users lu ON pl.user_id = lu.id
+        WHERE pl.post_id = p.id AND lu.deleted_at IS NULL AND lu.deactivated_at IS NULL
+    ) AS like_count,
+    (
+        SELECT COUNT(*) FROM comments c
+        INNER JOIN users cu ON c.user_id = cu.id
+        WHERE c.post_id = p.id AND cu.deleted_at IS NULL AND cu.deactivated_at IS NULL
+    ) AS comment_count,
+    (
+        SELECT EXISTS(
+            SELECT 1 FROM post_likes upl WHERE upl.post_id = p.id AND upl.user_id = v.id
+        )
+    ) AS liked_by_user,
+    (
+        SELECT EXISTS(
+            SELECT 1 FROM follows af WHERE af.follower_id = v.id AND af.followee_id = u.id
+        )
+    ) AS author_is_following,
+    (
+        SELECT EXISTS(
+            SELECT 1 FROM follows ay WHERE ay.follower_id = u.id AND ay.followee_id = v.id
+        )
+    ) AS author_follows_you,
+    v.id AS viewer_id
+FROM posts p
+INNER JOIN users u ON p.user_id = u.id
+CROSS JOIN users v
+WHERE p.deleted_at IS NULL AND u.deleted_at IS NULL AND u.deactivated_at IS NULL
+    AND NOT EXISTS(SELECT 1 FROM user_blocks vb WHERE vb.blocker_id = v.id AND vb.blocked_id = p.user_id)
+    AND NOT EXISTS(SELECT 1 FROM user_mutes vm WHERE vm.muter_id = v.id AND vm.muted_id = p.user_id)
+    AND (u.is_private = FALSE OR u.id = v.id OR EXISTS(SELECT 1 FROM follows pf WHERE pf.follower_id = v.id AND pf.followee_id = u.id));
+
+-- +goose Down
+DROP VIEW visible_posts;
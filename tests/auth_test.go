@@ -1,10 +1,14 @@
 package tests
 
 import (
+	"context"
+	"strconv"
 	"testing"
 	"time"
 
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/app/handlers"
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/app/jwtkeys"
 )
 
 func TestHashPasswordPositive(t *testing.T) {
@@ -36,9 +40,9 @@ func TestHashPasswordNegative(t *testing.T) {
 
 func TestMakeJWT(t *testing.T) {
 	userId := int64(22)
-	tokenSecret := "rT8!pL3#vQ7@kF9$mZ2&xY5*wC1^"
+	keys := jwtkeys.NewHMACKeySet("rT8!pL3#vQ7@kF9$mZ2&xY5*wC1^")
 
-	_, err := handlers.MakeJWT(userId, tokenSecret, 1*time.Hour)
+	_, err := handlers.MakeJWT(userId, 0, keys, 1*time.Hour)
 	if err != nil {
 		t.Fatalf("error making jwt token")
 	}
@@ -46,14 +50,14 @@ func TestMakeJWT(t *testing.T) {
 
 func TestValidateJWT(t *testing.T) {
 	userId := int64(24)
-	tokenSecret := "rT8!pL3#vQ7@kF9$mZ2&xY5*wC1^"
+	keys := jwtkeys.NewHMACKeySet("rT8!pL3#vQ7@kF9$mZ2&xY5*wC1^")
 
-	signedJWTString, err := handlers.MakeJWT(userId, tokenSecret, 1*time.Hour)
+	signedJWTString, err := handlers.MakeJWT(userId, 0, keys, 1*time.Hour)
 	if err != nil {
 		t.Fatalf("error making jwt token")
 	}
 
-	validatedUserId, validateErr := handlers.ValidateJWT(signedJWTString, tokenSecret)
+	validatedUserId, validateErr := handlers.ValidateJWT(context.Background(), signedJWTString, keys, nil)
 	if validateErr != nil {
 		t.Fatalf("error validating jwt : %v", validateErr)
 	}
@@ -62,3 +66,107 @@ func TestValidateJWT(t *testing.T) {
 		t.Fatalf("user ids do not match")
 	}
 }
+
+func TestHashToken(t *testing.T) {
+	token, err := handlers.MakeRandomToken()
+	if err != nil {
+		t.Fatalf("error making random token")
+	}
+
+	if handlers.HashToken(token) != handlers.HashToken(token) {
+		t.Fatalf("hashing the same token gives different hashes")
+	}
+
+	otherToken, err := handlers.MakeRandomToken()
+	if err != nil {
+		t.Fatalf("error making random token")
+	}
+
+	if handlers.HashToken(token) == handlers.HashToken(otherToken) {
+		t.Fatalf("different tokens have the same hash")
+	}
+}
+
+type revokeEverything struct{}
+
+func (revokeEverything) IsRevoked(ctx context.Context, claims *handlers.AccessTokenClaims) (bool, error) {
+	return true, nil
+}
+
+func TestValidateJWTRevoked(t *testing.T) {
+	userId := int64(26)
+	keys := jwtkeys.NewHMACKeySet("rT8!pL3#vQ7@kF9$mZ2&xY5*wC1^")
+
+	signedJWTString, err := handlers.MakeJWT(userId, 0, keys, 1*time.Hour)
+	if err != nil {
+		t.Fatalf("error making jwt token")
+	}
+
+	if _, validateErr := handlers.ValidateJWT(context.Background(), signedJWTString, keys, revokeEverything{}); validateErr == nil {
+		t.Fatalf("revoked token passed validation")
+	}
+}
+
+func TestEmailVerificationTokenIsNotAnAccessToken(t *testing.T) {
+	userId := int64(28)
+	email := "user@sanctuary.test"
+	keys := jwtkeys.NewHMACKeySet("rT8!pL3#vQ7@kF9$mZ2&xY5*wC1^")
+
+	verificationToken, err := handlers.MakeEmailVerificationToken(userId, email, keys, 1*time.Hour)
+	if err != nil {
+		t.Fatalf("error making verification token")
+	}
+
+	validatedUserId, validatedEmail, validateErr := handlers.ValidateEmailVerificationToken(verificationToken, keys)
+	if validateErr != nil {
+		t.Fatalf("error validating verification token : %v", validateErr)
+	}
+	if validatedUserId != userId || validatedEmail != email {
+		t.Fatalf("verification token claims do not match")
+	}
+
+	if _, accessErr := handlers.ValidateJWT(context.Background(), verificationToken, keys, nil); accessErr == nil {
+		t.Fatalf("verification token was accepted as an access token")
+	}
+}
+
+func TestAccessTokenWithoutAudience(t *testing.T) {
+	userId := int64(30)
+	keys := jwtkeys.NewHMACKeySet("rT8!pL3#vQ7@kF9$mZ2&xY5*wC1^")
+
+	// Access tokens issued before audiences were added are accepted until they expire
+	legacyToken, err := keys.Sign(handlers.AccessTokenClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    "sanctuary",
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(1 * time.Hour)),
+			Subject:   strconv.FormatInt(userId, 10),
+		},
+	})
+	if err != nil {
+		t.Fatalf("error signing legacy token")
+	}
+	validatedUserId, validateErr := handlers.ValidateJWT(context.Background(), legacyToken, keys, nil)
+	if validateErr != nil {
+		t.Fatalf("error validating legacy token : %v", validateErr)
+	}
+	if validatedUserId != userId {
+		t.Fatalf("user ids do not match")
+	}
+
+	// Tokens without an audience that live longer than an access token are not access tokens
+	longLivedToken, err := keys.Sign(handlers.AccessTokenClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    "sanctuary",
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
+			Subject:   strconv.FormatInt(userId, 10),
+		},
+	})
+	if err != nil {
+		t.Fatalf("error signing long lived token")
+	}
+	if _, validateErr := handlers.ValidateJWT(context.Background(), longLivedToken, keys, nil); validateErr == nil {
+		t.Fatalf("long lived token without an audience was accepted")
+	}
+}
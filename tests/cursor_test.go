@@ -0,0 +1,114 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/app/handlers"
+)
+
+type cursorItem struct {
+	ID        int64
+	CreatedAt time.Time
+}
+
+func cursorItemKey(item cursorItem) handlers.Cursor {
+	return handlers.Cursor{CreatedAt: item.CreatedAt, ID: item.ID}
+}
+
+// Items newest first, the way the list queries return them
+func cursorItems(ids ...int64) []cursorItem {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	items := []cursorItem{}
+	for _, id := range ids {
+		items = append(items, cursorItem{ID: id, CreatedAt: start.Add(time.Duration(id) * time.Second)})
+	}
+	return items
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	cursor := handlers.Cursor{
+		CreatedAt: time.Date(2025, 3, 4, 5, 6, 7, 123456000, time.UTC),
+		ID:        42,
+		Prev:      true,
+	}
+
+	decoded, err := handlers.DecodeCursor(handlers.EncodeCursor(cursor))
+	if err != nil {
+		t.Fatalf("Error decoding cursor %v", err)
+	}
+	if !decoded.CreatedAt.Equal(cursor.CreatedAt) || decoded.ID != cursor.ID || decoded.Prev != cursor.Prev {
+		t.Fatalf("Expected %v but got %v", cursor, decoded)
+	}
+}
+
+func TestDecodeInvalidCursor(t *testing.T) {
+	for _, value := range []string{"not a cursor", "e30", "eyJpIjoxfQ"} {
+		if _, err := handlers.DecodeCursor(value); err == nil {
+			t.Fatalf("Expected an error for cursor %v", value)
+		}
+	}
+}
+
+func TestFirstCursorPage(t *testing.T) {
+	page := handlers.NewCursorPage(cursorItems(10, 9, 8, 7), 3, nil, cursorItemKey)
+
+	if len(page.Items) != 3 || page.Items[0].ID != 10 || page.Items[2].ID != 8 {
+		t.Fatalf("Unexpected items %v", page.Items)
+	}
+	if page.PrevCursor != "" {
+		t.Fatalf("The first page should not have a previous cursor")
+	}
+
+	next, err := handlers.DecodeCursor(page.NextCursor)
+	if err != nil {
+		t.Fatalf("Error decoding next cursor %v", err)
+	}
+	if next.ID != 8 || next.Prev {
+		t.Fatalf("Next cursor should point after item 8 but got %v", next)
+	}
+}
+
+func TestLastCursorPage(t *testing.T) {
+	cursor := cursorItemKey(cursorItems(8)[0])
+	page := handlers.NewCursorPage(cursorItems(7, 6), 3, &cursor, cursorItemKey)
+
+	if page.NextCursor != "" {
+		t.Fatalf("The last page should not have a next cursor")
+	}
+
+	prev, err := handlers.DecodeCursor(page.PrevCursor)
+	if err != nil {
+		t.Fatalf("Error decoding previous cursor %v", err)
+	}
+	if prev.ID != 7 || !prev.Prev {
+		t.Fatalf("Previous cursor should point before item 7 but got %v", prev)
+	}
+}
+
+func TestPrevCursorPage(t *testing.T) {
+	// Walking backwards from item 4 returns the newer items oldest first
+	cursor := cursorItemKey(cursorItems(4)[0])
+	cursor.Prev = true
+	page := handlers.NewCursorPage(cursorItems(5, 6, 7, 8), 3, &cursor, cursorItemKey)
+
+	if len(page.Items) != 3 || page.Items[0].ID != 7 || page.Items[2].ID != 5 {
+		t.Fatalf("Expected items 7, 6, 5 but got %v", page.Items)
+	}
+
+	next, nextErr := handlers.DecodeCursor(page.NextCursor)
+	if nextErr != nil || next.ID != 5 || next.Prev {
+		t.Fatalf("Next cursor should point after item 5 but got %v %v", next, nextErr)
+	}
+	prev, prevErr := handlers.DecodeCursor(page.PrevCursor)
+	if prevErr != nil || prev.ID != 7 || !prev.Prev {
+		t.Fatalf("Previous cursor should point before item 7 but got %v %v", prev, prevErr)
+	}
+}
+
+func TestEmptyCursorPage(t *testing.T) {
+	page := handlers.NewCursorPage([]cursorItem{}, 3, nil, cursorItemKey)
+	if len(page.Items) != 0 || page.NextCursor != "" || page.PrevCursor != "" {
+		t.Fatalf("Expected an empty page but got %v", page)
+	}
+}
@@ -0,0 +1,247 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"testing"
+
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/app/imaging"
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/app/media"
+)
+
+// A jpeg with the left half red and the right half blue
+func halvesJpeg(t *testing.T, width int, height int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if x < width/2 {
+				img.Set(x, y, color.RGBA{R: 255, A: 255})
+			} else {
+				img.Set(x, y, color.RGBA{B: 255, A: 255})
+			}
+		}
+	}
+	encoded := bytes.Buffer{}
+	if err := jpeg.Encode(&encoded, img, &jpeg.Options{Quality: 95}); err != nil {
+		t.Fatalf("error encoding jpeg: %v", err)
+	}
+	return encoded.Bytes()
+}
+
+// Put an EXIF segment with only the orientation right after the start of the jpeg
+func withExifOrientation(data []byte, orientation uint16, order binary.AppendByteOrder) []byte {
+	tiff := []byte("MM")
+	if order == binary.LittleEndian {
+		tiff = []byte("II")
+	}
+	tiff = order.AppendUint16(tiff, 42)
+	tiff = order.AppendUint32(tiff, 8)
+	tiff = order.AppendUint16(tiff, 1)
+	// Orientation tag, type short, count 1, value padded to 4 bytes
+	tiff = order.AppendUint16(tiff, 0x0112)
+	tiff = order.AppendUint16(tiff, 3)
+	tiff = order.AppendUint32(tiff, 1)
+	tiff = order.AppendUint16(tiff, orientation)
+	tiff = append(tiff, 0, 0)
+	tiff = order.AppendUint32(tiff, 0)
+
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+	segment := []byte{0xFF, 0xE1}
+	segment = binary.BigEndian.AppendUint16(segment, uint16(len(payload)+2))
+	segment = append(segment, payload...)
+
+	withExif := append([]byte{}, data[:2]...)
+	withExif = append(withExif, segment...)
+	return append(withExif, data[2:]...)
+}
+
+func isReddish(c color.Color) bool {
+	r, g, b, _ := c.RGBA()
+	return r > 0xC000 && g < 0x4000 && b < 0x4000
+}
+
+func isBluish(c color.Color) bool {
+	r, g, b, _ := c.RGBA()
+	return b > 0xC000 && r < 0x4000 && g < 0x4000
+}
+
+func TestProcessAppliesExifOrientation(t *testing.T) {
+	tests := []struct {
+		name        string
+		orientation uint16
+		order       binary.AppendByteOrder
+		// Where the red left half of the photo ends up
+		redOnTop bool
+	}{
+		{"turned clockwise", 6, binary.BigEndian, true},
+		{"turned counter clockwise", 8, binary.LittleEndian, false},
+	}
+
+	for _, test := range tests {
+		data := withExifOrientation(halvesJpeg(t, 40, 20), test.orientation, test.order)
+
+		variants, err := imaging.Process(data)
+		if err != nil {
+			t.Fatalf("%v: error processing: %v", test.name, err)
+		}
+		full := variants[0]
+		if full.Width != 20 || full.Height != 40 {
+			t.Fatalf("%v: expected 20x40, got %vx%v", test.name, full.Width, full.Height)
+		}
+		if bytes.Contains(full.Data, []byte("Exif")) {
+			t.Fatalf("%v: the EXIF data should be removed", test.name)
+		}
+
+		decoded, decodeErr := jpeg.Decode(bytes.NewReader(full.Data))
+		if decodeErr != nil {
+			t.Fatalf("%v: the result is not a jpeg: %v", test.name, decodeErr)
+		}
+		top, bottom := decoded.At(10, 5), decoded.At(10, 35)
+		if test.redOnTop && !(isReddish(top) && isBluish(bottom)) {
+			t.Fatalf("%v: expected red on top and blue at the bottom, got %v and %v", test.name, top, bottom)
+		}
+		if !test.redOnTop && !(isBluish(top) && isReddish(bottom)) {
+			t.Fatalf("%v: expected blue on top and red at the bottom, got %v and %v", test.name, top, bottom)
+		}
+	}
+}
+
+func TestProcessMakesRenditions(t *testing.T) {
+	encoded := bytes.Buffer{}
+	if err := png.Encode(&encoded, image.NewRGBA(image.Rect(0, 0, 3000, 1500))); err != nil {
+		t.Fatalf("error encoding png: %v", err)
+	}
+
+	variants, err := imaging.Process(encoded.Bytes())
+	if err != nil {
+		t.Fatalf("error processing: %v", err)
+	}
+
+	expected := []struct {
+		rendition     imaging.Rendition
+		width, height int
+	}{
+		{imaging.RenditionFull, 2048, 1024},
+		{imaging.RenditionFeed, 1080, 540},
+		{imaging.RenditionThumbnail, 320, 160},
+	}
+	if len(variants) != len(expected) {
+		t.Fatalf("expected %v variants, got %v", len(expected), len(variants))
+	}
+	for index, variant := range variants {
+		if variant.Rendition != expected[index].rendition || variant.Width != expected[index].width || variant.Height != expected[index].height {
+			t.Fatalf("expected %v at %vx%v, got %v at %vx%v", expected[index].rendition, expected[index].width, expected[index].height, variant.Rendition, variant.Width, variant.Height)
+		}
+		if variant.ContentType != "image/png" || variant.Extension != ".png" {
+			t.Fatalf("pngs should stay pngs, got %v", variant.ContentType)
+		}
+		config, configErr := png.DecodeConfig(bytes.NewReader(variant.Data))
+		if configErr != nil || config.Width != variant.Width || config.Height != variant.Height {
+			t.Fatalf("the %v data does not match its size: %v %v", variant.Rendition, config, configErr)
+		}
+	}
+}
+
+func TestProcessDoesNotUpscale(t *testing.T) {
+	variants, err := imaging.Process(halvesJpeg(t, 100, 50))
+	if err != nil {
+		t.Fatalf("error processing: %v", err)
+	}
+
+	if len(variants) != 1 {
+		t.Fatalf("a small image should only have the full rendition, got %v", len(variants))
+	}
+	if variants[0].Rendition != imaging.RenditionFull || variants[0].Width != 100 || variants[0].Height != 50 {
+		t.Fatalf("expected the full rendition at 100x50, got %+v", variants[0].Rendition)
+	}
+	if variants[0].ContentType != "image/jpeg" {
+		t.Fatalf("jpegs should stay jpegs, got %v", variants[0].ContentType)
+	}
+}
+
+func TestProcessRefusesTooManyPixels(t *testing.T) {
+	encoded := bytes.Buffer{}
+	if err := png.Encode(&encoded, image.NewGray(image.Rect(0, 0, 1, 1))); err != nil {
+		t.Fatalf("error encoding png: %v", err)
+	}
+	data := encoded.Bytes()
+
+	// Claim 10000x10000 in the header. It comes right after the signature, its length and its type.
+	header := data[16:29]
+	binary.BigEndian.PutUint32(header[0:], 10000)
+	binary.BigEndian.PutUint32(header[4:], 10000)
+	binary.BigEndian.PutUint32(data[29:], crc32.ChecksumIEEE(data[12:29]))
+
+	if _, err := imaging.Process(data); !errors.Is(err, imaging.ErrTooManyPixels) {
+		t.Fatalf("expected ErrTooManyPixels, got %v", err)
+	}
+}
+
+func TestProcessWebPStripsMetadata(t *testing.T) {
+	chunk := func(fourcc string, payload []byte) []byte {
+		data := binary.LittleEndian.AppendUint32([]byte(fourcc), uint32(len(payload)))
+		data = append(data, payload...)
+		if len(payload)%2 == 1 {
+			data = append(data, 0)
+		}
+		return data
+	}
+
+	// Flags for EXIF and XMP, then the canvas size minus one: 100x50
+	vp8x := []byte{0x0C, 0, 0, 0, 99, 0, 0, 49, 0, 0}
+	body := []byte("WEBP")
+	body = append(body, chunk("VP8X", vp8x)...)
+	body = append(body, chunk("EXIF", []byte("GPS!!"))...)
+	body = append(body, chunk("VP8L", []byte{0x2F, 0, 0, 0, 0, 0})...)
+	body = append(body, chunk("XMP ", []byte("<x:xmpmeta/>"))...)
+	data := binary.LittleEndian.AppendUint32([]byte("RIFF"), uint32(len(body)))
+	data = append(data, body...)
+
+	variants, err := imaging.Process(data)
+	if err != nil {
+		t.Fatalf("error processing webp: %v", err)
+	}
+	if len(variants) != 1 || variants[0].Rendition != imaging.RenditionFull || variants[0].ContentType != "image/webp" {
+		t.Fatalf("expected only the full webp rendition, got %v variants", len(variants))
+	}
+
+	stripped := variants[0].Data
+	if bytes.Contains(stripped, []byte("EXIF")) || bytes.Contains(stripped, []byte("GPS")) || bytes.Contains(stripped, []byte("xmpmeta")) {
+		t.Fatalf("the metadata should be removed")
+	}
+	if !bytes.Contains(stripped, []byte("VP8L")) {
+		t.Fatalf("the image data should be kept")
+	}
+	if stripped[20]&0x0C != 0 {
+		t.Fatalf("the metadata flags should be cleared, got %08b", stripped[20])
+	}
+	if int(binary.LittleEndian.Uint32(stripped[4:])) != len(stripped)-8 {
+		t.Fatalf("the RIFF size should match the new length")
+	}
+	if variants[0].Width != 100 || variants[0].Height != 50 {
+		t.Fatalf("expected 100x50, got %vx%v", variants[0].Width, variants[0].Height)
+	}
+}
+
+func TestProcessRejectsOtherFiles(t *testing.T) {
+	video, readErr := os.ReadFile("testdata/media/video.mp4")
+	if readErr != nil {
+		t.Fatalf("error reading fixture: %v", readErr)
+	}
+	if _, err := imaging.Process(video); !errors.Is(err, media.ErrUnsupportedFormat) {
+		t.Fatalf("expected ErrUnsupportedFormat for a video, got %v", err)
+	}
+
+	photo := halvesJpeg(t, 40, 20)
+	if _, err := imaging.Process(photo[:len(photo)/2]); !errors.Is(err, media.ErrMalformed) {
+		t.Fatalf("expected ErrMalformed for a truncated jpeg, got %v", err)
+	}
+}
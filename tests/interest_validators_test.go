@@ -0,0 +1,28 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/app/validators"
+)
+
+func TestValidateInterestNameTrims(t *testing.T) {
+	name, err := validators.ValidateInterestName("  Board Games ")
+	if err != nil {
+		t.Fatalf("error validating name: %v", err)
+	}
+	if name != "Board Games" {
+		t.Fatalf("expected the trimmed name, got %q", name)
+	}
+}
+
+func TestValidateInterestNameInvalid(t *testing.T) {
+	names := []string{"", "   ", strings.Repeat("a", 51)}
+
+	for _, name := range names {
+		if _, err := validators.ValidateInterestName(name); err == nil {
+			t.Fatalf("expected an error for %q", name)
+		}
+	}
+}
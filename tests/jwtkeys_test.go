@@ -0,0 +1,180 @@
+package tests
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/app/handlers"
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/app/jwtkeys"
+)
+
+func writeRSAKey(t *testing.T, dir, kid string) *rsa.PrivateKey {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("error generating rsa key: %v", err)
+	}
+	pemBytes := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)})
+	if err := os.WriteFile(filepath.Join(dir, kid+".pem"), pemBytes, 0600); err != nil {
+		t.Fatalf("error writing key: %v", err)
+	}
+	return privateKey
+}
+
+func writeEd25519Key(t *testing.T, dir, kid string) ed25519.PrivateKey {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("error generating ed25519 key: %v", err)
+	}
+	derBytes, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		t.Fatalf("error marshaling key: %v", err)
+	}
+	pemBytes := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: derBytes})
+	if err := os.WriteFile(filepath.Join(dir, kid+".pem"), pemBytes, 0600); err != nil {
+		t.Fatalf("error writing key: %v", err)
+	}
+	return privateKey
+}
+
+func TestKeySetSignsWithKid(t *testing.T) {
+	for _, algorithm := range []string{"RS256", "EdDSA"} {
+		dir := t.TempDir()
+		if algorithm == "RS256" {
+			writeRSAKey(t, dir, "key-1")
+		} else {
+			writeEd25519Key(t, dir, "key-1")
+		}
+
+		keys, err := jwtkeys.LoadKeySet(dir, "", "")
+		if err != nil {
+			t.Fatalf("error loading keys: %v", err)
+		}
+
+		token, err := handlers.MakeJWT(30, 0, keys, 1*time.Hour)
+		if err != nil {
+			t.Fatalf("error making jwt: %v", err)
+		}
+
+		userId, validateErr := handlers.ValidateJWT(context.Background(), token, keys, nil)
+		if validateErr != nil {
+			t.Fatalf("error validating %v jwt: %v", algorithm, validateErr)
+		}
+		if userId != 30 {
+			t.Fatalf("expected user id 30 but got %v", userId)
+		}
+
+		jwks := keys.JWKS()
+		if len(jwks.Keys) != 1 || jwks.Keys[0].Kid != "key-1" || jwks.Keys[0].Alg != algorithm {
+			t.Fatalf("unexpected jwks %+v", jwks)
+		}
+	}
+}
+
+func TestKeySetRotation(t *testing.T) {
+	dir := t.TempDir()
+	oldKey := writeRSAKey(t, dir, "2025-01")
+
+	oldKeys, err := jwtkeys.LoadKeySet(dir, "", "")
+	if err != nil {
+		t.Fatalf("error loading keys: %v", err)
+	}
+	oldToken, err := handlers.MakeJWT(32, 0, oldKeys, 1*time.Hour)
+	if err != nil {
+		t.Fatalf("error making jwt: %v", err)
+	}
+
+	// Retire the old key: keep only its public key and add a new signing key
+	publicDer, err := x509.MarshalPKIXPublicKey(&oldKey.PublicKey)
+	if err != nil {
+		t.Fatalf("error marshaling public key: %v", err)
+	}
+	publicPem := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDer})
+	if err := os.WriteFile(filepath.Join(dir, "2025-01.pem"), publicPem, 0600); err != nil {
+		t.Fatalf("error writing key: %v", err)
+	}
+	writeEd25519Key(t, dir, "2025-02")
+
+	newKeys, err := jwtkeys.LoadKeySet(dir, "", "")
+	if err != nil {
+		t.Fatalf("error loading keys: %v", err)
+	}
+
+	if _, err := handlers.ValidateJWT(context.Background(), oldToken, newKeys, nil); err != nil {
+		t.Fatalf("token signed with the retired key should still be valid: %v", err)
+	}
+
+	newToken, err := handlers.MakeJWT(32, 0, newKeys, 1*time.Hour)
+	if err != nil {
+		t.Fatalf("error making jwt: %v", err)
+	}
+	if _, err := handlers.ValidateJWT(context.Background(), newToken, newKeys, nil); err != nil {
+		t.Fatalf("token signed with the new key should be valid: %v", err)
+	}
+
+	// The old server does not know the new key
+	if _, err := handlers.ValidateJWT(context.Background(), newToken, oldKeys, nil); err == nil {
+		t.Fatalf("token with an unknown kid should be rejected")
+	}
+
+	if len(newKeys.JWKS().Keys) != 2 {
+		t.Fatalf("expected both keys in the jwks")
+	}
+}
+
+func TestKeySetHMACFallback(t *testing.T) {
+	secret := "rT8!pL3#vQ7@kF9$mZ2&xY5*wC1^"
+	legacyToken, err := handlers.MakeJWT(34, 0, jwtkeys.NewHMACKeySet(secret), 1*time.Hour)
+	if err != nil {
+		t.Fatalf("error making jwt: %v", err)
+	}
+
+	dir := t.TempDir()
+	writeRSAKey(t, dir, "key-1")
+
+	withFallback, err := jwtkeys.LoadKeySet(dir, "", secret)
+	if err != nil {
+		t.Fatalf("error loading keys: %v", err)
+	}
+	if _, err := handlers.ValidateJWT(context.Background(), legacyToken, withFallback, nil); err != nil {
+		t.Fatalf("legacy hs256 token should be accepted with the fallback secret: %v", err)
+	}
+
+	withoutFallback, err := jwtkeys.LoadKeySet(dir, "", "")
+	if err != nil {
+		t.Fatalf("error loading keys: %v", err)
+	}
+	if _, err := handlers.ValidateJWT(context.Background(), legacyToken, withoutFallback, nil); err == nil {
+		t.Fatalf("legacy hs256 token should be rejected without the fallback secret")
+	}
+}
+
+func TestKeySetRejectsAlgorithmConfusion(t *testing.T) {
+	dir := t.TempDir()
+	writeRSAKey(t, dir, "key-1")
+
+	keys, err := jwtkeys.LoadKeySet(dir, "", "")
+	if err != nil {
+		t.Fatalf("error loading keys: %v", err)
+	}
+
+	token, err := handlers.MakeJWT(36, 0, keys, 1*time.Hour)
+	if err != nil {
+		t.Fatalf("error making jwt: %v", err)
+	}
+
+	// Swap the alg header for HS256 while keeping the kid
+	parts := strings.Split(token, ".")
+	parts[0] = "eyJhbGciOiJIUzI1NiIsImtpZCI6ImtleS0xIiwidHlwIjoiSldUIn0"
+	if _, err := handlers.ValidateJWT(context.Background(), strings.Join(parts, "."), keys, nil); err == nil {
+		t.Fatalf("token with a swapped alg should be rejected")
+	}
+}
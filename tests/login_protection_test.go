@@ -0,0 +1,31 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/app/handlers"
+)
+
+func TestLoginLockoutDuration(t *testing.T) {
+	base := 30 * time.Second
+	max := 1 * time.Hour
+
+	cases := map[int64]time.Duration{
+		0:   0,
+		4:   0,
+		5:   30 * time.Second,
+		6:   1 * time.Minute,
+		7:   2 * time.Minute,
+		10:  16 * time.Minute,
+		12:  1 * time.Hour,
+		100: 1 * time.Hour,
+	}
+
+	for failedAttempts, expected := range cases {
+		lockout := handlers.LoginLockoutDuration(failedAttempts, 5, base, max)
+		if lockout != expected {
+			t.Fatalf("after %v failures expected %v but got %v", failedAttempts, expected, lockout)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package tests
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/app/mailer"
+)
+
+func TestFileMailerWritesEml(t *testing.T) {
+	dir := t.TempDir()
+	fileMailer := &mailer.FileMailer{Dir: dir, From: "no-reply@sanctuary.test"}
+
+	message := mailer.Message{
+		To:      "user@sanctuary.test",
+		Subject: "Reset your password",
+		Body:    "token: abc",
+	}
+	if err := fileMailer.Send(context.Background(), message); err != nil {
+		t.Fatalf("error sending mail : %v", err)
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	if err != nil || len(files) != 1 {
+		t.Fatalf("expected one .eml file, got %v", len(files))
+	}
+
+	content, readErr := os.ReadFile(files[0])
+	if readErr != nil {
+		t.Fatalf("error reading mail : %v", readErr)
+	}
+
+	for _, expected := range []string{"To: user@sanctuary.test", "Subject: Reset your password", "token: abc"} {
+		if !strings.Contains(string(content), expected) {
+			t.Fatalf("mail does not contain %q", expected)
+		}
+	}
+}
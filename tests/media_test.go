@@ -0,0 +1,150 @@
+package tests
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/png"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/app/media"
+)
+
+func probeFixture(t *testing.T, name string) media.Info {
+	t.Helper()
+	data, readErr := os.ReadFile("testdata/media/" + name)
+	if readErr != nil {
+		t.Fatalf("error reading fixture %v: %v", name, readErr)
+	}
+	info, probeErr := media.Probe(bytes.NewReader(data))
+	if probeErr != nil {
+		t.Fatalf("error probing %v: %v", name, probeErr)
+	}
+	return info
+}
+
+func TestProbeMP4(t *testing.T) {
+	// The moov box comes after mdat, and the audio track comes before the video track
+	info := probeFixture(t, "video.mp4")
+
+	if info.Kind != media.KindVideo || info.ContentType != "video/mp4" {
+		t.Fatalf("expected an mp4 video, got %v %v", info.Kind, info.ContentType)
+	}
+	if info.Width != 640 || info.Height != 360 {
+		t.Fatalf("expected 640x360, got %vx%v", info.Width, info.Height)
+	}
+	if info.Duration != 2500*time.Millisecond {
+		t.Fatalf("expected 2.5s, got %v", info.Duration)
+	}
+	if info.Poster != nil {
+		t.Fatalf("videos should not have a poster")
+	}
+}
+
+func TestProbeWebM(t *testing.T) {
+	// The segment has an unknown size, and the audio track comes before the video track
+	info := probeFixture(t, "video.webm")
+
+	if info.Kind != media.KindVideo || info.ContentType != "video/webm" {
+		t.Fatalf("expected a webm video, got %v %v", info.Kind, info.ContentType)
+	}
+	if info.Width != 1280 || info.Height != 720 {
+		t.Fatalf("expected 1280x720, got %vx%v", info.Width, info.Height)
+	}
+	if info.Duration != 3*time.Second {
+		t.Fatalf("expected 3s, got %v", info.Duration)
+	}
+}
+
+func TestProbeAnimatedGif(t *testing.T) {
+	info := probeFixture(t, "animated.gif")
+
+	if info.Kind != media.KindGif || info.ContentType != "image/gif" {
+		t.Fatalf("expected an animated gif, got %v %v", info.Kind, info.ContentType)
+	}
+	if info.Width != 8 || info.Height != 6 {
+		t.Fatalf("expected 8x6, got %vx%v", info.Width, info.Height)
+	}
+	if info.Duration != 250*time.Millisecond {
+		t.Fatalf("expected 250ms, got %v", info.Duration)
+	}
+	if info.Poster == nil || info.Poster.Bounds() != image.Rect(0, 0, 8, 6) {
+		t.Fatalf("expected an 8x6 poster, got %v", info.Poster)
+	}
+}
+
+func TestProbeStillGifIsImage(t *testing.T) {
+	info := probeFixture(t, "still.gif")
+
+	if info.Kind != media.KindImage {
+		t.Fatalf("a gif with one frame should be an image, got %v", info.Kind)
+	}
+	if info.Duration != 0 || info.Poster != nil {
+		t.Fatalf("a still gif should have no duration or poster")
+	}
+}
+
+func TestProbePng(t *testing.T) {
+	encoded := bytes.Buffer{}
+	if err := png.Encode(&encoded, image.NewRGBA(image.Rect(0, 0, 30, 20))); err != nil {
+		t.Fatalf("error encoding png: %v", err)
+	}
+
+	info, err := media.Probe(bytes.NewReader(encoded.Bytes()))
+	if err != nil {
+		t.Fatalf("error probing png: %v", err)
+	}
+	if info.Kind != media.KindImage || info.ContentType != "image/png" || info.Width != 30 || info.Height != 20 {
+		t.Fatalf("expected a 30x20 png image, got %+v", info)
+	}
+}
+
+func TestProbeUnsupported(t *testing.T) {
+	inputs := [][]byte{
+		{},
+		[]byte("just some text"),
+		[]byte("%PDF-1.7\n"),
+	}
+
+	for _, input := range inputs {
+		if _, err := media.Probe(bytes.NewReader(input)); !errors.Is(err, media.ErrUnsupportedFormat) {
+			t.Fatalf("expected ErrUnsupportedFormat for %q, got %v", input, err)
+		}
+	}
+}
+
+func TestProbeTruncated(t *testing.T) {
+	for _, name := range []string{"video.mp4", "video.webm", "animated.gif"} {
+		data, readErr := os.ReadFile("testdata/media/" + name)
+		if readErr != nil {
+			t.Fatalf("error reading fixture %v: %v", name, readErr)
+		}
+
+		// Keep enough for the format to be sniffed, but cut the metadata
+		truncated := data[:len(data)*2/3]
+		if name == "video.webm" {
+			truncated = data[:80]
+		}
+		if _, err := media.Probe(bytes.NewReader(truncated)); !errors.Is(err, media.ErrMalformed) {
+			t.Fatalf("expected ErrMalformed for truncated %v, got %v", name, err)
+		}
+	}
+}
+
+func TestSniffRewinds(t *testing.T) {
+	data, readErr := os.ReadFile("testdata/media/video.webm")
+	if readErr != nil {
+		t.Fatalf("error reading fixture: %v", readErr)
+	}
+	reader := bytes.NewReader(data)
+
+	contentType, err := media.Sniff(reader)
+	if err != nil || contentType != "video/webm" {
+		t.Fatalf("expected video/webm, got %v %v", contentType, err)
+	}
+	if reader.Len() != len(data) {
+		t.Fatalf("the reader should be back at the start")
+	}
+}
@@ -0,0 +1,79 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/app"
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/app/validators"
+)
+
+var patchNow = time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+func TestParseProfilePatchOnlySentFields(t *testing.T) {
+	patch, err := validators.ParseProfilePatch([]byte(`{"full_name": "  Zaw Htet  "}`), app.TIME_PARSE_LAYOUT, patchNow)
+	if err != nil {
+		t.Fatalf("error parsing patch: %v", err)
+	}
+
+	if patch.FullName == nil || *patch.FullName != "Zaw Htet" {
+		t.Fatalf("full name should be trimmed and set, got %v", patch.FullName)
+	}
+	if patch.UserName != nil || patch.Dob != nil || patch.ClearDob {
+		t.Fatalf("fields that were not sent should not change")
+	}
+}
+
+func TestParseProfilePatchDob(t *testing.T) {
+	patch, err := validators.ParseProfilePatch([]byte(`{"dob": "1995-03-14"}`), app.TIME_PARSE_LAYOUT, patchNow)
+	if err != nil {
+		t.Fatalf("error parsing patch: %v", err)
+	}
+
+	expected := time.Date(1995, 3, 14, 0, 0, 0, 0, time.UTC)
+	if patch.Dob == nil || !patch.Dob.Equal(expected) {
+		t.Fatalf("expected dob %v, got %v", expected, patch.Dob)
+	}
+}
+
+func TestParseProfilePatchNullDobClearsIt(t *testing.T) {
+	patch, err := validators.ParseProfilePatch([]byte(`{"dob": null}`), app.TIME_PARSE_LAYOUT, patchNow)
+	if err != nil {
+		t.Fatalf("error parsing patch: %v", err)
+	}
+
+	if !patch.ClearDob || patch.Dob != nil {
+		t.Fatalf("null dob should clear it")
+	}
+}
+
+func TestParseProfilePatchEmpty(t *testing.T) {
+	patch, err := validators.ParseProfilePatch([]byte(`{}`), app.TIME_PARSE_LAYOUT, patchNow)
+	if err != nil {
+		t.Fatalf("error parsing patch: %v", err)
+	}
+
+	if !patch.IsEmpty() {
+		t.Fatalf("empty object should not change anything")
+	}
+}
+
+func TestParseProfilePatchInvalid(t *testing.T) {
+	bodies := []string{
+		`not json`,
+		`["full_name"]`,
+		`{"full_name": null}`,
+		`{"user_name": null}`,
+		`{"user_name": "   "}`,
+		`{"user_name": 12}`,
+		`{"dob": "14-03-1995"}`,
+		`{"dob": "2030-01-01"}`,
+		`{"email": "someone@example.com"}`,
+	}
+
+	for _, body := range bodies {
+		if _, err := validators.ParseProfilePatch([]byte(body), app.TIME_PARSE_LAYOUT, patchNow); err == nil {
+			t.Fatalf("expected an error for %v", body)
+		}
+	}
+}
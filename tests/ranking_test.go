@@ -0,0 +1,125 @@
+package tests
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/app/ranking"
+)
+
+var rankingNow = time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+func TestScore(t *testing.T) {
+	weights := ranking.Weights{Interest: 2, Like: 1, Comment: 1, HalfLife: 10 * time.Hour}
+	candidate := ranking.Candidate{
+		ID:           1,
+		CreatedAt:    rankingNow.Add(-10 * time.Hour),
+		LikeCount:    0,
+		CommentCount: 0,
+		InterestIDs:  []int64{1, 2, 3},
+	}
+
+	// Base of 1, plus 2 for each of the two shared interests, halved after one half life
+	score := ranking.Score(candidate, map[int64]bool{1: true, 3: true}, rankingNow, weights)
+	if math.Abs(score-2.5) > 1e-9 {
+		t.Fatalf("Expected 2.5 but got %v", score)
+	}
+}
+
+func TestScoreEngagement(t *testing.T) {
+	weights := ranking.Weights{Interest: 0, Like: 1, Comment: 2, HalfLife: time.Hour}
+	candidate := ranking.Candidate{
+		ID:           1,
+		CreatedAt:    rankingNow,
+		LikeCount:    6,
+		CommentCount: 3,
+	}
+
+	expected := 1 + math.Log(7) + 2*math.Log(4)
+	score := ranking.Score(candidate, map[int64]bool{}, rankingNow, weights)
+	if math.Abs(score-expected) > 1e-9 {
+		t.Fatalf("Expected %v but got %v", expected, score)
+	}
+}
+
+func TestRank(t *testing.T) {
+	weights := ranking.DefaultWeights()
+	candidates := []ranking.Candidate{
+		// Old post with no engagement
+		{ID: 1, CreatedAt: rankingNow.Add(-72 * time.Hour)},
+		// New post with no engagement
+		{ID: 2, CreatedAt: rankingNow.Add(-1 * time.Hour)},
+		// New post matching an interest of the viewer
+		{ID: 3, CreatedAt: rankingNow.Add(-1 * time.Hour), InterestIDs: []int64{7}},
+		// Popular post from a day ago
+		{ID: 4, CreatedAt: rankingNow.Add(-24 * time.Hour), LikeCount: 50, CommentCount: 20},
+	}
+
+	ranked := ranking.Rank(candidates, []int64{7}, rankingNow, weights)
+
+	ids := []int64{}
+	for _, candidate := range ranked {
+		ids = append(ids, candidate.ID)
+	}
+	if !reflect.DeepEqual(ids, []int64{4, 3, 2, 1}) {
+		t.Fatalf("Expected 4, 3, 2, 1 but got %v", ids)
+	}
+}
+
+func TestRankTies(t *testing.T) {
+	// Equal scores are ordered newest first, then by id
+	candidates := []ranking.Candidate{
+		{ID: 1, CreatedAt: rankingNow},
+		{ID: 3, CreatedAt: rankingNow},
+		{ID: 2, CreatedAt: rankingNow},
+	}
+
+	for i := 0; i < 10; i++ {
+		ranked := ranking.Rank(candidates, nil, rankingNow, ranking.DefaultWeights())
+		if ranked[0].ID != 3 || ranked[1].ID != 2 || ranked[2].ID != 1 {
+			t.Fatalf("Expected 3, 2, 1 but got %v", ranked)
+		}
+	}
+}
+
+func TestParseWeights(t *testing.T) {
+	weights, err := ranking.ParseWeights("5", "", "0.5", "12h")
+	if err != nil {
+		t.Fatalf("Error parsing weights %v", err)
+	}
+
+	expected := ranking.DefaultWeights()
+	expected.Interest = 5
+	expected.Comment = 0.5
+	expected.HalfLife = 12 * time.Hour
+	if weights != expected {
+		t.Fatalf("Expected %v but got %v", expected, weights)
+	}
+
+	if _, err := ranking.ParseWeights("-1", "", "", ""); err == nil {
+		t.Fatalf("Expected an error for a negative weight")
+	}
+	if _, err := ranking.ParseWeights("", "", "", "0s"); err == nil {
+		t.Fatalf("Expected an error for a zero half life")
+	}
+}
+
+func TestInferInterests(t *testing.T) {
+	interests := map[int64]string{
+		1: "Art",
+		2: "Music",
+		3: "Machine Learning",
+		4: "Travel",
+	}
+
+	inferred := ranking.InferInterests("Started a new MUSIC project about machine learning!", interests)
+	if !reflect.DeepEqual(inferred, []int64{2, 3}) {
+		t.Fatalf("Expected 2, 3 but got %v", inferred)
+	}
+
+	if inferred := ranking.InferInterests("Nothing to see here", interests); len(inferred) != 0 {
+		t.Fatalf("Expected no interests but got %v", inferred)
+	}
+}
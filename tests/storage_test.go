@@ -0,0 +1,212 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/app/handlers"
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/app/media"
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/app/storage"
+)
+
+func newLocalStorage(t *testing.T) *storage.LocalStorage {
+	t.Helper()
+	return &storage.LocalStorage{
+		Dir:     t.TempDir(),
+		BaseURL: "http://localhost:8080/files/",
+	}
+}
+
+func TestLocalStoragePutAndServe(t *testing.T) {
+	localStorage := newLocalStorage(t)
+	ctx := context.Background()
+
+	if err := localStorage.Put(ctx, "images/photo.png", strings.NewReader("not really a png"), "image/png"); err != nil {
+		t.Fatalf("error storing file: %v", err)
+	}
+	downloadUrl := localStorage.URL("images/photo.png")
+	if downloadUrl != "http://localhost:8080/files/images/photo.png" {
+		t.Fatalf("unexpected url %v", downloadUrl)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /files/{key...}", localStorage.ServeFile)
+
+	recorder := httptest.NewRecorder()
+	mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/files/images/photo.png", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %v", recorder.Code)
+	}
+	if body, _ := io.ReadAll(recorder.Body); string(body) != "not really a png" {
+		t.Fatalf("unexpected body %q", body)
+	}
+	if recorder.Header().Get("X-Content-Type-Options") != "nosniff" {
+		t.Fatalf("stored files should not be sniffed")
+	}
+
+	// Directories are not listed and missing files are not found
+	for _, path := range []string{"/files/images/", "/files/images/missing.png"} {
+		recorder = httptest.NewRecorder()
+		mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+		if recorder.Code != http.StatusNotFound {
+			t.Fatalf("expected 404 for %v, got %v", path, recorder.Code)
+		}
+	}
+}
+
+func TestLocalStorageDelete(t *testing.T) {
+	localStorage := newLocalStorage(t)
+	ctx := context.Background()
+
+	if err := localStorage.Put(ctx, "videos/clip.mp4", strings.NewReader("clip"), "video/mp4"); err != nil {
+		t.Fatalf("error storing file: %v", err)
+	}
+	if err := localStorage.Delete(ctx, "videos/clip.mp4"); err != nil {
+		t.Fatalf("error deleting file: %v", err)
+	}
+	if _, statErr := os.Stat(filepath.Join(localStorage.Dir, "videos", "clip.mp4")); !errors.Is(statErr, os.ErrNotExist) {
+		t.Fatalf("the file should be gone, got %v", statErr)
+	}
+
+	// Deleting again is not an error
+	if err := localStorage.Delete(ctx, "videos/clip.mp4"); err != nil {
+		t.Fatalf("deleting a missing file should succeed, got %v", err)
+	}
+}
+
+func TestLocalStorageRejectsKeysOutsideDir(t *testing.T) {
+	localStorage := newLocalStorage(t)
+	ctx := context.Background()
+
+	for _, key := range []string{"", "../secret", "images/../../secret", "/etc/passwd", `images\..\..\secret`} {
+		if err := localStorage.Put(ctx, key, strings.NewReader("x"), "text/plain"); !errors.Is(err, storage.ErrInvalidKey) {
+			t.Fatalf("expected ErrInvalidKey for %q, got %v", key, err)
+		}
+		if err := localStorage.Delete(ctx, key); !errors.Is(err, storage.ErrInvalidKey) {
+			t.Fatalf("expected ErrInvalidKey when deleting %q, got %v", key, err)
+		}
+	}
+}
+
+func TestKeyFromURL(t *testing.T) {
+	localStorage := newLocalStorage(t)
+
+	key, ok := storage.KeyFromURL(localStorage, "http://localhost:8080/files/profiles/me.jpg")
+	if !ok || key != "profiles/me.jpg" {
+		t.Fatalf("expected profiles/me.jpg, got %v %v", key, ok)
+	}
+
+	for _, downloadUrl := range []string{"", "http://localhost:8080/files/", "https://example.com/files/profiles/me.jpg"} {
+		if _, ok := storage.KeyFromURL(localStorage, downloadUrl); ok {
+			t.Fatalf("%q is not a file of the storage", downloadUrl)
+		}
+	}
+}
+
+func testAwsConfig() aws.Config {
+	return aws.Config{
+		Region: "ap-southeast-1",
+		Credentials: aws.CredentialsProviderFunc(func(ctx context.Context) (aws.Credentials, error) {
+			return aws.Credentials{AccessKeyID: "test", SecretAccessKey: "test"}, nil
+		}),
+	}
+}
+
+func TestS3StorageURLs(t *testing.T) {
+	s3Storage := storage.NewS3Storage(testAwsConfig(), "sanctuary")
+	if downloadUrl := s3Storage.URL("images/a.jpg"); downloadUrl != "https://sanctuary.s3.ap-southeast-1.amazonaws.com/images/a.jpg" {
+		t.Fatalf("unexpected s3 url %v", downloadUrl)
+	}
+
+	minio := storage.NewS3CompatibleStorage(testAwsConfig(), "sanctuary", "http://minio:9000/", "")
+	if downloadUrl := minio.URL("images/a.jpg"); downloadUrl != "http://minio:9000/sanctuary/images/a.jpg" {
+		t.Fatalf("unexpected minio url %v", downloadUrl)
+	}
+
+	proxied := storage.NewS3CompatibleStorage(testAwsConfig(), "sanctuary", "http://minio:9000", "https://files.example.com")
+	if downloadUrl := proxied.URL("images/a.jpg"); downloadUrl != "https://files.example.com/sanctuary/images/a.jpg" {
+		t.Fatalf("unexpected public url %v", downloadUrl)
+	}
+}
+
+func TestS3CompatibleStoragePresign(t *testing.T) {
+	minio := storage.NewS3CompatibleStorage(testAwsConfig(), "sanctuary", "http://minio:9000", "")
+
+	// Presigning only signs the url, nothing is sent to the server
+	presigned, err := minio.Presign(context.Background(), "videos/clip.mp4", 15*time.Minute)
+	if err != nil {
+		t.Fatalf("error presigning: %v", err)
+	}
+
+	parsed, parseErr := url.Parse(presigned)
+	if parseErr != nil {
+		t.Fatalf("invalid presigned url %v", presigned)
+	}
+	if parsed.Host != "minio:9000" || parsed.Path != "/sanctuary/videos/clip.mp4" {
+		t.Fatalf("the presigned url should point at the endpoint, got %v", presigned)
+	}
+	if parsed.Query().Get("X-Amz-Signature") == "" || parsed.Query().Get("X-Amz-Expires") != "900" {
+		t.Fatalf("expected a signature that expires in 900 seconds, got %v", presigned)
+	}
+}
+
+func TestUploadFileHeaderUsesSniffedType(t *testing.T) {
+	localStorage := newLocalStorage(t)
+	data, readErr := os.ReadFile("testdata/media/video.mp4")
+	if readErr != nil {
+		t.Fatalf("error reading fixture: %v", readErr)
+	}
+
+	// The client sends a video without an extension or a useful content type
+	body := bytes.Buffer{}
+	form := multipart.NewWriter(&body)
+	part, partErr := form.CreatePart(textproto.MIMEHeader{
+		"Content-Disposition": {`form-data; name="files"; filename="clip"`},
+		"Content-Type":        {"application/octet-stream"},
+	})
+	if partErr != nil {
+		t.Fatalf("error making form: %v", partErr)
+	}
+	part.Write(data)
+	form.Close()
+
+	request := httptest.NewRequest(http.MethodPost, "/api/posts", &body)
+	request.Header.Set("Content-Type", form.FormDataContentType())
+	if parseErr := request.ParseMultipartForm(1 << 20); parseErr != nil {
+		t.Fatalf("error parsing form: %v", parseErr)
+	}
+	fileHeader := request.MultipartForm.File["files"][0]
+
+	file, _ := fileHeader.Open()
+	contentType, sniffErr := media.Sniff(file)
+	file.Close()
+	if sniffErr != nil {
+		t.Fatalf("error sniffing: %v", sniffErr)
+	}
+
+	downloadUrl, uploadErr := handlers.UploadFileHeader(context.Background(), fileHeader, "videos", contentType, localStorage)
+	if uploadErr != nil {
+		t.Fatalf("error uploading: %v", uploadErr)
+	}
+	if !strings.HasPrefix(downloadUrl, localStorage.URL("videos/")) || !strings.HasSuffix(downloadUrl, ".mp4") {
+		t.Fatalf("expected an mp4 under videos, got %v", downloadUrl)
+	}
+
+	// Content types Sniff does not return are refused
+	if _, err := handlers.UploadFileHeader(context.Background(), fileHeader, "videos", "text/html", localStorage); !errors.Is(err, media.ErrUnsupportedFormat) {
+		t.Fatalf("expected ErrUnsupportedFormat, got %v", err)
+	}
+}
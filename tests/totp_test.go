@@ -0,0 +1,111 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/app/handlers"
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/app/jwtkeys"
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/app/totp"
+)
+
+// Base32 of the RFC 6238 SHA1 test secret "12345678901234567890"
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTotpRfcVectors(t *testing.T) {
+	// RFC 6238 appendix B, truncated to 6 digits
+	vectors := map[int64]string{
+		59:         "287082",
+		1111111109: "081804",
+		1111111111: "050471",
+		1234567890: "005924",
+		2000000000: "279037",
+	}
+
+	for unixTime, expected := range vectors {
+		code, err := totp.GenerateCode(rfcSecret, totp.Step(time.Unix(unixTime, 0)))
+		if err != nil {
+			t.Fatalf("error generating code: %v", err)
+		}
+		if code != expected {
+			t.Fatalf("at %v expected %v but got %v", unixTime, expected, code)
+		}
+	}
+}
+
+func TestTotpValidateSkew(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	previousCode, _ := totp.GenerateCode(rfcSecret, totp.Step(now)-1)
+	oldCode, _ := totp.GenerateCode(rfcSecret, totp.Step(now)-2)
+
+	step, ok := totp.Validate(rfcSecret, previousCode, now, 1)
+	if !ok {
+		t.Fatalf("code from the previous step should be accepted")
+	}
+	if step != totp.Step(now)-1 {
+		t.Fatalf("expected the matched step to be %v but got %v", totp.Step(now)-1, step)
+	}
+
+	if _, ok := totp.Validate(rfcSecret, oldCode, now, 1); ok {
+		t.Fatalf("code outside the skew window should be rejected")
+	}
+
+	if _, ok := totp.Validate(rfcSecret, "12345", now, 1); ok {
+		t.Fatalf("code with the wrong length should be rejected")
+	}
+}
+
+func TestTotpURI(t *testing.T) {
+	uri := totp.URI("Sanctuary", "user@example.com", rfcSecret)
+	if !strings.HasPrefix(uri, "otpauth://totp/Sanctuary:user@example.com?") {
+		t.Fatalf("unexpected uri %v", uri)
+	}
+	if !strings.Contains(uri, "secret="+rfcSecret) {
+		t.Fatalf("uri does not contain the secret %v", uri)
+	}
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	codes, err := totp.GenerateRecoveryCodes(10)
+	if err != nil {
+		t.Fatalf("error generating recovery codes: %v", err)
+	}
+	if len(codes) != 10 {
+		t.Fatalf("expected 10 codes but got %v", len(codes))
+	}
+
+	seen := map[string]bool{}
+	for _, code := range codes {
+		if seen[code] {
+			t.Fatalf("duplicate recovery code %v", code)
+		}
+		seen[code] = true
+	}
+
+	// Typing the code in lower case or without dashes should still match
+	if totp.NormalizeRecoveryCode(strings.ToLower(strings.ReplaceAll(codes[0], "-", " "))) != totp.NormalizeRecoveryCode(codes[0]) {
+		t.Fatalf("normalized recovery codes do not match")
+	}
+}
+
+func TestMFATokenIsNotAnAccessToken(t *testing.T) {
+	keys := jwtkeys.NewHMACKeySet("rT8!pL3#vQ7@kF9$mZ2&xY5*wC1^")
+
+	mfaToken, err := handlers.MakeMFAToken(22, 0, keys, 5*time.Minute)
+	if err != nil {
+		t.Fatalf("error making mfa token: %v", err)
+	}
+
+	claims, validateErr := handlers.ValidateMFAToken(mfaToken, keys)
+	if validateErr != nil {
+		t.Fatalf("error validating mfa token: %v", validateErr)
+	}
+	if userId, _ := claims.UserId(); userId != 22 {
+		t.Fatalf("expected user id 22 but got %v", userId)
+	}
+
+	if _, jwtErr := handlers.ParseJWT(mfaToken, keys); jwtErr == nil {
+		t.Fatalf("mfa token must not be accepted as an access token")
+	}
+}
@@ -0,0 +1,56 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/app"
+	"github.com/zawhtetnaing10/Sanctuary-Backend/internal/app/validators"
+)
+
+func TestValidateUserNameValid(t *testing.T) {
+	userNames := []string{"zaw", "zaw_htet", "zaw.htet", "Zaw99", "9lives", strings.Repeat("a", 30)}
+
+	for _, userName := range userNames {
+		if err := validators.ValidateUserName(userName); err != nil {
+			t.Fatalf("expected %q to be valid, got %v", userName, err)
+		}
+	}
+}
+
+func TestValidateUserNameInvalid(t *testing.T) {
+	userNames := []string{
+		"",
+		"ab",
+		strings.Repeat("a", 31),
+		"zaw htet",
+		"zaw-htet",
+		"_zaw",
+		".zaw",
+		"zaw.",
+		"zaw..htet",
+		"zäw",
+		"Admin",
+		"me",
+	}
+
+	for _, userName := range userNames {
+		if err := validators.ValidateUserName(userName); err == nil {
+			t.Fatalf("expected %q to be invalid", userName)
+		}
+	}
+}
+
+func TestParseProfilePatchValidatesUserName(t *testing.T) {
+	if _, err := validators.ParseProfilePatch([]byte(`{"user_name": "support"}`), app.TIME_PARSE_LAYOUT, patchNow); err == nil {
+		t.Fatalf("expected a reserved user name to be rejected")
+	}
+
+	patch, err := validators.ParseProfilePatch([]byte(`{"user_name": "zaw.htet"}`), app.TIME_PARSE_LAYOUT, patchNow)
+	if err != nil {
+		t.Fatalf("error parsing patch: %v", err)
+	}
+	if patch.UserName == nil || *patch.UserName != "zaw.htet" {
+		t.Fatalf("expected the user name to be set, got %v", patch.UserName)
+	}
+}